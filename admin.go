@@ -0,0 +1,199 @@
+package main
+
+import (
+  "crypto/subtle"
+  "errors"
+  "net/http"
+  "slices"
+  "strconv"
+  "strings"
+)
+
+/*
+  ADMIN ROUTES
+
+  Maintenance routes live under /admin and need the ADMIN_TOKEN as a bearer token:
+
+  curl -X POST -H "Authorization: Bearer $ADMIN_TOKEN" http://localhost:3000/admin/compact
+
+  Without an ADMIN_TOKEN they can't be used at all.
+*/
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    if config.AdminToken == "" {
+      jsonError(w, http.StatusForbidden, "admin routes are disabled, set ADMIN_TOKEN to enable them")
+      return
+    }
+    if !isAdmin(r) {
+      w.Header().Set("WWW-Authenticate", "Bearer")
+      jsonError(w, http.StatusUnauthorized, "invalid or missing admin token")
+      return
+    }
+    next(w, r)
+  }
+}
+
+// isAdmin reports whether the request carries the admin token. Public routes use it to show admins a little more.
+func isAdmin(r *http.Request) bool {
+  if config.AdminToken == "" {
+    return false
+  }
+  token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+  // ConstantTimeCompare takes as long for a wrong token as for a right one, so the token can't be guessed by timing the responses.
+  return ok && subtle.ConstantTimeCompare([]byte(token), []byte(config.AdminToken)) == 1
+}
+
+/*
+  COMPACT HANDLER
+
+  Housekeeping for the posts file:
+
+  - Deleted posts are removed for good.
+  - Leading and trailing whitespace is trimmed from the Title, Content, Author and Tags, and duplicate or empty tags are dropped.
+  - With ?reassign_ids=true and the sequential ID strategy, the posts are numbered again from 1 in file order. Links to the old IDs break, so it's opt-in.
+
+  The file is then rewritten in one go, and the response tells what was done:
+
+  {"posts": 12, "removed": 3, "normalized": 1, "reassigned": 12}
+*/
+type compactSummary struct {
+  Posts      int `json:"posts"`
+  Removed    int `json:"removed"`
+  Normalized int `json:"normalized"`
+  Reassigned int `json:"reassigned"`
+}
+
+func compact(w http.ResponseWriter, r *http.Request) {
+  reassign := r.URL.Query().Get("reassign_ids") == "true"
+  if reassign && config.IDStrategy != sequentialIDs {
+    jsonError(w, http.StatusBadRequest, "reassign_ids needs the sequential ID strategy")
+    return
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+
+  var summary compactSummary
+  kept := []Post{}
+  for _, post := range posts {
+    if post.Deleted {
+      summary.Removed++
+      continue
+    }
+    if post.normalize() {
+      summary.Normalized++
+    }
+    kept = append(kept, post)
+  }
+
+  renamed := map[PostID]PostID{}
+  if reassign {
+    for i := range kept {
+      id := PostID(strconv.Itoa(i + 1))
+      if kept[i].ID != id {
+        renamed[kept[i].ID] = id
+        kept[i].ID = id
+        summary.Reassigned++
+      }
+    }
+  }
+
+  if err := savePosts(r.Context(), kept); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+  // Views not written yet follow their post to its new ID.
+  viewBuffer.rename(renamed)
+
+  summary.Posts = len(kept)
+  writeJSON(w, http.StatusOK, summary)
+}
+
+// normalize tidies up the fields of the post and reports whether anything changed.
+func (post *Post) normalize() bool {
+  before := *post
+  post.Title = strings.TrimSpace(post.Title)
+  post.Content = strings.TrimSpace(post.Content)
+  post.Author = strings.TrimSpace(post.Author)
+  post.Tags = normalizeTags(post.Tags)
+  post.CoAuthors = normalizeCoAuthors(post.Author, post.CoAuthors)
+  return post.Title != before.Title || post.Content != before.Content || post.Author != before.Author || !slices.Equal(post.Tags, before.Tags) || !slices.Equal(post.CoAuthors, before.CoAuthors)
+}
+
+/*
+  REINDEX HANDLER
+
+  Unless they're cached (CACHE_POSTS, see cache.go), the posts are read from the file for every request, so they can't get out of date after editing the file by hand. What lives in memory in any case is the view buffer (see views.go), and it's keyed by post ID: after renumbering posts by hand, buffered views would land on whichever post now has the ID.
+
+  POST /admin/reindex reloads the cache, writes the buffered views right away, dropping those of posts that are gone, then reads the posts again to check it's still valid and summarizes what's in it:
+
+  {"posts": 12, "deleted": 1, "authors": 4, "tags": 9, "flushed_views": 37}
+
+  Anything derived from the posts that gets kept in memory later on should be rebuilt here too.
+*/
+type reindexSummary struct {
+  Posts        int   `json:"posts"`
+  Deleted      int   `json:"deleted"`
+  Authors      int   `json:"authors"`
+  Tags         int   `json:"tags"`
+  FlushedViews int64 `json:"flushed_views"`
+  // ReassignedIDs are the posts that got a new ID because another post had theirs, see duplicateids.go.
+  ReassignedIDs []reassignedID `json:"reassigned_ids,omitempty"`
+}
+
+func reindex(w http.ResponseWriter, r *http.Request) {
+  // The cache is always reloaded, whether the file looks changed or not. It's reloaded first: flushing the views saves the posts, the stale ones would overwrite the edits.
+  if cached, ok := storage.(*CachedStore); ok {
+    postsMu.Lock()
+    err := cached.reload()
+    postsMu.Unlock()
+    if err != nil {
+      jsonError(w, http.StatusInternalServerError, err.Error())
+      return
+    }
+  }
+  var summary reindexSummary
+  for _, views := range viewBuffer.snapshot() {
+    summary.FlushedViews += views.count
+  }
+  if err := flushViews(r.Context()); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+
+  reassigned, err := resolveDuplicateIDs(r.Context())
+  if errors.Is(err, errSharedIDs) {
+    jsonError(w, http.StatusConflict, err.Error())
+    return
+  }
+  if err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  summary.ReassignedIDs = reassigned
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+
+  authors, tags := map[string]bool{}, map[string]bool{}
+  for _, post := range posts {
+    if post.Deleted {
+      summary.Deleted++
+      continue
+    }
+    authors[post.Author] = true
+    for _, tag := range post.Tags {
+      tags[tag] = true
+    }
+  }
+  summary.Posts = len(posts) - summary.Deleted
+  summary.Authors = len(authors)
+  summary.Tags = len(tags)
+  writeJSON(w, http.StatusOK, summary)
+}
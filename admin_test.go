@@ -0,0 +1,152 @@
+package main
+
+import (
+  "encoding/json"
+  "io"
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "reflect"
+  "slices"
+  "testing"
+)
+
+const testAdminToken = "secret"
+
+// adminRequest returns a request carrying the admin token.
+func adminRequest(method, target string, body io.Reader) *http.Request {
+  r := httptest.NewRequest(method, target, body)
+  r.Header.Set("Authorization", "Bearer "+testAdminToken)
+  return r
+}
+
+func TestCompactPurgesDeletedPosts(t *testing.T) {
+  posts := testPosts()
+  posts[0].Deleted = true
+  posts[1].Title = "  Second post "
+  posts[1].Tags = []string{"go", "go", " web", ""}
+  setup(t, posts...)
+  config.AdminToken = testAdminToken
+
+  if w := serve("POST /admin/compact", requireAdmin(compact), httptest.NewRequest(http.MethodPost, "/admin/compact", nil)); w.Code != http.StatusUnauthorized {
+    t.Errorf("without the token: got status %d, want %d", w.Code, http.StatusUnauthorized)
+  }
+
+  w := serve("POST /admin/compact", requireAdmin(compact), adminRequest(http.MethodPost, "/admin/compact?reassign_ids=true", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  want := compactSummary{Posts: 2, Removed: 1, Normalized: 1, Reassigned: 2}
+  if got := decode[compactSummary](t, w); got != want {
+    t.Errorf("got summary %+v, want %+v", got, want)
+  }
+
+  stored := storedPosts(t)
+  if len(stored) != 2 {
+    t.Fatalf("got %d stored posts, want 2", len(stored))
+  }
+  if stored[0].ID != "1" || stored[0].Title != "Second post" || !slices.Equal(stored[0].Tags, []string{"go", "web"}) {
+    t.Errorf("got %+v, want the second post normalized as post 1", stored[0])
+  }
+  if stored[1].ID != "2" || stored[1].Title != "Third post" {
+    t.Errorf("got %+v, want the third post as post 2", stored[1])
+  }
+}
+
+func TestReindexAfterEditingTheFile(t *testing.T) {
+  setup(t)
+  path := useFileStore(t, `[
+  {"ID": "1", "Title": "First post", "Content": "First", "Author": "Jane Doe", "CreatedAt": "2025-01-01T10:00:00Z"},
+  {"ID": "2", "Title": "Second post", "Content": "Second", "Author": "John Smith", "CreatedAt": "2025-01-02T10:00:00Z"}
+]`)
+  storage = &CachedStore{File: storage.(*FileStore)}
+  config.AdminToken = testAdminToken
+  viewPost(t, "2", "192.0.2.1:1234")
+
+  // The file is edited by hand, and the cache doesn't know since nothing watches the file here.
+  edited := `[
+  {"ID": "1", "Title": "Edited by hand", "Content": "First", "Author": "Jane Doe", "CreatedAt": "2025-01-01T10:00:00Z", "Tags": ["go", "web"]},
+  {"ID": "2", "Title": "Second post", "Content": "Second", "Author": "John Smith", "CreatedAt": "2025-01-02T10:00:00Z"},
+  {"ID": "3", "Title": "Added by hand", "Content": "Third", "Author": "Joanna Lee", "CreatedAt": "2025-01-03T10:00:00Z", "Deleted": true}
+]`
+  if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+    t.Fatal(err)
+  }
+  if got := storedPosts(t); got[0].Title != "First post" {
+    t.Fatalf("before reindex: got %+v, want the cached posts", got[0])
+  }
+
+  w := serve("POST /admin/reindex", requireAdmin(reindex), adminRequest(http.MethodPost, "/admin/reindex", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  want := reindexSummary{Posts: 2, Deleted: 1, Authors: 2, Tags: 2, FlushedViews: 1}
+  if got := decode[reindexSummary](t, w); !reflect.DeepEqual(got, want) {
+    t.Errorf("got summary %+v, want %+v", got, want)
+  }
+
+  w = listPosts("?sort=file")
+  got := decode[[]Post](t, w)
+  if len(got) != 2 || got[0].Title != "Edited by hand" {
+    t.Errorf("after reindex: got %+v, want the edited posts", got)
+  }
+  // The buffered view was written to the edited file rather than lost.
+  if got := storedPosts(t)[1].ViewCount; got < 1 {
+    t.Errorf("got %d stored views of post 2, want the buffered view", got)
+  }
+}
+
+func TestDashboard(t *testing.T) {
+  posts := testPosts()
+  posts[0].ViewCount = 10
+  posts[0].Comments = []Comment{{ID: 1, Author: "Reader", Content: "Nice"}}
+  posts[1].ViewCount = 50
+  posts[1].Hidden = true
+  posts[2].ViewCount = 30
+  posts[2].Shares = 2
+  posts = append(posts,
+    Post{ID: "4", Title: "Draft", Content: "Draft", Author: "Jane Doe", CreatedAt: "2025-01-04T10:00:00Z", Draft: true},
+    Post{ID: "5", Title: "Deleted", Content: "Deleted", Author: "Jane Doe", CreatedAt: "2025-01-05T10:00:00Z", ViewCount: 5, Deleted: true},
+  )
+  data, err := json.Marshal(posts)
+  if err != nil {
+    t.Fatal(err)
+  }
+  setup(t)
+  path := useFileStore(t, string(data))
+  config.AdminToken = testAdminToken
+  viewBuffer.add("1", "2025-01-06T10:00:00Z")
+
+  w := serve("GET /admin/dashboard", requireAdmin(adminDashboard), adminRequest(http.MethodGet, "/admin/dashboard", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  want := dashboard{
+    Posts: 2, Drafts: 1, Hidden: 1, Deleted: 1,
+    TotalViews: 96, TotalShares: 2, Comments: 1, PendingViews: 1,
+    TopPosts: []dashboardPost{
+      {ID: "3", Title: "Third post", Views: 30, CreatedAt: "2025-01-03T10:00:00Z"},
+      {ID: "1", Title: "First post", Views: 11, CreatedAt: "2025-01-01T10:00:00Z"},
+    },
+    RecentPosts: []dashboardPost{
+      {ID: "3", Title: "Third post", Views: 30, CreatedAt: "2025-01-03T10:00:00Z"},
+      {ID: "1", Title: "First post", Views: 11, CreatedAt: "2025-01-01T10:00:00Z"},
+    },
+    Storage: dashboardStorage{Path: path, Size: int64(len(data))},
+  }
+  if got := decode[dashboard](t, w); !reflect.DeepEqual(got, want) {
+    t.Errorf("got %+v\nwant %+v", got, want)
+  }
+
+  // Nothing was written.
+  after, err := os.ReadFile(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if string(after) != string(data) {
+    t.Error("the posts file was modified")
+  }
+  if views, _ := viewBuffer.unsaved(); views != 1 {
+    t.Errorf("got %d buffered views, want the 1 still pending", views)
+  }
+}
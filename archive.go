@@ -0,0 +1,47 @@
+package main
+
+import (
+  "net/http"
+)
+
+/*
+  ARCHIVE HANDLER
+
+  Groups the post titles by the year and month they were created in:
+
+  {"2025": {"04": ["My First Post"]}, "unknown": ["Small Post"]}
+
+  Posts whose CreatedAt can't be parsed are listed under "unknown". Unlike index, looking at the archive doesn't count as viewing the posts.
+*/
+func archive(w http.ResponseWriter, r *http.Request) {
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  posts = visiblePosts(posts)
+
+  years := map[string]map[string][]string{}
+  unknown := []string{}
+  for _, post := range posts {
+    created, ok := displayTime(post.CreatedAt)
+    if !ok {
+      unknown = append(unknown, post.Title)
+      continue
+    }
+    year, month := created.Format("2006"), created.Format("01")
+    if years[year] == nil {
+      years[year] = map[string][]string{}
+    }
+    years[year][month] = append(years[year][month], post.Title)
+  }
+
+  response := map[string]any{}
+  for year, months := range years {
+    response[year] = months
+  }
+  if len(unknown) > 0 {
+    response["unknown"] = unknown
+  }
+  writeJSON(w, http.StatusOK, response)
+}
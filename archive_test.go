@@ -0,0 +1,44 @@
+package main
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "reflect"
+  "testing"
+)
+
+func TestArchiveGroupsByMonth(t *testing.T) {
+  setup(t,
+    Post{ID: "1", Title: "January", Content: "c", Author: "a", CreatedAt: "2024-01-15T10:00:00Z"},
+    Post{ID: "2", Title: "Also January", Content: "c", Author: "a", CreatedAt: "2024-01-20"},
+    Post{ID: "3", Title: "March", Content: "c", Author: "a", CreatedAt: "2024-03-01T10:00:00Z"},
+    Post{ID: "4", Title: "Next year", Content: "c", Author: "a", CreatedAt: "2025-12-31T10:00:00Z"},
+    Post{ID: "5", Title: "Undated", Content: "c", Author: "a", CreatedAt: "sometime"},
+  )
+
+  w := serve("GET /posts/archive", archive, httptest.NewRequest(http.MethodGet, "/posts/archive", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  var got map[string]json.RawMessage
+  if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+    t.Fatal(err)
+  }
+  want := map[string]string{
+    "2024":    `{"01":["January","Also January"],"03":["March"]}`,
+    "2025":    `{"12":["Next year"]}`,
+    "unknown": `["Undated"]`,
+  }
+  if len(got) != len(want) {
+    t.Errorf("got keys %v, want %v", got, want)
+  }
+  for key, value := range want {
+    var gotValue, wantValue any
+    json.Unmarshal(got[key], &gotValue)
+    json.Unmarshal([]byte(value), &wantValue)
+    if !reflect.DeepEqual(gotValue, wantValue) {
+      t.Errorf("%s: got %s, want %s", key, got[key], value)
+    }
+  }
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+  "errors"
+  "net/http"
+  "slices"
+  "sort"
+  "strings"
+)
+
+/*
+  AUTHORS HANDLER
+
+  GET /authors?q=jo suggests authors for the post creation form. A name matches when it starts with or contains the query, or, to forgive a typo or a skipped letter, when it has all the letters of the query in the same order ("jmc" matches "John McWilly"). Case doesn't matter.
+
+  Names starting with the query come first, then the ones containing it and last the fuzzy matches. Within each group the authors who wrote the most posts come first.
+
+  Without a query it returns the most prolific authors. At most AUTHOR_SUGGESTIONS (10) authors are returned.
+*/
+type authorSuggestion struct {
+  Author string `json:"author"`
+  Posts  int    `json:"posts"`
+  match  int
+}
+
+func authors(w http.ResponseWriter, r *http.Request) {
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+
+  counts := map[string]int{}
+  for _, post := range visiblePosts(posts) {
+    counts[post.Author]++
+  }
+
+  query := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+  suggestions := []authorSuggestion{}
+  for author, count := range counts {
+    if match, ok := matchAuthor(strings.ToLower(author), query); ok {
+      suggestions = append(suggestions, authorSuggestion{Author: author, Posts: count, match: match})
+    }
+  }
+  // Maps have no order in Go, sorting by name too makes the result the same every time.
+  sort.Slice(suggestions, func(i, j int) bool {
+    if suggestions[i].match != suggestions[j].match {
+      return suggestions[i].match < suggestions[j].match
+    }
+    if suggestions[i].Posts != suggestions[j].Posts {
+      return suggestions[i].Posts > suggestions[j].Posts
+    }
+    return suggestions[i].Author < suggestions[j].Author
+  })
+  if len(suggestions) > config.AuthorSuggestions {
+    suggestions = suggestions[:config.AuthorSuggestions]
+  }
+
+  writeJSON(w, http.StatusOK, suggestions)
+}
+
+// matchAuthor tells how well name matches query, lower is better.
+func matchAuthor(name, query string) (int, bool) {
+  switch {
+  case strings.HasPrefix(name, query):
+    return 0, true
+  case strings.Contains(name, query):
+    return 1, true
+  case fuzzyMatch(name, query):
+    return 2, true
+  }
+  return 0, false
+}
+
+// fuzzyMatch reports whether the runes of query appear in name in the same order, like "jmc" in "john mcwilly".
+func fuzzyMatch(name, query string) bool {
+  remaining := []rune(query)
+  for _, r := range name {
+    if len(remaining) == 0 {
+      break
+    }
+    if r == remaining[0] {
+      remaining = remaining[1:]
+    }
+  }
+  return len(remaining) == 0
+}
+
+/*
+  FEATURED AUTHORS HANDLER
+
+  GET /authors/featured returns the FEATURED_AUTHORS (a comma separated list as an environment variable) for an author spotlight on the home page, in the order of the list, with the number of posts they published and the views those got:
+
+  [{"author": "Jane Doe", "posts": 12, "views": 3400}]
+
+  Names match the Author of the posts regardless of case. Featured authors without a published post are left out, a misspelled name doesn't show up as an author who never wrote anything.
+*/
+type authorProfile struct {
+  Author string `json:"author"`
+  Posts  int    `json:"posts"`
+  Views  int64  `json:"views"`
+}
+
+func featuredAuthors(w http.ResponseWriter, r *http.Request) {
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+
+  published := visiblePosts(posts)
+  profiles := []authorProfile{}
+  for _, name := range config.FeaturedAuthors {
+    profile := authorProfile{Author: name}
+    for _, post := range published {
+      if strings.EqualFold(post.Author, name) {
+        profile.Posts++
+        profile.Views = saturatingAdd(profile.Views, post.ViewCount)
+      }
+    }
+    if profile.Posts > 0 {
+      profiles = append(profiles, profile)
+    }
+  }
+  writeJSON(w, http.StatusOK, profiles)
+}
+
+/*
+  CO-AUTHORS
+
+  Posts written by several people name the others in CoAuthors, e.g. "CoAuthors": ["John McWilly"]. Names can't be blank, and normalizeCoAuthors drops the ones listed twice or already credited as the Author, case aside.
+
+  index lists the posts someone took part in with ?contributor=, matching the Author or any of the co-authors, see writtenBy.
+*/
+func validateCoAuthors(coAuthors []string) error {
+  for _, name := range coAuthors {
+    if strings.TrimSpace(name) == "" {
+      return errors.New("co-authors can't be empty")
+    }
+  }
+  return nil
+}
+
+func normalizeCoAuthors(author string, coAuthors []string) []string {
+  if coAuthors == nil {
+    return nil
+  }
+  normalized := []string{}
+  for _, name := range coAuthors {
+    name = strings.TrimSpace(name)
+    duplicate := strings.EqualFold(name, author) || slices.ContainsFunc(normalized, func(other string) bool { return strings.EqualFold(other, name) })
+    if name != "" && !duplicate {
+      normalized = append(normalized, name)
+    }
+  }
+  return normalized
+}
+
+// writtenBy tells whether name is the author or one of the co-authors of the post, case aside.
+func (post *Post) writtenBy(name string) bool {
+  if strings.EqualFold(post.Author, name) {
+    return true
+  }
+  return slices.ContainsFunc(post.CoAuthors, func(coAuthor string) bool { return strings.EqualFold(coAuthor, name) })
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "testing"
+)
+
+// suggestedAuthors returns the names suggested by /authors for the query.
+func suggestedAuthors(t *testing.T, query string) []string {
+  t.Helper()
+  w := serve("GET /authors", authors, httptest.NewRequest(http.MethodGet, "/authors"+query, nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  names := []string{}
+  for _, suggestion := range decode[[]authorSuggestion](t, w) {
+    names = append(names, suggestion.Author)
+  }
+  return names
+}
+
+func TestAuthorSuggestions(t *testing.T) {
+  setup(t, append(testPosts(),
+    Post{ID: "4", Title: "Fourth post", Content: "Fourth", Author: "Joanna Lee", CreatedAt: "2025-01-04T10:00:00Z"},
+    Post{ID: "5", Title: "Fifth post", Content: "Fifth", Author: "Bo Jones", CreatedAt: "2025-01-05T10:00:00Z"},
+    Post{ID: "6", Title: "Sixth post", Content: "Sixth", Author: "Jody Hidden", CreatedAt: "2025-01-06T10:00:00Z", Hidden: true},
+  )...)
+
+  // Names starting with "jo" first, then containing it, then with a j and an o in that order.
+  if got, want := suggestedAuthors(t, "?q=JO"), []string{"Joanna Lee", "John Smith", "Bo Jones", "Jane Doe"}; !slices.Equal(got, want) {
+    t.Errorf("q=JO: got %v, want %v", got, want)
+  }
+  if got, want := suggestedAuthors(t, "?q=jsmth"), []string{"John Smith"}; !slices.Equal(got, want) {
+    t.Errorf("q=jsmth: got %v, want %v", got, want)
+  }
+  if got := suggestedAuthors(t, "?q=xyz"); len(got) != 0 {
+    t.Errorf("q=xyz: got %v, want none", got)
+  }
+
+  config.AuthorSuggestions = 2
+  if got := suggestedAuthors(t, ""); len(got) != 2 || got[0] != "Jane Doe" {
+    t.Errorf("no query: got %v, want the 2 top authors, Jane Doe first", got)
+  }
+}
+
+func TestCoAuthors(t *testing.T) {
+  setup(t, testPosts()...)
+
+  post := Post{Title: "Written together", Content: "Written by three people.", Author: "Mary Major", CoAuthors: []string{" Ann Lee ", "ann lee", "MARY MAJOR", "Bob Stone"}}
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", post))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("create: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  created := decode[Post](t, w)
+  if want := []string{"Ann Lee", "Bob Stone"}; !slices.Equal(created.CoAuthors, want) {
+    t.Errorf("got co-authors %q, want %q", created.CoAuthors, want)
+  }
+
+  tests := []struct {
+    contributor string
+    want        []PostID
+  }{
+    {"Ann%20Lee", []PostID{created.ID}},
+    {"bob%20stone", []PostID{created.ID}},
+    {"Mary%20Major", []PostID{created.ID}},
+    {"Jane%20Doe", []PostID{"3", "1"}},
+    {"Nobody", []PostID{}},
+  }
+  for _, test := range tests {
+    if got := listedIDs(t, "?contributor="+test.contributor); !slices.Equal(got, test.want) {
+      t.Errorf("?contributor=%s: got %v, want %v", test.contributor, got, test.want)
+    }
+  }
+
+  post.CoAuthors = []string{"Ann Lee", " "}
+  if w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", post)); w.Code != http.StatusUnprocessableEntity {
+    t.Errorf("blank co-author: got status %d, want %d", w.Code, http.StatusUnprocessableEntity)
+  }
+}
+
+func TestFeaturedAuthors(t *testing.T) {
+  posts := append(testPosts(), Post{ID: "4", Title: "Hidden post", Content: "Hidden", Author: "Jane Doe", CreatedAt: "2025-01-04T10:00:00Z", ViewCount: 100, Hidden: true})
+  posts[0].ViewCount = 10
+  posts[1].ViewCount = 7
+  posts[2].ViewCount = 5
+  setup(t, posts...)
+  config.FeaturedAuthors = []string{"John Smith", "Nobody", "jane doe"}
+  // Views waiting in the buffer count too.
+  viewBuffer.add("1", "2025-06-01T10:00:00Z")
+
+  w := serve("GET /authors/featured", featuredAuthors, httptest.NewRequest(http.MethodGet, "/authors/featured", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  want := []authorProfile{
+    {Author: "John Smith", Posts: 1, Views: 7},
+    // The hidden post isn't published, neither it nor its views count.
+    {Author: "jane doe", Posts: 2, Views: 16},
+  }
+  if got := decode[[]authorProfile](t, w); !slices.Equal(got, want) {
+    t.Errorf("got %+v, want %+v", got, want)
+  }
+}
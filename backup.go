@@ -0,0 +1,169 @@
+package main
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "log"
+  "net/http"
+  "os"
+  "path/filepath"
+  "slices"
+  "time"
+)
+
+/*
+  BACKUPS
+
+  With a BACKUP_DIR the posts are copied there when the service starts and then every BACKUP_INTERVAL (1h by default), as posts-<time>.json files. Only the BACKUP_KEEP most recent ones (24 by default) are kept.
+
+  Backups hold the posts exactly as they're stored, so with an ENCRYPTION_KEY their content is encrypted too.
+*/
+const backupTimeLayout = "20060102T150405Z"
+
+// backupPosts writes a backup of the stored posts and removes the oldest backups.
+func backupPosts(ctx context.Context) (err error) {
+  _, span := tracer.Start(ctx, "backupPosts")
+  defer func() { endSpan(span, err) }()
+
+  postsMu.Lock()
+  posts, err := storage.Load()
+  postsMu.Unlock()
+  if err != nil {
+    return err
+  }
+
+  data, err := json.MarshalIndent(posts, "", "  ")
+  if err != nil {
+    return err
+  }
+  if err := os.MkdirAll(config.BackupDir, 0755); err != nil {
+    return err
+  }
+  name := "posts-" + time.Now().UTC().Format(backupTimeLayout) + ".json"
+  if err := os.WriteFile(filepath.Join(config.BackupDir, name), data, 0644); err != nil {
+    return err
+  }
+
+  backups, err := listBackups()
+  if err != nil {
+    return err
+  }
+  for len(backups) > config.BackupKeep {
+    if err := os.Remove(backups[0]); err != nil {
+      return err
+    }
+    backups = backups[1:]
+  }
+  return nil
+}
+
+// listBackups returns the paths of the backups, oldest first. The time in their names sorts the same way as the names themselves.
+func listBackups() ([]string, error) {
+  backups, err := filepath.Glob(filepath.Join(config.BackupDir, "posts-*.json"))
+  if err != nil {
+    return nil, err
+  }
+  slices.Sort(backups)
+  return backups, nil
+}
+
+// backupEvery takes a backup right away and then every interval, until ctx is cancelled.
+func backupEvery(ctx context.Context, interval time.Duration) {
+  ticker := time.NewTicker(interval)
+  defer ticker.Stop()
+  for {
+    if err := backupPosts(ctx); err != nil {
+      log.Printf("Error backing up posts: %v", err)
+    }
+    select {
+    case <-ctx.Done():
+      return
+    case <-ticker.C:
+    }
+  }
+}
+
+// latestBackup loads the posts of the most recent backup.
+func latestBackup() ([]Post, error) {
+  backups, err := listBackups()
+  if err != nil {
+    return nil, err
+  }
+  if len(backups) == 0 {
+    return nil, errNoBackup
+  }
+  path := backups[len(backups)-1]
+  data, err := os.ReadFile(path)
+  if err != nil {
+    return nil, err
+  }
+  if data, err = decodeText(data); err != nil {
+    return nil, fmt.Errorf("%s: %w", path, err)
+  }
+  var posts []Post
+  if err := json.Unmarshal(data, &posts); err != nil {
+    return nil, fmt.Errorf("%s is corrupt: %w", path, err)
+  }
+  if err := decryptPosts(posts); err != nil {
+    return nil, err
+  }
+  return posts, nil
+}
+
+var errNoBackup = errors.New("no backup found")
+
+/*
+  RESTORE HANDLER
+
+  POST /posts/{id}/restore-from-backup puts back the version of a post from the most recent backup, e.g. after its content got mangled. The version being replaced is kept in the Revisions like with any other edit, and so are the views, shares and comments since the backup. A deleted post comes back to life.
+
+  Admins only, see admin.go.
+*/
+func restoreFromBackup(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  backup, err := latestBackup()
+  if errors.Is(err, errNoBackup) {
+    jsonError(w, http.StatusNotFound, err.Error())
+    return
+  }
+  if err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  from := slices.IndexFunc(backup, func(post Post) bool { return post.ID == id })
+  if from == -1 {
+    jsonError(w, http.StatusNotFound, "post not found in the latest backup")
+    return
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  // findPost skips deleted posts, restoring one is fine.
+  i := slices.IndexFunc(posts, func(post Post) bool { return post.ID == id })
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  current := posts[i]
+  restored := backup[from]
+  restored.Deleted = false
+  restored.ViewCount, restored.LastViewed, restored.overlay = current.ViewCount, current.LastViewed, current.overlay
+  restored.Shares, restored.Comments, restored.PinnedCommentID = current.Shares, current.Comments, current.PinnedCommentID
+  restored.Revisions = current.Revisions
+  restored.recordRevision(current)
+  restored.setUpdatedAt()
+  posts[i] = restored
+  if err := savePosts(r.Context(), posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, restored)
+}
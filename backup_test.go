@@ -0,0 +1,54 @@
+package main
+
+import (
+  "context"
+  "net/http"
+  "testing"
+)
+
+func TestRestoreFromBackup(t *testing.T) {
+  setup(t, testPosts()...)
+  config.AdminToken = testAdminToken
+  config.BackupDir = t.TempDir()
+  restore := func(id string) *http.Request {
+    return adminRequest(http.MethodPost, "/posts/"+id+"/restore-from-backup", nil)
+  }
+
+  if w := serve("POST /posts/{id}/restore-from-backup", requireAdmin(restoreFromBackup), restore("1")); w.Code != http.StatusNotFound {
+    t.Errorf("no backup: got status %d, want %d", w.Code, http.StatusNotFound)
+  }
+
+  if err := backupPosts(context.Background()); err != nil {
+    t.Fatal(err)
+  }
+  w := serve("PATCH /posts/{id}", patchPost, patchRequest(`[{"op": "replace", "path": "/Content", "value": "Mangled"}]`))
+  if w.Code != http.StatusOK {
+    t.Fatalf("patch: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  viewPost(t, "1", "192.0.2.1:1234")
+
+  w = serve("POST /posts/{id}/restore-from-backup", requireAdmin(restoreFromBackup), restore("1"))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if got := decode[Post](t, w); got.Content != "The content of the first post." || got.ViewCount != 1 {
+    t.Errorf("got Content %q with %d views, want the backed-up Content and the view since", got.Content, got.ViewCount)
+  }
+  stored := storedPosts(t)[0]
+  if stored.Content != "The content of the first post." || stored.UpdatedAt == "" {
+    t.Errorf("got stored post %+v, want the backed-up Content", stored)
+  }
+  // The mangled version is kept in the history.
+  if n := len(stored.Revisions); n == 0 || stored.Revisions[n-1].Content != "Mangled" {
+    t.Errorf("got revisions %+v, want the mangled version last", stored.Revisions)
+  }
+
+  w = serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"}))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("create: got status %d, want %d", w.Code, http.StatusCreated)
+  }
+  w = serve("POST /posts/{id}/restore-from-backup", requireAdmin(restoreFromBackup), restore("4"))
+  if got := decode[map[string]string](t, w)["error"]; w.Code != http.StatusNotFound || got != "post not found in the latest backup" {
+    t.Errorf("post not in the backup: got status %d and error %q", w.Code, got)
+  }
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+  "encoding/json"
+  "fmt"
+  "net/http"
+)
+
+/*
+  BATCH VIEWS HANDLER
+
+  POST /posts/views adds views counted elsewhere, by a reader app that was offline for instance, to several posts in a single save:
+
+  [{"id": 1, "count": 3}, {"id": "2", "count": 1}]
+
+  Counts must be positive, or nothing is applied. IDs that don't match a post don't stop the others from being applied, they're listed in the answer, like the posts that don't track their views (see TrackViews):
+
+  {"applied": 4, "unknown": ["9"], "untracked": []}
+
+  These views were already counted by the client, the debouncer and MAX_VIEWS_PER_MINUTE don't apply to them.
+*/
+type viewIncrement struct {
+  ID    PostID `json:"id"`
+  Count int64  `json:"count"`
+}
+
+type batchViewsSummary struct {
+  Applied   int64    `json:"applied"`
+  Unknown   []PostID `json:"unknown"`
+  Untracked []PostID `json:"untracked"`
+}
+
+func batchViews(w http.ResponseWriter, r *http.Request) {
+  var increments []viewIncrement
+  if err := json.NewDecoder(r.Body).Decode(&increments); err != nil {
+    jsonError(w, http.StatusBadRequest, "invalid JSON body, expected a list of {\"id\", \"count\"}")
+    return
+  }
+  defer r.Body.Close()
+  for i, increment := range increments {
+    if increment.Count <= 0 {
+      jsonError(w, http.StatusBadRequest, fmt.Sprintf("count of views[%d] must be positive, got %d", i, increment.Count))
+      return
+    }
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  summary := batchViewsSummary{Unknown: []PostID{}, Untracked: []PostID{}}
+  for _, increment := range increments {
+    i := findPost(posts, increment.ID)
+    if i == -1 {
+      summary.Unknown = append(summary.Unknown, increment.ID)
+      continue
+    }
+    post := &posts[i]
+    if !post.tracksViews() {
+      summary.Untracked = append(summary.Untracked, increment.ID)
+      continue
+    }
+    post.ViewCount = saturatingAdd(post.ViewCount, increment.Count)
+    // Not post.setLastViewed, the post may have buffered views, see viewedNow.
+    viewBuffer.viewedNow(post)
+    summary.Applied = saturatingAdd(summary.Applied, increment.Count)
+  }
+
+  if summary.Applied > 0 {
+    if err := savePosts(r.Context(), posts); err != nil {
+      jsonError(w, http.StatusInternalServerError, "Error saving posts")
+      return
+    }
+  }
+  writeJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+  "context"
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestBatchViews(t *testing.T) {
+  posts := testPosts()
+  untracked := false
+  posts[2].TrackViews = &untracked
+  setup(t, posts...)
+  store := &countingStore{Store: storage}
+  storage = store
+  // A view of post 1 waiting in the buffer, from before the batch.
+  viewBuffer.add("1", "2025-06-01T10:00:00Z")
+
+  before := time.Now().Add(-time.Second)
+  // IDs can be sent as numbers too.
+  r := httptest.NewRequest(http.MethodPost, "/posts/views", strings.NewReader(`[{"id": 1, "count": 3}, {"id": "2", "count": 1}, {"id": "9", "count": 2}, {"id": "3", "count": 5}]`))
+  w := serve("POST /posts/views", batchViews, r)
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  summary := decode[batchViewsSummary](t, w)
+  if summary.Applied != 4 || !slices.Equal(summary.Unknown, []PostID{"9"}) || !slices.Equal(summary.Untracked, []PostID{"3"}) {
+    t.Errorf("got %+v, want 4 applied, 9 unknown and 3 untracked", summary)
+  }
+  if store.saves != 1 {
+    t.Errorf("got %d saves, want 1", store.saves)
+  }
+
+  viewedRecently := func(post Post) bool {
+    viewed, err := time.Parse(time.RFC3339, post.LastViewed)
+    return err == nil && !viewed.Before(before)
+  }
+  if err := flushViews(context.Background()); err != nil {
+    t.Fatal(err)
+  }
+  stored := storedPosts(t)
+  for i, want := range []int64{4, 1, 0} {
+    if stored[i].ViewCount != want {
+      t.Errorf("post %s: got %d views, want %d", stored[i].ID, stored[i].ViewCount, want)
+    }
+  }
+  // The buffered view is older than the batch, flushing it doesn't turn LastViewed back.
+  if !viewedRecently(stored[0]) || !viewedRecently(stored[1]) || stored[2].LastViewed != "" {
+    t.Errorf("got LastViewed %q, %q and %q, want now, now and never", stored[0].LastViewed, stored[1].LastViewed, stored[2].LastViewed)
+  }
+}
+
+func TestBatchViewsRejectsNonPositiveCounts(t *testing.T) {
+  setup(t, testPosts()...)
+  r := newJSONRequest(t, http.MethodPost, "/posts/views", []viewIncrement{{ID: "1", Count: 3}, {ID: "2", Count: 0}})
+  if w := serve("POST /posts/views", batchViews, r); w.Code != http.StatusBadRequest {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusBadRequest)
+  }
+  if got := storedPosts(t)[0].ViewCount; got != 0 {
+    t.Errorf("got %d views of post 1, want none applied", got)
+  }
+}
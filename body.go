@@ -0,0 +1,81 @@
+package main
+
+import (
+  "bytes"
+  "compress/gzip"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "strings"
+)
+
+/*
+  REQUEST BODIES
+
+  Clients on slow links can gzip what they send, saying so with "Content-Encoding: gzip". Any other encoding is refused with 415 Unsupported Media Type.
+
+  A few kilobytes of gzip can decompress to gigabytes (a "zip bomb"), so the limit of MAX_BODY_BYTES (1MB by default) applies to the decompressed body: we never read more than that, and answer 413 Request Entity Too Large when there's more.
+
+  Bodies exported from Windows tools, starting with a BOM or in UTF-16, are converted to plain UTF-8 (see encoding.go).
+*/
+func readBody(r *http.Request) ([]byte, int, error) {
+  var reader io.Reader = r.Body
+  switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+  case "", "identity":
+  case "gzip":
+    gz, err := gzip.NewReader(r.Body)
+    if err != nil {
+      return nil, http.StatusBadRequest, errors.New("invalid gzip body")
+    }
+    defer gz.Close()
+    reader = gz
+  default:
+    return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported Content-Encoding %q, use gzip or none", encoding)
+  }
+
+  // Reading one byte more than the limit tells a body of exactly the limit apart from a bigger one.
+  body, err := io.ReadAll(io.LimitReader(reader, config.MaxBodyBytes+1))
+  if err != nil {
+    return nil, http.StatusBadRequest, errors.New("Error reading request body")
+  }
+  if int64(len(body)) > config.MaxBodyBytes {
+    return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("request body is larger than %d bytes", config.MaxBodyBytes)
+  }
+  if body, err = decodeText(body); err != nil {
+    return nil, http.StatusBadRequest, err
+  }
+  return body, http.StatusOK, nil
+}
+
+/*
+  DUPLICATE KEYS
+
+  json.Unmarshal silently keeps the last of two keys with the same name, {"Title": "Draft", "Title": "Final"} gives a post titled "Final". That's almost always a bug in the client, so checkDuplicateKeys reports it instead. json.Decoder.Token walks the object key by key, the values are skipped by decoding them into a json.RawMessage.
+
+  Field names are matched without regard to case by json.Unmarshal, so "Title" and "title" count as the same key. Only the keys of the top-level object are checked, anything that isn't an object is left to json.Unmarshal to complain about.
+*/
+func checkDuplicateKeys(body []byte) error {
+  decoder := json.NewDecoder(bytes.NewReader(body))
+  if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+    return nil
+  }
+  seen := map[string]bool{}
+  for decoder.More() {
+    token, err := decoder.Token()
+    if err != nil {
+      return nil
+    }
+    key := token.(string)
+    if seen[strings.ToLower(key)] {
+      return fmt.Errorf("duplicate key %q in JSON body", key)
+    }
+    seen[strings.ToLower(key)] = true
+    var value json.RawMessage
+    if err := decoder.Decode(&value); err != nil {
+      return nil
+    }
+  }
+  return nil
+}
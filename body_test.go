@@ -0,0 +1,91 @@
+package main
+
+import (
+  "bytes"
+  "compress/gzip"
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+// gzipRequest returns a create request with v encoded as its gzipped JSON body.
+func gzipRequest(t *testing.T, v any) *http.Request {
+  t.Helper()
+  var body bytes.Buffer
+  gz := gzip.NewWriter(&body)
+  if err := json.NewEncoder(gz).Encode(v); err != nil {
+    t.Fatal(err)
+  }
+  if err := gz.Close(); err != nil {
+    t.Fatal(err)
+  }
+  r := httptest.NewRequest(http.MethodPost, "/create", &body)
+  r.Header.Set("Content-Type", "application/json")
+  r.Header.Set("Content-Encoding", "gzip")
+  return r
+}
+
+func TestCreateGzipBody(t *testing.T) {
+  setup(t)
+
+  w := serve("/create", create, gzipRequest(t, Post{Title: "Zipped", Content: "Sent over a slow link", Author: "Jane Doe"}))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  if got := storedPosts(t)[0]; got.Title != "Zipped" || got.Content != "Sent over a slow link" {
+    t.Errorf("got stored post %+v", got)
+  }
+
+  // The limit applies to the decompressed body, a small gzip of a huge body is refused.
+  config.MaxBodyBytes = 1024
+  w = serve("/create", create, gzipRequest(t, Post{Title: "Bomb", Content: strings.Repeat("a", 1<<20), Author: "Jane Doe"}))
+  if w.Code != http.StatusRequestEntityTooLarge {
+    t.Errorf("zip bomb: got status %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+  }
+
+  r := newJSONRequest(t, http.MethodPost, "/create", Post{Title: "Brotli", Content: "Content", Author: "Jane Doe"})
+  r.Header.Set("Content-Encoding", "br")
+  if w := serve("/create", create, r); w.Code != http.StatusUnsupportedMediaType {
+    t.Errorf("br: got status %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+  }
+  r = newJSONRequest(t, http.MethodPost, "/create", Post{Title: "Not gzip", Content: "Content", Author: "Jane Doe"})
+  r.Header.Set("Content-Encoding", "gzip")
+  if w := serve("/create", create, r); w.Code != http.StatusBadRequest {
+    t.Errorf("invalid gzip: got status %d, want %d", w.Code, http.StatusBadRequest)
+  }
+  if got := len(storedPosts(t)); got != 1 {
+    t.Errorf("got %d stored posts, want 1", got)
+  }
+}
+
+func TestCreateRejectsDuplicateKeys(t *testing.T) {
+  tests := []struct {
+    body   string
+    status int
+  }{
+    {`{"Title": "Draft", "Content": "Content", "Author": "Jane Doe", "Title": "Final"}`, http.StatusBadRequest},
+    {`{"Title": "Draft", "Content": "Content", "Author": "Jane Doe", "title": "Final"}`, http.StatusBadRequest},
+    // Keys of nested objects aren't checked.
+    {`{"Title": "Final", "Content": "Content", "Author": "Jane Doe", "Comments": [{"ID": 1}, {"ID": 2}]}`, http.StatusCreated},
+  }
+  for _, test := range tests {
+    setup(t)
+    r := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(test.body))
+    r.Header.Set("Content-Type", "application/json")
+    w := serve("/create", create, r)
+    if w.Code != test.status {
+      t.Errorf("%s: got status %d, want %d: %s", test.body, w.Code, test.status, w.Body)
+      continue
+    }
+    if test.status == http.StatusBadRequest {
+      if got := decode[map[string]string](t, w)["error"]; !strings.HasPrefix(got, "duplicate key") {
+        t.Errorf("%s: got error %q", test.body, got)
+      }
+      if got := len(storedPosts(t)); got != 0 {
+        t.Errorf("%s: got %d stored posts, want none", test.body, got)
+      }
+    }
+  }
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+  "encoding/json"
+  "net/http"
+)
+
+/*
+  BULK UPDATE HANDLER
+
+  Applies the same changes to every post matching a filter, in a single save:
+
+  {"filter": {"Author": "Jane Doe"}, "set": {"Author": "Jane Smith"}}
+
+  A filter field that's left out matches any post, but at least one of them has to be given so a typo can't rewrite the whole blog. The response says how many posts were updated.
+*/
+type bulkUpdateRequest struct {
+  Filter struct {
+    Author *string `json:"Author"`
+    Title  *string `json:"Title"`
+  } `json:"filter"`
+  Set struct {
+    Title   *string `json:"Title"`
+    Content *string `json:"Content"`
+    Author  *string `json:"Author"`
+  } `json:"set"`
+}
+
+func bulkUpdate(w http.ResponseWriter, r *http.Request) {
+  var request bulkUpdateRequest
+  decoder := json.NewDecoder(r.Body)
+  decoder.DisallowUnknownFields()
+  if err := decoder.Decode(&request); err != nil {
+    jsonError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+    return
+  }
+  defer r.Body.Close()
+
+  filter, set := request.Filter, request.Set
+  if filter.Author == nil && filter.Title == nil {
+    jsonError(w, http.StatusBadRequest, "filter needs at least one of Author or Title")
+    return
+  }
+  if set.Title == nil && set.Content == nil && set.Author == nil {
+    jsonError(w, http.StatusBadRequest, "set needs at least one of Title, Content or Author")
+    return
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+
+  updated := 0
+  for i := range posts {
+    post := &posts[i]
+    if post.Deleted {
+      continue
+    }
+    if filter.Author != nil && post.Author != *filter.Author {
+      continue
+    }
+    if filter.Title != nil && post.Title != *filter.Title {
+      continue
+    }
+
+    previous := *post
+    if set.Title != nil {
+      post.Title = *set.Title
+    }
+    if set.Content != nil {
+      post.Content = *set.Content
+    }
+    if set.Author != nil {
+      post.Author = *set.Author
+    }
+    // The changes are the same for every post, so if one of them ends up invalid nothing is saved. They're checked like a new post, see create.
+    if errs := post.contentErrors(); len(errs) > 0 {
+      writeJSON(w, http.StatusUnprocessableEntity, newInvalidPost(errs))
+      return
+    }
+    post.CoAuthors = normalizeCoAuthors(post.Author, post.CoAuthors)
+    post.recordRevision(previous)
+    post.setUpdatedAt()
+    updated++
+  }
+
+  if updated > 0 {
+    if err := savePosts(r.Context(), posts); err != nil {
+      jsonError(w, http.StatusInternalServerError, "Error saving posts")
+      return
+    }
+  }
+
+  writeJSON(w, http.StatusOK, map[string]int{"updated": updated})
+}
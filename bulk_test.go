@@ -0,0 +1,87 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "strings"
+  "testing"
+)
+
+// bulkRequest returns a PATCH /posts/bulk with the given body.
+func bulkRequest(body string) *http.Request {
+  return httptest.NewRequest(http.MethodPatch, "/posts/bulk", strings.NewReader(body))
+}
+
+func TestBulkRenamesAuthor(t *testing.T) {
+  posts := testPosts()
+  posts[2].CoAuthors = []string{"Jane Smith", "John Smith"}
+  setup(t, posts...)
+
+  w := serve("PATCH /posts/bulk", bulkUpdate, bulkRequest(`{"filter": {"Author": "Jane Doe"}, "set": {"Author": "Jane Smith"}}`))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if got := decode[map[string]int](t, w)["updated"]; got != 2 {
+    t.Errorf("got %d updated posts, want 2", got)
+  }
+  stored := storedPosts(t)
+  for i, want := range []string{"Jane Smith", "John Smith", "Jane Smith"} {
+    if stored[i].Author != want {
+      t.Errorf("post %s: got Author %q, want %q", stored[i].ID, stored[i].Author, want)
+    }
+  }
+  if stored[0].UpdatedAt == "" || stored[1].UpdatedAt != "" {
+    t.Errorf("got UpdatedAt %q and %q, want only the updated post to have one", stored[0].UpdatedAt, stored[1].UpdatedAt)
+  }
+  // The new author isn't their own co-author.
+  if got := stored[2].CoAuthors; !slices.Equal(got, []string{"John Smith"}) {
+    t.Errorf("got CoAuthors %v, want [John Smith]", got)
+  }
+}
+
+func TestBulkValidatesChanges(t *testing.T) {
+  tests := []struct {
+    name   string
+    setup  func()
+    body   string
+    status int
+  }{
+    {"no filter", nil, `{"filter": {}, "set": {"Author": "Jane Smith"}}`, http.StatusBadRequest},
+    {"nothing to set", nil, `{"filter": {"Author": "Jane Doe"}, "set": {}}`, http.StatusBadRequest},
+    {"unknown field", nil, `{"filter": {"Author": "Jane Doe"}, "set": {"ViewCount": 0}}`, http.StatusBadRequest},
+    {"empty author", nil, `{"filter": {"Author": "Jane Doe"}, "set": {"Author": ""}}`, http.StatusUnprocessableEntity},
+    {"profanity", func() { config.ProfanityMode, config.ProfanityWords = profanityReject, []string{"darn"} }, `{"filter": {"Author": "Jane Doe"}, "set": {"Title": "Darn it"}}`, http.StatusUnprocessableEntity},
+    {"too short", func() { config.MinContentLength = 50 }, `{"filter": {"Author": "Jane Doe"}, "set": {"Content": "Too short"}}`, http.StatusUnprocessableEntity},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t, testPosts()...)
+      if test.setup != nil {
+        test.setup()
+      }
+      w := serve("PATCH /posts/bulk", bulkUpdate, bulkRequest(test.body))
+      if w.Code != test.status {
+        t.Fatalf("got status %d, want %d: %s", w.Code, test.status, w.Body)
+      }
+      for _, post := range storedPosts(t) {
+        if post.UpdatedAt != "" {
+          t.Errorf("post %s was updated", post.ID)
+        }
+      }
+    })
+  }
+}
+
+func TestBulkMasksProfanity(t *testing.T) {
+  setup(t, testPosts()...)
+  config.ProfanityMode, config.ProfanityWords = profanityMask, []string{"darn"}
+
+  w := serve("PATCH /posts/bulk", bulkUpdate, bulkRequest(`{"filter": {"Title": "First post"}, "set": {"Title": "Darn it"}}`))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if got := storedPosts(t)[0].Title; got != "**** it" {
+    t.Errorf("got Title %q, want %q", got, "**** it")
+  }
+}
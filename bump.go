@@ -0,0 +1,40 @@
+package main
+
+import (
+  "net/http"
+)
+
+/*
+  BUMP HANDLER
+
+  POST /posts/{id}/bump reposts evergreen content: it sets the CreatedAt and UpdatedAt of the post to now, which brings it back to the top of index?sort=created_at and of the feed, and returns the bumped post.
+
+  The first bump keeps the date the post was really created in OriginalCreatedAt, later bumps leave it alone.
+*/
+func bump(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  post := &posts[i]
+  if post.OriginalCreatedAt == "" {
+    post.OriginalCreatedAt = post.CreatedAt
+  }
+  post.setCreatedAt()
+  post.setUpdatedAt()
+  if err := savePosts(r.Context(), posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, post)
+}
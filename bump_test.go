@@ -0,0 +1,43 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "testing"
+  "time"
+)
+
+func TestBump(t *testing.T) {
+  setup(t, testPosts()...)
+  if got := listedIDs(t, "?sort=created_at"); !slices.Equal(got, []PostID{"3", "2", "1"}) {
+    t.Fatalf("before: got %v, want [3 2 1]", got)
+  }
+
+  before := time.Now().Add(-time.Second)
+  w := serve("POST /posts/{id}/bump", bump, httptest.NewRequest(http.MethodPost, "/posts/1/bump", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  stored := storedPosts(t)[0]
+  created, err := time.Parse(time.RFC3339, stored.CreatedAt)
+  if err != nil || created.Before(before) || stored.UpdatedAt != stored.CreatedAt {
+    t.Errorf("got CreatedAt %q and UpdatedAt %q, want both now", stored.CreatedAt, stored.UpdatedAt)
+  }
+  if stored.OriginalCreatedAt != "2025-01-01T10:00:00Z" {
+    t.Errorf("got OriginalCreatedAt %q, want 2025-01-01T10:00:00Z", stored.OriginalCreatedAt)
+  }
+  if got := listedIDs(t, "?sort=created_at"); !slices.Equal(got, []PostID{"1", "3", "2"}) {
+    t.Errorf("after: got %v, want [1 3 2]", got)
+  }
+
+  // A second bump keeps the date the post was really created.
+  serve("POST /posts/{id}/bump", bump, httptest.NewRequest(http.MethodPost, "/posts/1/bump", nil))
+  if got := storedPosts(t)[0].OriginalCreatedAt; got != "2025-01-01T10:00:00Z" {
+    t.Errorf("second bump: got OriginalCreatedAt %q, want 2025-01-01T10:00:00Z", got)
+  }
+
+  if w := serve("POST /posts/{id}/bump", bump, httptest.NewRequest(http.MethodPost, "/posts/9/bump", nil)); w.Code != http.StatusNotFound {
+    t.Errorf("unknown post: got status %d, want %d", w.Code, http.StatusNotFound)
+  }
+}
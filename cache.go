@@ -0,0 +1,165 @@
+package main
+
+import (
+  "context"
+  "encoding/json"
+  "log"
+  "os"
+  "path/filepath"
+  "sync"
+  "time"
+
+  "github.com/fsnotify/fsnotify"
+)
+
+/*
+  POSTS CACHE
+
+  Every request reads and decodes the posts file. With CACHE_POSTS the posts are kept in memory instead and the file is only written to, CachedStore wraps the FileStore to do so. Like MemoryStore it keeps the posts as JSON, so every Load hands out a fresh copy.
+
+  The cache must not go stale when posts.json is edited by hand, so watchPosts reloads it when the file changes (see below). Streaming (STREAM_POSTS) makes no sense with the posts in memory, a CachedStore isn't a PostStreamer.
+*/
+type CachedStore struct {
+  File *FileStore
+
+  mu   sync.Mutex
+  data []byte
+  // written is the state of the file after our last Save, to tell our own writes apart from the edits made by someone else.
+  written fileState
+}
+
+// fileState is what tells two versions of a file apart without reading them.
+type fileState struct {
+  modTime time.Time
+  size    int64
+}
+
+func statFile(path string) fileState {
+  info, err := os.Stat(path)
+  if err != nil {
+    return fileState{}
+  }
+  return fileState{info.ModTime(), info.Size()}
+}
+
+func (store *CachedStore) Load() ([]Post, error) {
+  store.mu.Lock()
+  defer store.mu.Unlock()
+  if store.data == nil {
+    if err := store.fill(); err != nil {
+      return nil, err
+    }
+  }
+  posts := []Post{}
+  if err := json.Unmarshal(store.data, &posts); err != nil {
+    return nil, err
+  }
+  return posts, nil
+}
+
+func (store *CachedStore) Save(posts []Post) error {
+  data, err := json.Marshal(posts)
+  if err != nil {
+    return err
+  }
+  store.mu.Lock()
+  defer store.mu.Unlock()
+  if err := store.File.Save(posts); err != nil {
+    // The file may or may not have been written, the next Load reads it again.
+    store.data = nil
+    return err
+  }
+  store.data = data
+  store.written = statFile(store.File.Path)
+  return nil
+}
+
+// reload reads the file again. Callers hold postsMu so the file isn't being written in the meantime.
+func (store *CachedStore) reload() error {
+  store.mu.Lock()
+  defer store.mu.Unlock()
+  return store.fill()
+}
+
+// fill expects store.mu to be held.
+func (store *CachedStore) fill() error {
+  posts, err := store.File.Load()
+  if err != nil {
+    return err
+  }
+  data, err := json.Marshal(posts)
+  if err != nil {
+    return err
+  }
+  store.data = data
+  store.written = statFile(store.File.Path)
+  return nil
+}
+
+// changedOnDisk reports whether the file is no longer the one we last read or wrote.
+func (store *CachedStore) changedOnDisk() bool {
+  store.mu.Lock()
+  defer store.mu.Unlock()
+  return statFile(store.File.Path) != store.written
+}
+
+/*
+  WATCHING THE FILE
+
+  fsnotify asks the operating system to tell us when a file changes (inotify on Linux, kqueue on macOS...) rather than checking it over and over. The directory is watched rather than the file itself: many editors save by writing a new file and renaming it over the old one, and a watch on the old file would be lost with it.
+
+  Saving a file often comes as a burst of events, so the reload waits until the file has been quiet for watchDebounce. It then takes postsMu, so it never reads a half written file of ours, and skips the reload when the file is the one we wrote last.
+*/
+const watchDebounce = 200 * time.Millisecond
+
+func watchPosts(ctx context.Context, store *CachedStore) error {
+  watcher, err := fsnotify.NewWatcher()
+  if err != nil {
+    return err
+  }
+  path, err := filepath.Abs(store.File.Path)
+  if err != nil {
+    watcher.Close()
+    return err
+  }
+  if err := watcher.Add(filepath.Dir(path)); err != nil {
+    watcher.Close()
+    return err
+  }
+
+  go func() {
+    defer watcher.Close()
+    var timer *time.Timer
+    for {
+      select {
+      case <-ctx.Done():
+        return
+      case event := <-watcher.Events:
+        if event.Name != path || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) {
+          continue
+        }
+        if timer != nil {
+          timer.Stop()
+        }
+        timer = time.AfterFunc(watchDebounce, func() { reloadChangedPosts(store) })
+      case err := <-watcher.Errors:
+        log.Printf("Error watching %s: %v", path, err)
+      }
+    }
+  }()
+  return nil
+}
+
+func reloadChangedPosts(store *CachedStore) {
+  postsMu.Lock()
+  defer postsMu.Unlock()
+  if !store.changedOnDisk() {
+    return
+  }
+  if err := store.reload(); err != nil {
+    // The cache keeps the posts it had, a broken file shouldn't take the site down.
+    log.Printf("%s changed but can't be reloaded: %v", store.File.Path, err)
+    return
+  }
+  log.Printf("%s changed, posts reloaded", store.File.Path)
+}
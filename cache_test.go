@@ -0,0 +1,51 @@
+package main
+
+import (
+  "context"
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "testing"
+  "time"
+)
+
+func TestCacheReloadsExternalEdits(t *testing.T) {
+  setup(t)
+  path := useFileStore(t, `[{"ID": "1", "Title": "First post", "Content": "First", "Author": "Jane Doe", "CreatedAt": "2025-01-01T10:00:00Z"}]`)
+  cached := &CachedStore{File: storage.(*FileStore)}
+  storage = cached
+  ctx, cancel := context.WithCancel(context.Background())
+  defer cancel()
+  if err := watchPosts(ctx, cached); err != nil {
+    t.Fatal(err)
+  }
+
+  showTitle := func() string {
+    w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+    return decode[Post](t, w).Title
+  }
+  if got := showTitle(); got != "First post" {
+    t.Fatalf("got title %q, want %q", got, "First post")
+  }
+
+  // Our own writes don't count as a change.
+  w := serve("PATCH /posts/{id}", lockPosts(patchPost), patchRequest(`[{"op": "replace", "path": "/Title", "value": "Patched"}]`))
+  if w.Code != http.StatusOK {
+    t.Fatalf("patch: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if cached.changedOnDisk() {
+    t.Error("the file looks changed after our own save")
+  }
+
+  edited := `[{"ID": "1", "Title": "Edited by hand", "Content": "First", "Author": "Jane Doe", "CreatedAt": "2025-01-01T10:00:00Z"}]`
+  if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+    t.Fatal(err)
+  }
+  deadline := time.Now().Add(5 * time.Second)
+  for showTitle() != "Edited by hand" {
+    if time.Now().After(deadline) {
+      t.Fatalf("the cache still has %q after the file was edited", showTitle())
+    }
+    time.Sleep(20 * time.Millisecond)
+  }
+}
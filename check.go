@@ -0,0 +1,114 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "log"
+)
+
+/*
+  SELF-CHECK
+
+  Before accepting requests main loads the posts and checks them, so a broken posts file shows up in the logs right away instead of on the first request. With STRICT_STARTUP the service refuses to start when anything is wrong.
+*/
+type problem struct {
+  ID      PostID `json:"id"`
+  Field   string `json:"field,omitempty"`
+  Message string `json:"message"`
+}
+
+func (p problem) String() string {
+  if p.Field == "" {
+    return fmt.Sprintf("post %s: %s", p.ID, p.Message)
+  }
+  return fmt.Sprintf("post %s: %s: %s", p.ID, p.Field, p.Message)
+}
+
+// checkPosts returns every problem found in the posts: missing required fields, missing IDs and IDs used more than once.
+func checkPosts(posts []Post) []problem {
+  checker := newPostChecker()
+  for _, post := range posts {
+    checker.check(post)
+  }
+  return checker.problems
+}
+
+// postChecker checks posts one at a time, remembering the IDs it has seen to spot the duplicates. Streamed posts are checked as they're read, see stream.go.
+type postChecker struct {
+  seen     map[PostID]int
+  problems []problem
+}
+
+func newPostChecker() *postChecker {
+  return &postChecker{seen: map[PostID]int{}, problems: []problem{}}
+}
+
+func (checker *postChecker) check(post Post) {
+  if post.ID == "" {
+    checker.problems = append(checker.problems, problem{ID: post.ID, Field: "ID", Message: "is required"})
+  } else {
+    // Only report a duplicate once, however many posts share the ID.
+    checker.seen[post.ID]++
+    if checker.seen[post.ID] == 2 {
+      checker.problems = append(checker.problems, problem{ID: post.ID, Field: "ID", Message: "is used by more than one post"})
+    }
+  }
+  for _, field := range requiredFields {
+    if fieldValue(post, field) == "" {
+      checker.problems = append(checker.problems, problem{ID: post.ID, Field: field, Message: "is required"})
+    }
+  }
+}
+
+// fieldValue returns the value of one of the requiredFields.
+func fieldValue(post Post, field string) string {
+  switch field {
+  case "Title":
+    return post.Title
+  case "Content":
+    return post.Content
+  case "Author":
+    return post.Author
+  }
+  return ""
+}
+
+// startupCheck logs a summary of the posts and returns an error when they can't be loaded or have problems.
+func startupCheck(ctx context.Context) error {
+  checker := newPostChecker()
+  loaded := 0
+  // Streaming stores are checked without loading the whole file, see stream.go.
+  if streamer, ok := storage.(PostStreamer); ok && config.StreamPosts {
+    err := streamer.Each(func(post Post) error {
+      one := []Post{post}
+      if err := decryptPosts(one); err != nil {
+        return err
+      }
+      checker.check(one[0])
+      loaded++
+      return nil
+    })
+    if err != nil {
+      return err
+    }
+  } else {
+    var posts []Post
+    if err := loadPost(ctx, &posts); err != nil {
+      return err
+    }
+    for _, post := range posts {
+      checker.check(post)
+    }
+    loaded = len(posts)
+  }
+
+  problems := checker.problems
+  for _, p := range problems {
+    log.Printf("Self-check: %s", p)
+  }
+  log.Printf("Self-check: %d posts loaded, %d problems found", loaded, len(problems))
+  if len(problems) > 0 {
+    return fmt.Errorf("%d problems found in the posts", len(problems))
+  }
+  return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+  "context"
+  "os"
+  "os/exec"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+const badPosts = `[
+  {"ID": "1", "Title": "First post", "Content": "First", "Author": "Jane Doe"},
+  {"ID": "2", "Title": "", "Content": "No title", "Author": "Jane Doe"}
+]`
+
+func TestStartupCheckReportsProblems(t *testing.T) {
+  setup(t)
+  useFileStore(t, badPosts)
+
+  err := startupCheck(context.Background())
+  if err == nil || !strings.Contains(err.Error(), "1 problems found") {
+    t.Errorf("got error %v, want 1 problem", err)
+  }
+  problems := checkPosts([]Post{{ID: "1", Title: "a", Content: "b", Author: "c"}, {ID: "1", Content: "b", Author: "c"}, {Title: "a", Content: "b", Author: "c"}})
+  want := []string{"post 1: ID: is used by more than one post", "post 1: Title: is required", "post : ID: is required"}
+  if len(problems) != len(want) {
+    t.Fatalf("got problems %v, want %v", problems, want)
+  }
+  for i, p := range problems {
+    if p.String() != want[i] {
+      t.Errorf("got problem %q, want %q", p, want[i])
+    }
+  }
+
+  useFileStore(t, `[{"ID": "1", "Title": "First post", "Content": "First", "Author": "Jane Doe"}]`)
+  if err := startupCheck(context.Background()); err != nil {
+    t.Errorf("valid posts: got error %v", err)
+  }
+}
+
+/*
+  TestStrictStartupFails runs the service itself, in a copy of the test binary: main exits the process when the self-check fails, which a test can't survive. The child process tells it's the service from RUN_MAIN.
+*/
+func TestStrictStartupFails(t *testing.T) {
+  if os.Getenv("RUN_MAIN") == "1" {
+    main()
+    return
+  }
+  dir := t.TempDir()
+  path := filepath.Join(dir, "posts.json")
+  if err := os.WriteFile(path, []byte(badPosts), 0644); err != nil {
+    t.Fatal(err)
+  }
+
+  cmd := exec.Command(os.Args[0], "-test.run=^TestStrictStartupFails$")
+  cmd.Dir = dir
+  cmd.Env = append(os.Environ(), "RUN_MAIN=1", "STRICT_STARTUP=true", "POSTS_FILE="+path)
+  output, err := cmd.CombinedOutput()
+  if _, ok := err.(*exec.ExitError); !ok {
+    t.Fatalf("got error %v, want the service to exit with an error: %s", err, output)
+  }
+  if !strings.Contains(string(output), "Self-check failed: 1 problems found") {
+    t.Errorf("got output %s, want the self-check failure", output)
+  }
+}
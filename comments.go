@@ -0,0 +1,204 @@
+package main
+
+import (
+  "encoding/json"
+  "errors"
+  "net/http"
+  "sort"
+  "strings"
+  "time"
+)
+
+/*
+  COMMENTS
+
+  Readers comment on a post with POST /posts/{id}/comments and GET /posts/{id}/comments lists them, oldest first. Comments are stored inside their post, numbered from 1 in the order they were made.
+
+  The author can pin one of them, say a correction, with POST /posts/{id}/pinned-comment {"comment_id":2}. The pinned comment is listed first, and DELETE /posts/{id}/pinned-comment unpins it.
+
+  POST /posts/{id}/toggle-comments closes the comments of a post attracting spam, or opens them again. New comments on a closed post are refused with a 403, the existing ones are still listed.
+*/
+type Comment struct {
+  ID        int    `json:"ID"`
+  Author    string `json:"Author"`
+  Content   string `json:"Content"`
+  CreatedAt string `json:"CreatedAt"`
+}
+
+// findComment returns the index of the comment with the given id, or -1 when the post has no such comment.
+func (post *Post) findComment(id int) int {
+  for i, comment := range post.Comments {
+    if comment.ID == id {
+      return i
+    }
+  }
+  return -1
+}
+
+// sortedComments returns the comments of the post with the pinned one first.
+func (post *Post) sortedComments() []Comment {
+  comments := append([]Comment{}, post.Comments...)
+  sort.SliceStable(comments, func(i, j int) bool {
+    return comments[i].ID == post.PinnedCommentID && comments[j].ID != post.PinnedCommentID
+  })
+  return comments
+}
+
+func listComments(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  // The comments are shown in the configured time zone like the post itself, see timezone.go.
+  post := posts[i].inDisplayZone()
+  writeJSON(w, http.StatusOK, post.sortedComments())
+}
+
+func addComment(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  body, status, err := readBody(r)
+  if err != nil {
+    jsonError(w, status, err.Error())
+    return
+  }
+  var comment Comment
+  if err := json.Unmarshal(body, &comment); err != nil {
+    jsonError(w, http.StatusBadRequest, "Invalid JSON")
+    return
+  }
+  comment.Author = strings.TrimSpace(comment.Author)
+  comment.Content = strings.TrimSpace(comment.Content)
+  if comment.Author == "" {
+    comment.Author = config.DefaultAuthor
+  }
+  if err := comment.validate(); err != nil {
+    jsonError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  post := &posts[i]
+  if !post.commentsEnabled() {
+    jsonError(w, http.StatusForbidden, "comments are closed on this post")
+    return
+  }
+  // Comments are never removed, so the last one has the highest ID.
+  comment.ID = 1
+  if len(post.Comments) > 0 {
+    comment.ID = post.Comments[len(post.Comments)-1].ID + 1
+  }
+  comment.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+  post.Comments = append(post.Comments, comment)
+  if err := savePosts(r.Context(), posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  comment.CreatedAt = displayTimestamp(comment.CreatedAt)
+  writeJSON(w, http.StatusCreated, comment)
+}
+
+func (comment *Comment) validate() error {
+  if comment.Content == "" {
+    return errors.New("Content is required")
+  }
+  if comment.Author == "" {
+    return errors.New("Author is required")
+  }
+  return nil
+}
+
+func pinComment(w http.ResponseWriter, r *http.Request) {
+  var request struct {
+    CommentID int `json:"comment_id"`
+  }
+  if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+    jsonError(w, http.StatusBadRequest, "Invalid JSON")
+    return
+  }
+  updatePinnedComment(w, r, request.CommentID)
+}
+
+func unpinComment(w http.ResponseWriter, r *http.Request) {
+  updatePinnedComment(w, r, 0)
+}
+
+// updatePinnedComment pins the comment with the given id, 0 unpins whichever comment was pinned.
+func updatePinnedComment(w http.ResponseWriter, r *http.Request, commentID int) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  post := &posts[i]
+  if commentID != 0 && post.findComment(commentID) == -1 {
+    jsonError(w, http.StatusNotFound, "comment not found")
+    return
+  }
+  post.PinnedCommentID = commentID
+  if err := savePosts(r.Context(), posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, map[string]any{"id": post.ID, "pinned_comment_id": post.PinnedCommentID})
+}
+
+// commentsEnabled reports whether the post takes new comments, which it does unless they were closed.
+func (post *Post) commentsEnabled() bool {
+  return post.CommentsEnabled == nil || *post.CommentsEnabled
+}
+
+func toggleComments(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  post := &posts[i]
+  enabled := !post.commentsEnabled()
+  post.CommentsEnabled = &enabled
+  post.setUpdatedAt()
+  if err := savePosts(r.Context(), posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, map[string]any{"id": post.ID, "comments_enabled": enabled})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "testing"
+)
+
+// addTestComment comments on post id and returns the response.
+func addTestComment(t *testing.T, id, content string) *httptest.ResponseRecorder {
+  t.Helper()
+  return serve("POST /posts/{id}/comments", addComment, newJSONRequest(t, http.MethodPost, "/posts/"+id+"/comments", Comment{Author: "Reader", Content: content}))
+}
+
+// commentIDs returns the IDs of the comments of post id, in the order they're listed.
+func commentIDs(t *testing.T, id string) []int {
+  t.Helper()
+  w := serve("GET /posts/{id}/comments", listComments, httptest.NewRequest(http.MethodGet, "/posts/"+id+"/comments", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  listed := []int{}
+  for _, comment := range decode[[]Comment](t, w) {
+    listed = append(listed, comment.ID)
+  }
+  return listed
+}
+
+func TestPinnedComment(t *testing.T) {
+  setup(t, testPosts()...)
+  for _, content := range []string{"Great post", "There's a typo", "Thanks"} {
+    if w := addTestComment(t, "1", content); w.Code != http.StatusCreated {
+      t.Fatalf("comment: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+    }
+  }
+  if got, want := commentIDs(t, "1"), []int{1, 2, 3}; !slices.Equal(got, want) {
+    t.Fatalf("got %v, want %v", got, want)
+  }
+
+  w := serve("POST /posts/{id}/pinned-comment", pinComment, newJSONRequest(t, http.MethodPost, "/posts/1/pinned-comment", map[string]int{"comment_id": 2}))
+  if w.Code != http.StatusOK {
+    t.Fatalf("pin: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if got, want := commentIDs(t, "1"), []int{2, 1, 3}; !slices.Equal(got, want) {
+    t.Errorf("pinned: got %v, want %v", got, want)
+  }
+
+  w = serve("POST /posts/{id}/pinned-comment", pinComment, newJSONRequest(t, http.MethodPost, "/posts/1/pinned-comment", map[string]int{"comment_id": 9}))
+  if w.Code != http.StatusNotFound {
+    t.Errorf("missing comment: got status %d, want %d", w.Code, http.StatusNotFound)
+  }
+
+  w = serve("DELETE /posts/{id}/pinned-comment", unpinComment, httptest.NewRequest(http.MethodDelete, "/posts/1/pinned-comment", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("unpin: got status %d, want %d", w.Code, http.StatusOK)
+  }
+  if got, want := commentIDs(t, "1"), []int{1, 2, 3}; !slices.Equal(got, want) {
+    t.Errorf("unpinned: got %v, want %v", got, want)
+  }
+  if got := storedPosts(t)[0].PinnedCommentID; got != 0 {
+    t.Errorf("got PinnedCommentID %d, want 0", got)
+  }
+}
+
+func TestToggleComments(t *testing.T) {
+  setup(t, testPosts()...)
+  toggle := func() bool {
+    t.Helper()
+    w := serve("POST /posts/{id}/toggle-comments", toggleComments, httptest.NewRequest(http.MethodPost, "/posts/1/toggle-comments", nil))
+    if w.Code != http.StatusOK {
+      t.Fatalf("toggle: got status %d, want %d", w.Code, http.StatusOK)
+    }
+    return decode[map[string]any](t, w)["comments_enabled"].(bool)
+  }
+  if w := addTestComment(t, "1", "Before closing"); w.Code != http.StatusCreated {
+    t.Fatalf("open: got status %d, want %d", w.Code, http.StatusCreated)
+  }
+
+  if toggle() {
+    t.Fatal("got comments enabled, want them closed")
+  }
+  w := addTestComment(t, "1", "Spam")
+  if w.Code != http.StatusForbidden {
+    t.Errorf("closed: got status %d, want %d", w.Code, http.StatusForbidden)
+  }
+  // The existing comments are still listed, and other posts still take comments.
+  if got := commentIDs(t, "1"); len(got) != 1 {
+    t.Errorf("closed: got comments %v, want the one made before", got)
+  }
+  if w := addTestComment(t, "2", "Elsewhere"); w.Code != http.StatusCreated {
+    t.Errorf("other post: got status %d, want %d", w.Code, http.StatusCreated)
+  }
+
+  if !toggle() {
+    t.Fatal("got comments closed, want them open again")
+  }
+  if w := addTestComment(t, "1", "After reopening"); w.Code != http.StatusCreated {
+    t.Errorf("reopened: got status %d, want %d", w.Code, http.StatusCreated)
+  }
+}
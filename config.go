@@ -0,0 +1,472 @@
+package main
+
+import (
+  "crypto/tls"
+  "encoding"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "log/slog"
+  "os"
+  "path/filepath"
+  "reflect"
+  "strconv"
+  "strings"
+  "time"
+
+  "gopkg.in/yaml.v3"
+)
+
+/*
+  CONFIGURATION
+
+  All the settings of the service live in a Config. They're resolved in three steps, each one overriding the previous:
+
+  1. The defaults from defaultConfig.
+  2. The config file pointed to by CONFIG_FILE, JSON or YAML depending on its extension.
+  3. Environment variables, named by the env struct tag of each field.
+
+  The merged config is validated once at startup, so handlers can trust the values they read.
+*/
+type Config struct {
+  Port int `json:"port" yaml:"port" env:"PORT"`
+  // TLSCert and TLSKey are the paths of a PEM certificate and its key, the service is served over HTTPS (and HTTP/2) when both are set.
+  TLSCert  string `json:"tls_cert" yaml:"tls_cert" env:"TLS_CERT"`
+  TLSKey   string `json:"tls_key" yaml:"tls_key" env:"TLS_KEY"`
+  FilePath string `json:"file_path" yaml:"file_path" env:"POSTS_FILE"`
+  // PostsDir keeps every post in its own file in that directory instead of FilePath, see dirstore.go.
+  PostsDir string `json:"posts_dir" yaml:"posts_dir" env:"POSTS_DIR"`
+  // FileMode is the permissions of the posts file, written in octal like chmod, e.g. "0640".
+  FileMode FileMode `json:"file_mode" yaml:"file_mode" env:"FILE_MODE"`
+  // TrailingNewline ends the posts file with a newline, as most editors and formatting checks expect.
+  TrailingNewline bool `json:"trailing_newline" yaml:"trailing_newline" env:"TRAILING_NEWLINE"`
+  // SettingsFile is where the site settings changed with PUT /settings are kept, see settings.go.
+  SettingsFile string `json:"settings_file" yaml:"settings_file" env:"SETTINGS_FILE"`
+  // MaxViewsPerMinute caps the views counted per post and per minute, 0 means no cap, see viewcap.go.
+  MaxViewsPerMinute int `json:"max_views_per_minute" yaml:"max_views_per_minute" env:"MAX_VIEWS_PER_MINUTE"`
+  // DuplicateIDs is what the startup check and reindex do with posts sharing an ID: fail or reassign, see duplicateids.go.
+  DuplicateIDs string `json:"duplicate_ids" yaml:"duplicate_ids" env:"DUPLICATE_IDS"`
+  // ViewLog is the write-ahead log of the buffered views, the creates and the shares, replayed at startup so a crash doesn't lose them, see wal.go. Empty disables it.
+  ViewLog string `json:"view_log" yaml:"view_log" env:"VIEW_LOG"`
+  // MemoryOnly keeps the posts in memory instead of FilePath, they're lost when the service stops.
+  MemoryOnly bool `json:"memory_only" yaml:"memory_only" env:"MEMORY_ONLY"`
+  // RecoverMode loads as many posts as possible from a truncated posts file instead of refusing to use it.
+  RecoverMode bool `json:"recover_mode" yaml:"recover_mode" env:"RECOVER_MODE"`
+  // StreamPosts makes index read the posts file one post at a time, keeping only the listed ones in memory, see stream.go.
+  StreamPosts bool `json:"stream_posts" yaml:"stream_posts" env:"STREAM_POSTS"`
+  // CachePosts keeps the posts in memory rather than reading the file for every request, reloading them when the file is edited, see cache.go.
+  CachePosts bool `json:"cache_posts" yaml:"cache_posts" env:"CACHE_POSTS"`
+  // IDStrategy is how new posts get their ID: sequential, uuid or ksuid, see ids.go.
+  IDStrategy string `json:"id_strategy" yaml:"id_strategy" env:"ID_STRATEGY"`
+
+  // EncryptionKey encrypts the Content of the posts at rest when set, see crypto.go.
+  EncryptionKey string `json:"encryption_key" yaml:"encryption_key" env:"ENCRYPTION_KEY"`
+  // TracingEndpoint is the OTLP/HTTP endpoint spans are exported to (e.g. http://localhost:4318), tracing is disabled when empty.
+  TracingEndpoint string `json:"tracing_endpoint" yaml:"tracing_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+  // StrictStartup refuses to start when the posts can't be loaded or fail the self-check, see check.go.
+  StrictStartup bool `json:"strict_startup" yaml:"strict_startup" env:"STRICT_STARTUP"`
+  // BackupDir is where backups of the posts are written every BackupInterval, keeping the BackupKeep most recent ones. No backups are taken when it's empty, see backup.go.
+  BackupDir      string   `json:"backup_dir" yaml:"backup_dir" env:"BACKUP_DIR"`
+  BackupInterval Duration `json:"backup_interval" yaml:"backup_interval" env:"BACKUP_INTERVAL"`
+  BackupKeep     int      `json:"backup_keep" yaml:"backup_keep" env:"BACKUP_KEEP"`
+  // ForceHTTPS redirects the requests that didn't come over HTTPS, see https.go.
+  ForceHTTPS bool `json:"force_https" yaml:"force_https" env:"FORCE_HTTPS"`
+  // AdminToken is the bearer token of the /admin routes, they're disabled when it's empty.
+  AdminToken string `json:"admin_token" yaml:"admin_token" env:"ADMIN_TOKEN"`
+  // ReadOnly makes every handler that would write to the posts file refuse to do so.
+  ReadOnly bool `json:"read_only" yaml:"read_only" env:"READ_ONLY"`
+  // JSONCase controls the casing of the keys in our JSON responses, see response.go.
+  JSONCase string `json:"json_case" yaml:"json_case" env:"JSON_CASE"`
+  // CacheMaxAge is the number of seconds browsers and proxies may cache the responses of read routes for. 0 leaves the Cache-Control header out.
+  CacheMaxAge int `json:"cache_max_age" yaml:"cache_max_age" env:"CACHE_MAX_AGE"`
+  // Timezone is the IANA time zone timestamps are shown in, e.g. Europe/Paris, see timezone.go.
+  Timezone Location `json:"timezone" yaml:"timezone" env:"TIMEZONE"`
+  // ViewDebounce is how long repeated views of a post by the same client are ignored for, see views.go.
+  ViewDebounce Duration `json:"view_debounce" yaml:"view_debounce" env:"VIEW_DEBOUNCE"`
+  // MaxConcurrentRequests is the number of requests served at once, the others get a 503. 0 lifts the limit.
+  MaxConcurrentRequests int `json:"max_concurrent_requests" yaml:"max_concurrent_requests" env:"MAX_CONCURRENT_REQUESTS"`
+  // MaxBodyBytes is the largest request body accepted, once decompressed, see body.go.
+  MaxBodyBytes int64 `json:"max_body_bytes" yaml:"max_body_bytes" env:"MAX_BODY_BYTES"`
+  // MaxURLLength and MaxHeaderBytes limit the size of the requests, see limits.go.
+  MaxURLLength   int `json:"max_url_length" yaml:"max_url_length" env:"MAX_URL_LENGTH"`
+  MaxHeaderBytes int `json:"max_header_bytes" yaml:"max_header_bytes" env:"MAX_HEADER_BYTES"`
+  // LogLevel is the lowest level of the messages logged: debug, info, warn or error. SlowThreshold is the duration from which requests are logged as slow, see logging.go.
+  LogLevel      slog.Level `json:"log_level" yaml:"log_level" env:"LOG_LEVEL"`
+  SlowThreshold Duration   `json:"slow_threshold" yaml:"slow_threshold" env:"SLOW_THRESHOLD"`
+  // ShutdownTimeout is how long the requests in flight have to finish once the service is asked to stop.
+  ShutdownTimeout Duration `json:"shutdown_timeout" yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+  // ViewFlushInterval is how often the views counted in memory are written to the file. 0 writes every view right away.
+  ViewFlushInterval Duration `json:"view_flush_interval" yaml:"view_flush_interval" env:"VIEW_FLUSH_INTERVAL"`
+  // PreserveDates keeps the CreatedAt sent by the client instead of overwriting it with the current date, as long as it's not further in the future than FutureTolerance.
+  PreserveDates   bool     `json:"preserve_dates" yaml:"preserve_dates" env:"PRESERVE_DATES"`
+  FutureTolerance Duration `json:"future_date_tolerance" yaml:"future_date_tolerance" env:"FUTURE_DATE_TOLERANCE"`
+  // PermalinkBase is what the permalinks of the posts start with, BaseURL + "/posts" when empty, see permalink.go.
+  PermalinkBase string `json:"permalink_base" yaml:"permalink_base" env:"PERMALINK_BASE"`
+  // ImportTimeout and ImportMaxBytes limit the download of POST /posts/import-url, see import.go.
+  ImportTimeout  Duration `json:"import_timeout" yaml:"import_timeout" env:"IMPORT_TIMEOUT"`
+  ImportMaxBytes int64    `json:"import_max_bytes" yaml:"import_max_bytes" env:"IMPORT_MAX_BYTES"`
+  // DefaultImage is the image of the Open Graph metadata of the posts without a FeatureImage, see og.go.
+  DefaultImage string `json:"default_image" yaml:"default_image" env:"DEFAULT_IMAGE"`
+  // BaseURL is the public address of the service, used to build links to the posts.
+  BaseURL string `json:"base_url" yaml:"base_url" env:"BASE_URL"`
+  // FeedTitle and FeedLimit describe the RSS feed, which lists the FeedLimit most recent posts.
+  FeedTitle string `json:"feed_title" yaml:"feed_title" env:"FEED_TITLE"`
+  FeedLimit int    `json:"feed_limit" yaml:"feed_limit" env:"FEED_LIMIT"`
+  // DefaultAuthor is used by create when the post has no Author.
+  DefaultAuthor string `json:"default_author" yaml:"default_author" env:"DEFAULT_AUTHOR"`
+  // RankRecencyWeight is how much recency counts against popularity in index?rank=hybrid, between 0 and 1, see rank.go.
+  RankRecencyWeight float64 `json:"rank_recency_weight" yaml:"rank_recency_weight" env:"RANK_RECENCY_WEIGHT"`
+  // StrictQuery makes the read routes refuse the query parameters they don't know, see query.go.
+  StrictQuery bool `json:"strict_query" yaml:"strict_query" env:"STRICT_QUERY"`
+  // DefaultSort is how index sorts the posts when the query doesn't say, e.g. created_at:desc, see listing.go.
+  DefaultSort string `json:"default_sort" yaml:"default_sort" env:"DEFAULT_SORT"`
+  // CheckImageURLs makes create check that the FeatureImage of a new post can be fetched, see image.go.
+  CheckImageURLs bool `json:"check_image_urls" yaml:"check_image_urls" env:"CHECK_IMAGE_URLS"`
+  // AuthorSuggestions is the number of authors returned by /authors.
+  AuthorSuggestions int `json:"author_suggestions" yaml:"author_suggestions" env:"AUTHOR_SUGGESTIONS"`
+  // FeaturedAuthors are the authors returned by /authors/featured. As an environment variable it's a comma separated list.
+  FeaturedAuthors []string `json:"featured_authors" yaml:"featured_authors" env:"FEATURED_AUTHORS"`
+  // EmptyListMessage makes index respond with {"data": [], "message": EmptyListMessage} instead of [] when no post is listed.
+  EmptyListMessage string `json:"empty_list_message" yaml:"empty_list_message" env:"EMPTY_LIST_MESSAGE"`
+  // ProfanityMode is what create does with a post using one of the ProfanityWords: off, reject or mask, see profanity.go.
+  ProfanityMode  string   `json:"profanity_mode" yaml:"profanity_mode" env:"PROFANITY_MODE"`
+  ProfanityWords []string `json:"profanity_words" yaml:"profanity_words" env:"PROFANITY_WORDS"`
+  // DuplicateCheck is what create does with a post very similar to an existing one: off, warn or reject, see duplicates.go. DuplicateThreshold is the similarity, between 0 and 1, from which posts count as duplicates.
+  DuplicateCheck     string  `json:"duplicate_check" yaml:"duplicate_check" env:"DUPLICATE_CHECK"`
+  DuplicateThreshold float64 `json:"duplicate_threshold" yaml:"duplicate_threshold" env:"DUPLICATE_THRESHOLD"`
+  // CreateCooldown is the minimum time between two posts created from the same IP, see cooldown.go. 0 turns it off.
+  CreateCooldown Duration `json:"create_cooldown" yaml:"create_cooldown" env:"CREATE_COOLDOWN"`
+  // MaxPostsPerAuthor is the number of posts an author can have, 0 lifts the limit.
+  MaxPostsPerAuthor int `json:"max_posts_per_author" yaml:"max_posts_per_author" env:"MAX_POSTS_PER_AUTHOR"`
+  // MinContentLength is the number of characters the Content of a new post must have at least, 0 turns the check off.
+  MinContentLength int `json:"min_content_len" yaml:"min_content_len" env:"MIN_CONTENT_LEN"`
+  // MaxTags and MaxTagLength limit the number of tags of a post and the length of each tag, 0 lifts the limit.
+  MaxTags      int `json:"max_tags" yaml:"max_tags" env:"MAX_TAGS"`
+  MaxTagLength int `json:"max_tag_length" yaml:"max_tag_length" env:"MAX_TAG_LENGTH"`
+  // Locales are the languages posts can be written in, the first one is the primary language posts get when they don't say. As an environment variable it's a comma separated list.
+  Locales []string `json:"locales" yaml:"locales" env:"LOCALES"`
+  // Stopwords are left out of the word frequency counts. As an environment variable it's a comma separated list.
+  Stopwords []string `json:"stopwords" yaml:"stopwords" env:"STOPWORDS"`
+  // Snippets are the texts {{name}} variables in the Content of the posts stand for, see snippets.go. They can only be set in the config file.
+  Snippets map[string]string `json:"snippets" yaml:"snippets"`
+}
+
+func defaultConfig() Config {
+  return Config{
+    Port:               3000,
+    FilePath:           "posts.json",
+    FileMode:           FileMode{0644},
+    TrailingNewline:    true,
+    SettingsFile:       "settings.json",
+    IDStrategy:         sequentialIDs,
+    DuplicateIDs:       duplicateIDsFail,
+    BackupInterval:     Duration{time.Hour},
+    BackupKeep:         24,
+    JSONCase:           pascalCase,
+    BaseURL:            "http://localhost:3000",
+    FeedTitle:          "Posts",
+    FeedLimit:          20,
+    AuthorSuggestions:  10,
+    DefaultSort:        "order",
+    RankRecencyWeight:  0.5,
+    DuplicateCheck:     duplicateWarn,
+    ProfanityMode:      profanityOff,
+    DuplicateThreshold: 0.9,
+    MaxTags:            10,
+    MaxTagLength:       30,
+    Locales:            []string{"en"},
+    Timezone:           Location{time.Local},
+    ViewDebounce:       Duration{30 * time.Minute},
+    ViewFlushInterval:  Duration{10 * time.Second},
+    ShutdownTimeout:    Duration{10 * time.Second},
+    SlowThreshold:      Duration{time.Second},
+    MaxBodyBytes:       1 << 20,
+    ImportTimeout:      Duration{30 * time.Second},
+    ImportMaxBytes:     10 << 20,
+    MaxURLLength:       8192,
+    MaxHeaderBytes:     64 << 10,
+    FutureTolerance:    Duration{5 * time.Minute},
+    Stopwords: []string{
+      "a", "about", "an", "and", "are", "as", "at", "be", "but", "by", "for", "from", "has", "have", "i", "in", "is", "it", "its", "my", "not", "of", "on", "or", "so", "that", "the", "this", "to", "was", "we", "were", "with", "you",
+    },
+  }
+}
+
+// loadConfig builds the config from the defaults, the given file (if any) and the environment.
+func loadConfig(path string) (Config, error) {
+  config := defaultConfig()
+
+  if path != "" {
+    data, err := os.ReadFile(path)
+    if err != nil {
+      return config, fmt.Errorf("reading config file: %w", err)
+    }
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".yaml", ".yml":
+      err = yaml.Unmarshal(data, &config)
+    default:
+      err = json.Unmarshal(data, &config)
+    }
+    if err != nil {
+      return config, fmt.Errorf("parsing config file %s: %w", path, err)
+    }
+  }
+
+  if err := config.applyEnv(os.LookupEnv); err != nil {
+    return config, err
+  }
+  config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+  config.PermalinkBase = strings.TrimRight(config.PermalinkBase, "/")
+  return config, config.validate()
+}
+
+/*
+  applyEnv overrides every field that has an env tag with the matching environment variable, when it's set. lookup is os.LookupEnv, taking it as an argument makes it easy to feed in other values.
+
+  reflect.Value.Addr gives us a pointer to the field, which is what we need to set it and to check whether its type knows how to parse itself from text (like Duration does).
+*/
+func (config *Config) applyEnv(lookup func(string) (string, bool)) error {
+  v := reflect.ValueOf(config).Elem()
+  for i := 0; i < v.NumField(); i++ {
+    name := v.Type().Field(i).Tag.Get("env")
+    value, ok := lookup(name)
+    if name == "" || !ok {
+      continue
+    }
+
+    field := v.Field(i)
+    if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+      if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+        return fmt.Errorf("%s: %w", name, err)
+      }
+      continue
+    }
+
+    switch field.Kind() {
+    case reflect.String:
+      field.SetString(value)
+    case reflect.Bool:
+      // strconv.ParseBool accepts the usual spellings of a boolean ("1", "true", "TRUE", ...). An empty variable counts as false.
+      b := false
+      if value != "" {
+        var err error
+        if b, err = strconv.ParseBool(value); err != nil {
+          return fmt.Errorf("%s must be true or false, got %q", name, value)
+        }
+      }
+      field.SetBool(b)
+    case reflect.Int, reflect.Int64:
+      n, err := strconv.ParseInt(value, 10, 64)
+      if err != nil {
+        return fmt.Errorf("%s must be a number, got %q", name, value)
+      }
+      field.SetInt(n)
+    case reflect.Float64:
+      f, err := strconv.ParseFloat(value, 64)
+      if err != nil {
+        return fmt.Errorf("%s must be a number, got %q", name, value)
+      }
+      field.SetFloat(f)
+    case reflect.Slice:
+      if field.Type().Elem().Kind() != reflect.String {
+        return fmt.Errorf("%s: unsupported config type %s", name, field.Type())
+      }
+      items := []string{}
+      for _, item := range strings.Split(value, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+          items = append(items, item)
+        }
+      }
+      field.Set(reflect.ValueOf(items))
+    default:
+      return fmt.Errorf("%s: unsupported config type %s", name, field.Type())
+    }
+  }
+  return nil
+}
+
+// validate checks the merged config. errors.Join reports every problem at once rather than only the first one.
+func (config *Config) validate() error {
+  var problems []error
+  if config.Port < 1 || config.Port > 65535 {
+    problems = append(problems, fmt.Errorf("port must be between 1 and 65535, got %d", config.Port))
+  }
+  if (config.TLSCert == "") != (config.TLSKey == "") {
+    problems = append(problems, errors.New("tls_cert and tls_key must be set together"))
+  } else if config.TLSCert != "" {
+    // Loading the pair here means a wrong path or a key that doesn't match the certificate stops the service at startup.
+    if _, err := tls.LoadX509KeyPair(config.TLSCert, config.TLSKey); err != nil {
+      problems = append(problems, fmt.Errorf("loading tls_cert and tls_key: %w", err))
+    }
+  }
+  if config.FilePath == "" {
+    problems = append(problems, errors.New("file_path can't be empty"))
+  }
+  if config.MaxViewsPerMinute < 0 {
+    problems = append(problems, fmt.Errorf("max_views_per_minute can't be negative, got %d", config.MaxViewsPerMinute))
+  }
+  // The cache watches the posts file, see cache.go.
+  if config.PostsDir != "" && config.CachePosts {
+    problems = append(problems, errors.New("cache_posts can't be used with posts_dir"))
+  }
+  if config.ViewLog != "" && config.MemoryOnly {
+    problems = append(problems, errors.New("view_log can't be used with memory_only, the posts it would replay the views of are lost anyway"))
+  }
+  if config.SettingsFile == "" && !config.MemoryOnly {
+    problems = append(problems, errors.New("settings_file can't be empty"))
+  }
+  // The service reads the file back, a mode that locks its owner out would only fail later.
+  if config.FileMode.FileMode&0600 != 0600 {
+    problems = append(problems, fmt.Errorf("file_mode must let the owner read and write the file, got %#o", config.FileMode.FileMode))
+  }
+  if config.IDStrategy != sequentialIDs && config.IDStrategy != uuidIDs && config.IDStrategy != ksuidIDs {
+    problems = append(problems, fmt.Errorf("id_strategy must be one of %s, %s or %s, got %q", sequentialIDs, uuidIDs, ksuidIDs, config.IDStrategy))
+  }
+  if config.BackupInterval.Duration <= 0 {
+    problems = append(problems, fmt.Errorf("backup_interval must be positive, got %s", config.BackupInterval))
+  }
+  if config.BackupKeep < 1 {
+    problems = append(problems, fmt.Errorf("backup_keep must be at least 1, got %d", config.BackupKeep))
+  }
+  if config.JSONCase != pascalCase && config.JSONCase != camelCase && config.JSONCase != snakeCase {
+    problems = append(problems, fmt.Errorf("json_case must be one of %s, %s or %s, got %q", pascalCase, camelCase, snakeCase, config.JSONCase))
+  }
+  if config.CacheMaxAge < 0 {
+    problems = append(problems, fmt.Errorf("cache_max_age can't be negative, got %d", config.CacheMaxAge))
+  }
+  if config.ViewDebounce.Duration < 0 {
+    problems = append(problems, fmt.Errorf("view_debounce can't be negative, got %s", config.ViewDebounce))
+  }
+  if config.MaxConcurrentRequests < 0 {
+    problems = append(problems, fmt.Errorf("max_concurrent_requests can't be negative, got %d", config.MaxConcurrentRequests))
+  }
+  if config.ImportTimeout.Duration <= 0 {
+    problems = append(problems, fmt.Errorf("import_timeout must be positive, got %s", config.ImportTimeout))
+  }
+  if config.ImportMaxBytes < 1 {
+    problems = append(problems, fmt.Errorf("import_max_bytes must be at least 1, got %d", config.ImportMaxBytes))
+  }
+  if config.MaxBodyBytes < 1 {
+    problems = append(problems, fmt.Errorf("max_body_bytes must be at least 1, got %d", config.MaxBodyBytes))
+  }
+  if config.MaxURLLength < 1 {
+    problems = append(problems, fmt.Errorf("max_url_length must be at least 1, got %d", config.MaxURLLength))
+  }
+  if config.MaxHeaderBytes < 1 {
+    problems = append(problems, fmt.Errorf("max_header_bytes must be at least 1, got %d", config.MaxHeaderBytes))
+  }
+  if config.ShutdownTimeout.Duration < 0 {
+    problems = append(problems, fmt.Errorf("shutdown_timeout can't be negative, got %s", config.ShutdownTimeout))
+  }
+  if config.CreateCooldown.Duration < 0 {
+    problems = append(problems, fmt.Errorf("create_cooldown can't be negative, got %s", config.CreateCooldown))
+  }
+  if config.SlowThreshold.Duration < 0 {
+    problems = append(problems, fmt.Errorf("slow_threshold can't be negative, got %s", config.SlowThreshold))
+  }
+  if config.ViewFlushInterval.Duration < 0 {
+    problems = append(problems, fmt.Errorf("view_flush_interval can't be negative, got %s", config.ViewFlushInterval))
+  }
+  if config.FutureTolerance.Duration < 0 {
+    problems = append(problems, fmt.Errorf("future_date_tolerance can't be negative, got %s", config.FutureTolerance))
+  }
+  if config.PermalinkBase != "" && !strings.HasPrefix(config.PermalinkBase, "http://") && !strings.HasPrefix(config.PermalinkBase, "https://") {
+    problems = append(problems, fmt.Errorf("permalink_base must be an http(s) URL, got %q", config.PermalinkBase))
+  }
+  if err := validateFeatureImage(config.DefaultImage); err != nil {
+    problems = append(problems, fmt.Errorf("default_image: %w", err))
+  }
+  if !strings.HasPrefix(config.BaseURL, "http://") && !strings.HasPrefix(config.BaseURL, "https://") {
+    problems = append(problems, fmt.Errorf("base_url must be an http(s) URL, got %q", config.BaseURL))
+  }
+  if config.EncryptionKey != "" {
+    if _, err := parseEncryptionKey(config.EncryptionKey); err != nil {
+      problems = append(problems, err)
+    }
+  }
+  if config.ProfanityMode != profanityOff && config.ProfanityMode != profanityReject && config.ProfanityMode != profanityMask {
+    problems = append(problems, fmt.Errorf("profanity_mode must be one of %s, %s or %s, got %q", profanityOff, profanityReject, profanityMask, config.ProfanityMode))
+  }
+  if config.DuplicateIDs != duplicateIDsFail && config.DuplicateIDs != duplicateIDsReassign {
+    problems = append(problems, fmt.Errorf("duplicate_ids must be %s or %s, got %q", duplicateIDsFail, duplicateIDsReassign, config.DuplicateIDs))
+  }
+  if config.DuplicateCheck != duplicateOff && config.DuplicateCheck != duplicateWarn && config.DuplicateCheck != duplicateReject {
+    problems = append(problems, fmt.Errorf("duplicate_check must be one of %s, %s or %s, got %q", duplicateOff, duplicateWarn, duplicateReject, config.DuplicateCheck))
+  }
+  if config.DuplicateThreshold <= 0 || config.DuplicateThreshold > 1 {
+    problems = append(problems, fmt.Errorf("duplicate_threshold must be above 0 and at most 1, got %v", config.DuplicateThreshold))
+  }
+  for name := range config.Snippets {
+    if !snippetName.MatchString(name) {
+      problems = append(problems, fmt.Errorf("snippet name %q can only contain letters, digits, - and _", name))
+    }
+  }
+  if _, err := sortOrder(config.DefaultSort); err != nil {
+    problems = append(problems, fmt.Errorf("default_sort: %w", err))
+  }
+  if config.RankRecencyWeight < 0 || config.RankRecencyWeight > 1 {
+    problems = append(problems, fmt.Errorf("rank_recency_weight must be between 0 and 1, got %v", config.RankRecencyWeight))
+  }
+  if config.MinContentLength < 0 {
+    problems = append(problems, fmt.Errorf("min_content_len can't be negative, got %d", config.MinContentLength))
+  }
+  if config.AuthorSuggestions < 1 {
+    problems = append(problems, fmt.Errorf("author_suggestions must be at least 1, got %d", config.AuthorSuggestions))
+  }
+  if config.MaxPostsPerAuthor < 0 {
+    problems = append(problems, fmt.Errorf("max_posts_per_author can't be negative, got %d", config.MaxPostsPerAuthor))
+  }
+  if config.MaxTags < 0 {
+    problems = append(problems, fmt.Errorf("max_tags can't be negative, got %d", config.MaxTags))
+  }
+  if config.MaxTagLength < 0 {
+    problems = append(problems, fmt.Errorf("max_tag_length can't be negative, got %d", config.MaxTagLength))
+  }
+  if len(config.Locales) == 0 {
+    problems = append(problems, errors.New("locales can't be empty"))
+  }
+  if config.FeedLimit < 1 {
+    problems = append(problems, fmt.Errorf("feed_limit must be at least 1, got %d", config.FeedLimit))
+  }
+  return errors.Join(problems...)
+}
+
+/*
+  Duration wraps time.Duration so it can be written as "30m" or "1h30m" in config files and environment variables. Embedding time.Duration gives Duration all of its methods (String, Seconds, ...) for free.
+
+  Implementing encoding.TextUnmarshaler is enough for encoding/json, yaml and applyEnv to know how to parse it.
+*/
+type Duration struct {
+  time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+  parsed, err := time.ParseDuration(string(text))
+  if err != nil {
+    return fmt.Errorf("invalid duration %q, use a value like 30s or 5m", text)
+  }
+  d.Duration = parsed
+  return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+  return []byte(d.String()), nil
+}
+
+// FileMode wraps os.FileMode the same way, so the permissions can be written in octal, e.g. "0640", rather than as a decimal number.
+type FileMode struct {
+  os.FileMode
+}
+
+func (m *FileMode) UnmarshalText(text []byte) error {
+  parsed, err := strconv.ParseUint(string(text), 8, 32)
+  if err != nil || parsed > 0777 {
+    return fmt.Errorf("invalid file mode %q, use octal permissions like 0644", text)
+  }
+  m.FileMode = os.FileMode(parsed)
+  return nil
+}
+
+func (m FileMode) MarshalText() ([]byte, error) {
+  return []byte(fmt.Sprintf("%#o", m.FileMode)), nil
+}
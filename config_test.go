@@ -0,0 +1,68 @@
+package main
+
+import (
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+  "time"
+)
+
+// writeConfigFile writes a config file with the given name and content in a temporary directory and returns its path.
+func writeConfigFile(t *testing.T, name, content string) string {
+  t.Helper()
+  path := filepath.Join(t.TempDir(), name)
+  if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+    t.Fatal(err)
+  }
+  return path
+}
+
+func TestEnvOverridesConfigFile(t *testing.T) {
+  files := map[string]string{
+    "config.json": `{"port": 4000, "file_path": "from-file.json", "cache_max_age": 60}`,
+    "config.yaml": "port: 4000\nfile_path: from-file.json\ncache_max_age: 60\n",
+  }
+  for name, content := range files {
+    t.Run(name, func(t *testing.T) {
+      t.Setenv("PORT", "5000")
+      t.Setenv("VIEW_DEBOUNCE", "1m")
+
+      loaded, err := loadConfig(writeConfigFile(t, name, content))
+      if err != nil {
+        t.Fatal(err)
+      }
+      if loaded.Port != 5000 {
+        t.Errorf("got port %d, want the 5000 of PORT", loaded.Port)
+      }
+      if loaded.FilePath != "from-file.json" || loaded.CacheMaxAge != 60 {
+        t.Errorf("got file_path %q and cache_max_age %d, want the values of the file", loaded.FilePath, loaded.CacheMaxAge)
+      }
+      if loaded.ViewDebounce.Duration != time.Minute {
+        t.Errorf("got view_debounce %s, want the 1m of VIEW_DEBOUNCE", loaded.ViewDebounce)
+      }
+      if loaded.FeedLimit != defaultConfig().FeedLimit {
+        t.Errorf("got feed_limit %d, want the default", loaded.FeedLimit)
+      }
+    })
+  }
+}
+
+func TestInvalidConfig(t *testing.T) {
+  t.Setenv("PORT", "70000")
+  _, err := loadConfig(writeConfigFile(t, "config.json", `{"cache_max_age": -1}`))
+  if err == nil {
+    t.Fatal("got no error")
+  }
+  // Every problem is reported, not only the first one.
+  for _, want := range []string{"port must be between 1 and 65535", "cache_max_age can't be negative"} {
+    if !strings.Contains(err.Error(), want) {
+      t.Errorf("error %q doesn't say %q", err, want)
+    }
+  }
+
+  t.Setenv("PORT", "abc")
+  if _, err := loadConfig(""); err == nil || !strings.Contains(err.Error(), "PORT must be a number") {
+    t.Errorf("got error %v, want PORT must be a number", err)
+  }
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+  "fmt"
+  "math"
+  "net/http"
+  "strconv"
+  "time"
+)
+
+/*
+  CREATE COOLDOWN
+
+  Scripts posting in a loop are slowed down by CREATE_COOLDOWN, the minimum time between two posts created from the same IP address (0, the default, turns it off). Creating a post during the cooldown is refused with 429 Too Many Requests, and the Retry-After header tells in how many seconds it can be tried again.
+
+  Only posts that were actually created start a cooldown, a request rejected for a typo can be fixed and sent again right away. The debouncer of the views (see views.go) keeps track of the IPs.
+*/
+var createCooldown = &debouncer{seen: map[string]time.Time{}}
+
+// checkCooldown answers with a 429 and reports false when the client is in its cooldown.
+func checkCooldown(w http.ResponseWriter, r *http.Request) bool {
+  wait := createCooldown.retryIn(clientIP(r), time.Now())
+  if wait <= 0 {
+    return true
+  }
+  // Retry-After is in whole seconds, rounding up makes sure the cooldown is over by then.
+  seconds := int(math.Ceil(wait.Seconds()))
+  w.Header().Set("Retry-After", strconv.Itoa(seconds))
+  jsonError(w, http.StatusTooManyRequests, fmt.Sprintf("please wait %ds before creating another post", seconds))
+  return false
+}
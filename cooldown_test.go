@@ -0,0 +1,39 @@
+package main
+
+import (
+  "net/http"
+  "testing"
+  "time"
+)
+
+func TestCreateCooldown(t *testing.T) {
+  setup(t)
+  createCooldown.window = time.Minute
+  createFrom := func(addr, title, author string) *http.Request {
+    r := newJSONRequest(t, http.MethodPost, "/create", Post{Title: title, Content: "Content of " + title, Author: author})
+    r.RemoteAddr = addr
+    return r
+  }
+
+  // A rejected post doesn't start the cooldown.
+  if w := serve("/create", create, createFrom("192.0.2.1:1234", "Typo", "")); w.Code != http.StatusUnprocessableEntity {
+    t.Fatalf("invalid: got status %d, want %d", w.Code, http.StatusUnprocessableEntity)
+  }
+  if w := serve("/create", create, createFrom("192.0.2.1:1234", "First", "Jane Doe")); w.Code != http.StatusCreated {
+    t.Fatalf("first: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+
+  w := serve("/create", create, createFrom("192.0.2.1:5678", "Second", "Jane Doe"))
+  if w.Code != http.StatusTooManyRequests {
+    t.Fatalf("second: got status %d, want %d", w.Code, http.StatusTooManyRequests)
+  }
+  if got := w.Header().Get("Retry-After"); got != "60" {
+    t.Errorf("second: got Retry-After %q, want 60", got)
+  }
+  if w := serve("/create", create, createFrom("192.0.2.2:1234", "Other client", "Jane Doe")); w.Code != http.StatusCreated {
+    t.Errorf("other client: got status %d, want %d", w.Code, http.StatusCreated)
+  }
+  if got := len(storedPosts(t)); got != 2 {
+    t.Errorf("got %d stored posts, want 2", got)
+  }
+}
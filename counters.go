@@ -0,0 +1,20 @@
+package main
+
+import "math"
+
+/*
+  COUNTERS
+
+  ViewCount and Shares are int64 rather than int: int is only 32 bits on 32-bit platforms, where a popular post would reach its 2147483647 limit. encoding/json writes int64 values digit by digit, so they don't lose precision on the way out either.
+
+  Adding to an integer at its maximum wraps around to the most negative value in Go, so the counters go through saturatingAdd and stay at math.MaxInt64 instead.
+*/
+func saturatingAdd(a, b int64) int64 {
+  if b > 0 && a > math.MaxInt64-b {
+    return math.MaxInt64
+  }
+  if b < 0 && a < math.MinInt64-b {
+    return math.MinInt64
+  }
+  return a + b
+}
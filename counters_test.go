@@ -0,0 +1,55 @@
+package main
+
+import (
+  "math"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func TestCountersSaturate(t *testing.T) {
+  posts := testPosts()
+  posts[0].ViewCount = math.MaxInt64 - 1
+  posts[0].Shares = math.MaxInt64
+  setup(t, posts...)
+
+  for _, addr := range []string{"192.0.2.1:1234", "192.0.2.2:1234", "192.0.2.3:1234"} {
+    if got := viewPost(t, "1", addr); got != math.MaxInt64 {
+      t.Errorf("view from %s: got %d views, want %d", addr, got, int64(math.MaxInt64))
+    }
+  }
+  // The buffered views saturate on their way to the file as well.
+  if err := flushViews(t.Context()); err != nil {
+    t.Fatal(err)
+  }
+  if got := storedPosts(t)[0].ViewCount; got != math.MaxInt64 {
+    t.Errorf("got %d stored views, want %d", got, int64(math.MaxInt64))
+  }
+
+  w := serve("POST /posts/{id}/share", share, httptest.NewRequest(http.MethodPost, "/posts/1/share", nil))
+  if !strings.Contains(w.Body.String(), `"shares":9223372036854775807`) {
+    t.Errorf("got %s, want the exact maximum shares", w.Body)
+  }
+  w = serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+  if !strings.Contains(w.Body.String(), `"ViewCount":9223372036854775807`) {
+    t.Errorf("got %s, want the exact maximum views", w.Body)
+  }
+}
+
+func TestSaturatingAdd(t *testing.T) {
+  tests := []struct {
+    a, b, want int64
+  }{
+    {1, 2, 3},
+    {math.MaxInt64, 1, math.MaxInt64},
+    {math.MaxInt64 - 5, 10, math.MaxInt64},
+    {math.MinInt64, -1, math.MinInt64},
+    {math.MaxInt64, -1, math.MaxInt64 - 1},
+  }
+  for _, test := range tests {
+    if got := saturatingAdd(test.a, test.b); got != test.want {
+      t.Errorf("saturatingAdd(%d, %d) = %d, want %d", test.a, test.b, got, test.want)
+    }
+  }
+}
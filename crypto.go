@@ -0,0 +1,127 @@
+package main
+
+import (
+  "crypto/aes"
+  "crypto/cipher"
+  "crypto/rand"
+  "encoding/base64"
+  "encoding/hex"
+  "errors"
+  "fmt"
+  "strings"
+)
+
+/*
+  ENCRYPTION AT REST
+
+  When ENCRYPTION_KEY is set the Content of every post is encrypted with AES-GCM before it's saved and decrypted right after it's loaded, so handlers only ever see plain text. Encrypted values are stored as "enc:v1:" followed by the base64 of the random nonce and the ciphertext.
+
+  Posts saved before the key was set are still read as plain text and get encrypted the next time the posts are saved.
+*/
+const encryptedPrefix = "enc:v1:"
+
+// contentCipher is set by main when an encryption key is configured, nil means content is stored as is.
+var contentCipher cipher.AEAD
+
+// parseEncryptionKey accepts a 16, 24 or 32 byte key (AES-128, AES-192 or AES-256) encoded as hex or base64.
+func parseEncryptionKey(value string) ([]byte, error) {
+  key, err := hex.DecodeString(value)
+  if err != nil {
+    key, err = base64.StdEncoding.DecodeString(value)
+  }
+  if err != nil {
+    return nil, errors.New("encryption key must be hex or base64 encoded")
+  }
+  if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+    return nil, fmt.Errorf("encryption key must be 16, 24 or 32 bytes long, got %d", len(key))
+  }
+  return key, nil
+}
+
+func newContentCipher(value string) (cipher.AEAD, error) {
+  key, err := parseEncryptionKey(value)
+  if err != nil {
+    return nil, err
+  }
+  block, err := aes.NewCipher(key)
+  if err != nil {
+    return nil, err
+  }
+  return cipher.NewGCM(block)
+}
+
+/*
+  encryptPosts returns a copy of the posts with their Content encrypted. Copying matters: the caller keeps using its posts after saving them and must still see the plain text.
+*/
+func encryptPosts(posts []Post) ([]Post, error) {
+  if contentCipher == nil {
+    return posts, nil
+  }
+  encrypted := make([]Post, len(posts))
+  copy(encrypted, posts)
+  for i := range encrypted {
+    post := &encrypted[i]
+    content, err := encryptContent(post.Content)
+    if err != nil {
+      return nil, err
+    }
+    post.Content = content
+
+    // copy only copies the Post structs, the Revisions slices are still shared with posts so they need a copy of their own.
+    post.Revisions = append([]Revision(nil), post.Revisions...)
+    for j := range post.Revisions {
+      if post.Revisions[j].Content, err = encryptContent(post.Revisions[j].Content); err != nil {
+        return nil, err
+      }
+    }
+  }
+  return encrypted, nil
+}
+
+func encryptContent(content string) (string, error) {
+  // A fresh random nonce for every value, GCM is only secure as long as nonces are never reused with the same key.
+  nonce := make([]byte, contentCipher.NonceSize())
+  if _, err := rand.Read(nonce); err != nil {
+    return "", err
+  }
+  sealed := contentCipher.Seal(nonce, nonce, []byte(content), nil)
+  return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptPosts decrypts the Content of the posts, and of their revisions, in place.
+func decryptPosts(posts []Post) error {
+  for i := range posts {
+    post := &posts[i]
+    var err error
+    if post.Content, err = decryptContent(post.ID, post.Content); err != nil {
+      return err
+    }
+    for j := range post.Revisions {
+      if post.Revisions[j].Content, err = decryptContent(post.ID, post.Revisions[j].Content); err != nil {
+        return err
+      }
+    }
+  }
+  return nil
+}
+
+// decryptContent returns the plain text of an encrypted value, values that aren't encrypted are returned as they are. id is only used in error messages.
+func decryptContent(id PostID, content string) (string, error) {
+  value, ok := strings.CutPrefix(content, encryptedPrefix)
+  if !ok {
+    return content, nil
+  }
+  if contentCipher == nil {
+    return "", fmt.Errorf("post %s is encrypted but ENCRYPTION_KEY is not set", id)
+  }
+  sealed, err := base64.StdEncoding.DecodeString(value)
+  if err != nil || len(sealed) < contentCipher.NonceSize() {
+    return "", fmt.Errorf("post %s has malformed encrypted content", id)
+  }
+  nonce, ciphertext := sealed[:contentCipher.NonceSize()], sealed[contentCipher.NonceSize():]
+  plain, err := contentCipher.Open(nil, nonce, ciphertext, nil)
+  if err != nil {
+    return "", fmt.Errorf("post %s can't be decrypted, is ENCRYPTION_KEY the right key?", id)
+  }
+  return string(plain), nil
+}
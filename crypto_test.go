@@ -0,0 +1,69 @@
+package main
+
+import (
+  "context"
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "strings"
+  "testing"
+)
+
+const testEncryptionKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestEncryptionRoundTrip(t *testing.T) {
+  setup(t)
+  path := useFileStore(t, "[]")
+  var err error
+  if contentCipher, err = newContentCipher(testEncryptionKey); err != nil {
+    t.Fatal(err)
+  }
+
+  const secret = "The launch date is the 4th of June."
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "Plans", Content: secret, Author: "Jane Doe"}))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("create: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  if got := decode[Post](t, w).Content; got != secret {
+    t.Errorf("create: got Content %q, want the plain text", got)
+  }
+
+  data, err := os.ReadFile(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if strings.Contains(string(data), "launch") || !strings.Contains(string(data), encryptedPrefix) {
+    t.Errorf("the file holds the plain text: %s", data)
+  }
+
+  w = serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+  if got := decode[Post](t, w).Content; got != secret {
+    t.Errorf("show: got Content %q, want the plain text", got)
+  }
+
+  // Another key can't read the posts.
+  if contentCipher, err = newContentCipher(strings.Repeat("ff", 32)); err != nil {
+    t.Fatal(err)
+  }
+  var posts []Post
+  if err := loadPost(context.Background(), &posts); err == nil {
+    t.Error("wrong key: got no error")
+  }
+}
+
+func TestNoKeyStoresPlainText(t *testing.T) {
+  setup(t)
+  path := useFileStore(t, "[]")
+
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "Plans", Content: "Nothing secret", Author: "Jane Doe"}))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("create: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  data, err := os.ReadFile(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if !strings.Contains(string(data), "Nothing secret") {
+    t.Errorf("the file doesn't hold the plain text: %s", data)
+  }
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+  "net/http"
+  "os"
+  "path/filepath"
+  "sort"
+)
+
+/*
+  DASHBOARD HANDLER
+
+  GET /admin/dashboard sums up the blog for an ops page in a single call:
+
+  {
+    "posts": 12, "drafts": 1, "hidden": 2, "deleted": 1,
+    "total_views": 5230, "total_shares": 41, "comments": 18, "pending_views": 7,
+    "top_posts": [{"id": "3", "title": "...", "views": 2100, "created_at": "..."}],
+    "recent_posts": [...],
+    "storage": {"path": "posts.json", "size": 48213}
+  }
+
+  posts counts the published posts, the ones in the public lists, and the top and recent posts are taken among them, dashboardTop of each. The views include the ones still in the view buffer, pending_views of which aren't written yet (see views.go).
+
+  Nothing is written: the file is read once, like for any other read, and the file size comes from os.Stat. Admins only, see admin.go.
+*/
+const dashboardTop = 5
+
+type dashboardPost struct {
+  ID        PostID `json:"id"`
+  Title     string `json:"title"`
+  Views     int64  `json:"views"`
+  CreatedAt string `json:"created_at"`
+}
+
+type dashboardStorage struct {
+  Path string `json:"path,omitempty"`
+  Size int64  `json:"size"`
+}
+
+type dashboard struct {
+  Posts        int              `json:"posts"`
+  Drafts       int              `json:"drafts"`
+  Hidden       int              `json:"hidden"`
+  Deleted      int              `json:"deleted"`
+  TotalViews   int64            `json:"total_views"`
+  TotalShares  int64            `json:"total_shares"`
+  Comments     int              `json:"comments"`
+  PendingViews int64            `json:"pending_views"`
+  TopPosts     []dashboardPost  `json:"top_posts"`
+  RecentPosts  []dashboardPost  `json:"recent_posts"`
+  Storage      dashboardStorage `json:"storage"`
+}
+
+func adminDashboard(w http.ResponseWriter, r *http.Request) {
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+
+  var summary dashboard
+  for _, post := range posts {
+    switch {
+    case post.Deleted:
+      summary.Deleted++
+    case post.Draft:
+      summary.Drafts++
+    case post.Hidden:
+      summary.Hidden++
+    }
+    summary.TotalViews = saturatingAdd(summary.TotalViews, post.ViewCount)
+    summary.TotalShares = saturatingAdd(summary.TotalShares, post.Shares)
+    summary.Comments += len(post.Comments)
+  }
+  for _, views := range viewBuffer.snapshot() {
+    summary.PendingViews += views.count
+  }
+
+  published := visiblePosts(posts)
+  summary.Posts = len(published)
+  summary.TopPosts = topPosts(published, "views")
+  summary.RecentPosts = topPosts(published, "created_at")
+
+  // A memory-only store has no file, its size is left at 0. A posts directory is as big as its files.
+  switch {
+  case config.MemoryOnly:
+  case config.PostsDir != "":
+    summary.Storage.Path = config.PostsDir
+    paths, _ := filepath.Glob(filepath.Join(config.PostsDir, "*.json"))
+    for _, path := range paths {
+      if info, err := os.Stat(path); err == nil {
+        summary.Storage.Size += info.Size()
+      }
+    }
+  default:
+    summary.Storage.Path = config.FilePath
+    if info, err := os.Stat(config.FilePath); err == nil {
+      summary.Storage.Size = info.Size()
+    }
+  }
+
+  writeJSON(w, http.StatusOK, summary)
+}
+
+// topPosts returns the first dashboardTop posts sorted by field, highest first.
+func topPosts(posts []Post, field string) []dashboardPost {
+  // The fields are fixed, sortOrder can't fail on them.
+  less, _ := sortOrder(field + ":desc")
+  sorted := append([]Post{}, posts...)
+  sort.SliceStable(sorted, func(a, b int) bool { return less(&sorted[a], &sorted[b]) })
+
+  top := []dashboardPost{}
+  for _, post := range sorted[:min(dashboardTop, len(sorted))] {
+    top = append(top, dashboardPost{ID: post.ID, Title: post.Title, Views: post.ViewCount, CreatedAt: displayTimestamp(post.CreatedAt)})
+  }
+  return top
+}
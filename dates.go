@@ -0,0 +1,103 @@
+package main
+
+import (
+  "fmt"
+  "net/http"
+  "os"
+  "path/filepath"
+  "time"
+)
+
+/*
+  DATES
+
+  Posts store their dates as strings. The setters write RFC 3339 timestamps in UTC, but clients may send other layouts and older files have plain dates ("2006-01-02"). Go describes layouts with the reference time Mon Jan 2 15:04:05 MST 2006 rather than with placeholders like %Y-%m-%d.
+*/
+var timestampLayouts = []string{
+  time.RFC3339Nano,
+  time.RFC3339,
+  "2006-01-02T15:04:05",
+  "2006-01-02",
+}
+
+// parseTimestamp tries every known layout and reports whether one of them matched.
+func parseTimestamp(value string) (time.Time, bool) {
+  for _, layout := range timestampLayouts {
+    if t, err := time.Parse(layout, value); err == nil {
+      return t, true
+    }
+  }
+  return time.Time{}, false
+}
+
+/*
+  checkCreatedAt validates a CreatedAt sent by the client when dates are preserved. It must be a date we can parse and it can't be in the future, give or take config.FutureTolerance to make up for clocks that are slightly off.
+*/
+func checkCreatedAt(value string, now time.Time) error {
+  created, ok := parseTimestamp(value)
+  if !ok {
+    return fmt.Errorf("CreatedAt %q is not a valid date", value)
+  }
+  if created.After(now.Add(config.FutureTolerance.Duration)) {
+    return fmt.Errorf("CreatedAt %q is in the future", value)
+  }
+  return nil
+}
+
+/*
+  notModifiedSince reports whether the request has an If-Modified-Since header at or after modified. HTTP dates only have a precision of seconds, so modified is truncated before comparing.
+*/
+func notModifiedSince(r *http.Request, modified time.Time) bool {
+  since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+  if err != nil {
+    return false
+  }
+  return !modified.Truncate(time.Second).After(since)
+}
+
+/*
+  listModified returns when the most recently modified of the listed posts was, see lastModified. An empty list has no posts to go by, it was last modified when the posts were: the modification time of the posts file, or of the newest file of the posts directory. Editing a post file in place doesn't change the modification time of the directory, only the directory itself is looked at when it has no post files. Memory-only posts have none, there's no Last-Modified then.
+*/
+func listModified(posts []Post) (time.Time, bool) {
+  if len(posts) == 0 {
+    if config.MemoryOnly {
+      return time.Time{}, false
+    }
+    if config.PostsDir != "" {
+      return dirModified(config.PostsDir)
+    }
+    info, err := os.Stat(config.FilePath)
+    if err != nil {
+      return time.Time{}, false
+    }
+    return info.ModTime(), true
+  }
+
+  var latest time.Time
+  found := false
+  for i := range posts {
+    if modified, ok := posts[i].lastModified(); ok && (!found || modified.After(latest)) {
+      latest, found = modified, true
+    }
+  }
+  return latest, found
+}
+
+// dirModified returns the modification time of the newest post file of dir, see dirstore.go, or of dir when it has none.
+func dirModified(dir string) (time.Time, bool) {
+  paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+  if err != nil {
+    return time.Time{}, false
+  }
+  if len(paths) == 0 {
+    paths = []string{dir}
+  }
+  var latest time.Time
+  found := false
+  for _, path := range paths {
+    if info, err := os.Stat(path); err == nil && (!found || info.ModTime().After(latest)) {
+      latest, found = info.ModTime(), true
+    }
+  }
+  return latest, found
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+  "net/http"
+  "testing"
+  "time"
+)
+
+func TestCreateRejectsFutureCreatedAt(t *testing.T) {
+  tests := []struct {
+    name      string
+    createdAt time.Time
+    status    int
+  }{
+    {"tomorrow", time.Now().Add(24 * time.Hour), http.StatusUnprocessableEntity},
+    {"past the tolerance", time.Now().Add(10 * time.Minute), http.StatusUnprocessableEntity},
+    {"within the tolerance", time.Now().Add(2 * time.Minute), http.StatusCreated},
+    {"now", time.Now(), http.StatusCreated},
+    {"last year", time.Now().AddDate(-1, 0, 0), http.StatusCreated},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t)
+      config.PreserveDates = true
+      config.FutureTolerance = Duration{5 * time.Minute}
+
+      createdAt := test.createdAt.UTC().Format(time.RFC3339)
+      w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe", CreatedAt: createdAt}))
+      if w.Code != test.status {
+        t.Fatalf("got status %d, want %d: %s", w.Code, test.status, w.Body)
+      }
+      if test.status == http.StatusCreated {
+        if got := storedPosts(t)[0].CreatedAt; got != createdAt {
+          t.Errorf("got CreatedAt %q, want %q", got, createdAt)
+        }
+      } else if got := len(storedPosts(t)); got != 0 {
+        t.Errorf("got %d stored posts, want none", got)
+      }
+    })
+  }
+}
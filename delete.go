@@ -0,0 +1,34 @@
+package main
+
+import (
+  "net/http"
+)
+
+/*
+  DELETE HANDLER
+
+  Deleting a post only marks it as Deleted (a soft delete): it disappears from every route right away but stays in the file, so a mistake can still be undone by hand. POST /admin/compact removes deleted posts for good.
+*/
+func deletePost(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  posts[i].Deleted = true
+  posts[i].setUpdatedAt()
+  if err := savePosts(r.Context(), posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestIncludeDeleted(t *testing.T) {
+  posts := testPosts()
+  posts[1].Deleted = true
+  setup(t, posts...)
+  config.AdminToken = testAdminToken
+
+  w := serve("/index", index, adminRequest(http.MethodGet, "/index?include_deleted=true&sort=file", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("admin: got status %d, want %d", w.Code, http.StatusOK)
+  }
+  got := decode[[]map[string]any](t, w)
+  if len(got) != 3 || got[1]["ID"] != "2" || got[1]["Deleted"] != true {
+    t.Errorf("admin: got %v, want every post with post 2 marked as deleted", got)
+  }
+  if _, ok := got[0]["Deleted"]; ok {
+    t.Errorf("admin: got %v, want no marker on the other posts", got[0])
+  }
+  if got := w.Header().Get("Cache-Control"); got != "private, no-store" {
+    t.Errorf("admin: got Cache-Control %q, want private, no-store", got)
+  }
+
+  for name, r := range map[string]*http.Request{
+    "anonymous":   httptest.NewRequest(http.MethodGet, "/index?include_deleted=true", nil),
+    "wrong token": func() *http.Request {
+      r := httptest.NewRequest(http.MethodGet, "/index?include_deleted=true", nil)
+      r.Header.Set("Authorization", "Bearer nope")
+      return r
+    }(),
+    "admin without the flag": adminRequest(http.MethodGet, "/index", nil),
+  } {
+    w := serve("/index", index, r)
+    for _, post := range decode[[]Post](t, w) {
+      if post.ID == "2" {
+        t.Errorf("%s: got the deleted post", name)
+      }
+    }
+  }
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "net/url"
+  "os"
+  "path/filepath"
+  "sort"
+  "strconv"
+  "strings"
+)
+
+/*
+  DIRSTORE
+
+  DirStore keeps every post in its own file, <id>.json in the POSTS_DIR directory, so that a posts directory under version control gets a diff per post rather than one for the whole posts file. Load reads every .json file of the directory, Save only writes the files of the posts that changed and removes the files of the posts that are gone.
+
+  A directory has no order, the posts are loaded sorted by ID, numerically for the numeric ones. Moving a post (see move.go) has no lasting effect then, its Order still does.
+*/
+type DirStore struct {
+  Dir string
+  // Mode and TrailingNewline are the same as FileStore's.
+  Mode            os.FileMode
+  TrailingNewline bool
+}
+
+func (store *DirStore) Load() ([]Post, error) {
+  paths, err := filepath.Glob(filepath.Join(store.Dir, "*.json"))
+  if err != nil {
+    return nil, err
+  }
+  posts := []Post{}
+  for _, path := range paths {
+    data, err := os.ReadFile(path)
+    if err != nil {
+      return nil, fmt.Errorf("Error reading %s: %w", path, err)
+    }
+    if data, err = decodeText(data); err != nil {
+      return nil, fmt.Errorf("Error reading %s: %w", path, err)
+    }
+    var post Post
+    if err := json.Unmarshal(data, &post); err != nil {
+      return nil, fmt.Errorf("%s is corrupt and won't be overwritten, fix or restore it: %w", path, err)
+    }
+    posts = append(posts, post)
+  }
+  sort.SliceStable(posts, func(i, j int) bool { return idLess(posts[i].ID, posts[j].ID) })
+  return posts, nil
+}
+
+func (store *DirStore) Save(posts []Post) error {
+  if err := os.MkdirAll(store.Dir, 0755); err != nil {
+    return err
+  }
+  kept := map[string]bool{}
+  for _, post := range posts {
+    data, err := json.MarshalIndent(post, "", "  ")
+    if err != nil {
+      return err
+    }
+    if store.TrailingNewline {
+      data = append(data, '\n')
+    }
+    path := store.path(post.ID)
+    kept[path] = true
+    // Files that didn't change are left alone, they keep their modification time.
+    if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+      continue
+    }
+    if err := os.WriteFile(path, data, store.Mode); err != nil {
+      return err
+    }
+    if err := os.Chmod(path, store.Mode); err != nil {
+      return err
+    }
+  }
+
+  paths, err := filepath.Glob(filepath.Join(store.Dir, "*.json"))
+  if err != nil {
+    return err
+  }
+  for _, path := range paths {
+    if !kept[path] {
+      if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+        return err
+      }
+    }
+  }
+  return nil
+}
+
+// path is the file of the post with the given ID. IDs are escaped, a "/" in one can't point outside of the directory.
+func (store *DirStore) path(id PostID) string {
+  return filepath.Join(store.Dir, url.PathEscape(string(id))+".json")
+}
+
+// idLess sorts numeric IDs as numbers, before the other IDs, which are sorted as strings.
+func idLess(a, b PostID) bool {
+  na, errA := strconv.Atoi(string(a))
+  nb, errB := strconv.Atoi(string(b))
+  switch {
+  case errA == nil && errB == nil:
+    return na < nb
+  case errA == nil || errB == nil:
+    return errA == nil
+  }
+  return strings.Compare(string(a), string(b)) < 0
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "path/filepath"
+  "slices"
+  "testing"
+  "time"
+)
+
+func TestDirStore(t *testing.T) {
+  setup(t)
+  dir := filepath.Join(t.TempDir(), "posts")
+  config.MemoryOnly = false
+  config.PostsDir = dir
+  config.AdminToken = testAdminToken
+  storage = newStore(config)
+  if _, ok := storage.(*DirStore); !ok {
+    t.Fatalf("got a %T, want a DirStore", storage)
+  }
+
+  for _, title := range []string{"First", "Second"} {
+    w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: title, Content: "Content of " + title, Author: "Jane Doe"}))
+    if w.Code != http.StatusCreated {
+      t.Fatalf("create: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+    }
+  }
+  files := func() []string {
+    names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+    if err != nil {
+      t.Fatal(err)
+    }
+    for i := range names {
+      names[i] = filepath.Base(names[i])
+    }
+    return names
+  }
+  if got := files(); !slices.Equal(got, []string{"1.json", "2.json"}) {
+    t.Fatalf("got files %v, want 1.json and 2.json", got)
+  }
+  if got := listedIDs(t, "?sort=title"); !slices.Equal(got, []PostID{"1", "2"}) {
+    t.Errorf("index: got %v, want [1 2]", got)
+  }
+
+  // Changing a post leaves the file of the other one alone.
+  old := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+  first := filepath.Join(dir, "1.json")
+  if err := os.Chtimes(first, old, old); err != nil {
+    t.Fatal(err)
+  }
+  if w := serve("DELETE /posts/{id}", deletePost, httptest.NewRequest(http.MethodDelete, "/posts/2", nil)); w.Code != http.StatusNoContent {
+    t.Fatalf("delete: got status %d, want %d", w.Code, http.StatusNoContent)
+  }
+  if got := listedIDs(t, ""); !slices.Equal(got, []PostID{"1"}) {
+    t.Errorf("after the delete: got %v, want [1]", got)
+  }
+  if info, err := os.Stat(first); err != nil || !info.ModTime().Equal(old) {
+    t.Errorf("1.json was rewritten")
+  }
+
+  // Purging the deleted post removes its file.
+  if w := serve("POST /admin/compact", requireAdmin(compact), adminRequest(http.MethodPost, "/admin/compact", nil)); w.Code != http.StatusOK {
+    t.Fatalf("compact: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if got := files(); !slices.Equal(got, []string{"1.json"}) {
+    t.Errorf("after compacting: got files %v, want 1.json", got)
+  }
+}
+
+func TestDirStoreSortsNumericIDs(t *testing.T) {
+  store := &DirStore{Dir: t.TempDir(), Mode: 0644}
+  if err := store.Save([]Post{{ID: "10"}, {ID: "abc"}, {ID: "9"}, {ID: "a/b"}}); err != nil {
+    t.Fatal(err)
+  }
+  posts, err := store.Load()
+  if err != nil {
+    t.Fatal(err)
+  }
+  got := []PostID{}
+  for _, post := range posts {
+    got = append(got, post.ID)
+  }
+  if want := []PostID{"9", "10", "a/b", "abc"}; !slices.Equal(got, want) {
+    t.Errorf("got %v, want %v", got, want)
+  }
+}
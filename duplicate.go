@@ -0,0 +1,66 @@
+package main
+
+import (
+  "net/http"
+)
+
+/*
+  DUPLICATE HANDLER
+
+  POST /posts/{id}/duplicate starts a new post from a copy of an existing one. The copy gets a new ID and "(copy)" at the end of its Title, it starts with no views, shares, comments, history or Order, and it's saved as a Draft so it doesn't show up anywhere until it's published.
+
+  The copy is a new post like any other: it counts towards MAX_POSTS_PER_AUTHOR, it waits for and starts the CREATE_COOLDOWN of the client, and it's logged as a create in the VIEW_LOG, see create.
+
+  The path follows the other actions on a post (/posts/{id}/move, /posts/{id}/order...). /posts/duplicate/{id} would be ambiguous with those: the ServeMux couldn't tell which route /posts/duplicate/move is meant for, and refuses to register both.
+*/
+func duplicate(w http.ResponseWriter, r *http.Request) {
+  if !checkCooldown(w, r) {
+    return
+  }
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  if !checkPostLimit(w, posts, posts[i].Author) {
+    return
+  }
+
+  // Assigning a struct copies it, but the slices inside still point to the same arrays, so the copy gets its own Tags.
+  clone := posts[i]
+  clone.Tags = append([]string(nil), clone.Tags...)
+  clone.Title += " (copy)"
+  clone.Draft = true
+  clone.Hidden = false
+  clone.Order = 0
+  clone.Revisions = nil
+  clone.overlay = nil
+  clone.ViewCount = 0
+  clone.Shares = 0
+  clone.Comments, clone.PinnedCommentID = nil, 0
+  clone.OriginalCreatedAt = ""
+  clone.setCreatedAt()
+  clone.setUpdatedAt()
+  clone.setLastViewed()
+
+  var err error
+  clone.ID, err = ids.NewID(posts)
+  if err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error generating post ID")
+    return
+  }
+  clone.Slug = uniqueSlug(posts, slugify(clone.Title), clone.ID)
+  if !saveNewPost(w, r, posts, clone) {
+    return
+  }
+
+  writeJSON(w, http.StatusCreated, clone)
+}
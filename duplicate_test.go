@@ -0,0 +1,102 @@
+package main
+
+import (
+  "context"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestDuplicate(t *testing.T) {
+  posts := testPosts()
+  posts[0].ViewCount = 42
+  posts[0].Shares = 3
+  posts[0].LastViewed = "2025-01-05T10:00:00Z"
+  posts[0].Tags = []string{"go"}
+  setup(t, posts...)
+
+  w := serve("POST /posts/{id}/duplicate", duplicate, httptest.NewRequest(http.MethodPost, "/posts/1/duplicate", nil))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  clone := decode[Post](t, w)
+  if clone.ID != "4" || clone.Title != "First post (copy)" || clone.Content != posts[0].Content || !clone.Draft {
+    t.Errorf("got %+v, want a draft copy of post 1 as post 4", clone)
+  }
+  if clone.ViewCount != 0 || clone.Shares != 0 {
+    t.Errorf("got %d views and %d shares, want none", clone.ViewCount, clone.Shares)
+  }
+  if clone.CreatedAt == posts[0].CreatedAt || clone.LastViewed == posts[0].LastViewed {
+    t.Errorf("got CreatedAt %q and LastViewed %q, want the time of the copy", clone.CreatedAt, clone.LastViewed)
+  }
+
+  stored := storedPosts(t)
+  if len(stored) != 4 || stored[0].ViewCount != 42 || stored[0].Title != "First post" {
+    t.Errorf("got %d stored posts with post 1 %+v, want the source untouched and the copy added", len(stored), stored[0])
+  }
+  // Drafts aren't listed.
+  for _, id := range listedIDs(t, "") {
+    if id == "4" {
+      t.Error("the copy is listed")
+    }
+  }
+
+  if w := serve("POST /posts/{id}/duplicate", duplicate, httptest.NewRequest(http.MethodPost, "/posts/99/duplicate", nil)); w.Code != http.StatusNotFound {
+    t.Errorf("missing post: got status %d, want %d", w.Code, http.StatusNotFound)
+  }
+}
+
+func TestDuplicateIsCheckedLikeACreate(t *testing.T) {
+  setup(t, testPosts()...)
+  duplicateFrom := func(addr string) *httptest.ResponseRecorder {
+    r := httptest.NewRequest(http.MethodPost, "/posts/2/duplicate", nil)
+    r.RemoteAddr = addr
+    return serve("POST /posts/{id}/duplicate", duplicate, r)
+  }
+
+  // John Smith has one post, a copy of it reaches MAX_POSTS_PER_AUTHOR.
+  config.MaxPostsPerAuthor = 2
+  if w := duplicateFrom("192.0.2.1:1234"); w.Code != http.StatusCreated {
+    t.Fatalf("first copy: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  if w := duplicateFrom("192.0.2.2:1234"); w.Code != http.StatusForbidden {
+    t.Errorf("over the limit: got status %d, want %d", w.Code, http.StatusForbidden)
+  }
+  config.MaxPostsPerAuthor = 0
+
+  createCooldown.window = time.Minute
+  if w := duplicateFrom("192.0.2.3:1234"); w.Code != http.StatusCreated {
+    t.Fatalf("before the cooldown: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  w := duplicateFrom("192.0.2.3:5678")
+  if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+    t.Errorf("during the cooldown: got status %d and Retry-After %q, want %d and 60", w.Code, w.Header().Get("Retry-After"), http.StatusTooManyRequests)
+  }
+  if got := len(storedPosts(t)); got != 5 {
+    t.Errorf("got %d stored posts, want 5", got)
+  }
+}
+
+func TestDuplicateIsLogged(t *testing.T) {
+  setup(t, testPosts()...)
+  path := useViewLog(t)
+  stored := storage
+  storage = crashingStore{stored}
+  crashWhile(t, "POST /posts/{id}/duplicate", lockPosts(duplicate), httptest.NewRequest(http.MethodPost, "/posts/1/duplicate", nil))
+
+  // After a restart the copy is replayed from the log.
+  viewBuffer.log.file.Close()
+  viewBuffer = &viewBufferMap{pending: map[PostID]bufferedViews{}}
+  storage = stored
+  if _, writes, err := viewBuffer.useLog(path); err != nil || writes != 1 {
+    t.Fatalf("got %d writes replayed and error %v, want 1", writes, err)
+  }
+  if err := flushViews(context.Background()); err != nil {
+    t.Fatal(err)
+  }
+  posts := storedPosts(t)
+  if len(posts) != 4 || posts[3].Title != "First post (copy)" || !posts[3].Draft {
+    t.Errorf("got stored posts %+v, want the copy back", posts)
+  }
+}
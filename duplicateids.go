@@ -0,0 +1,111 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "log"
+  "net/http"
+  "strings"
+)
+
+/*
+  DUPLICATE IDS
+
+  Editing the posts file by hand can leave two posts with the same ID, and then there's no telling which one /posts/{id} is about. DUPLICATE_IDS decides what happens when the posts are checked, at startup and on POST /admin/reindex:
+
+  fail      the service refuses to start and reindex answers 409, until the file is fixed, the default
+  reassign  the first post keeps the ID and the others get new ones from the ID_STRATEGY generator, saved right away
+
+  Between two checks show and patch answer 409 for an ID used more than once rather than picking one of the posts.
+*/
+const (
+  duplicateIDsFail     = "fail"
+  duplicateIDsReassign = "reassign"
+)
+
+var errSharedIDs = errors.New("more than one post has the ID")
+
+type reassignedID struct {
+  From  PostID `json:"from"`
+  To    PostID `json:"to"`
+  Title string `json:"title"`
+}
+
+// sharedIDs returns the IDs used by more than one post, deleted posts included, in the order of the posts.
+func sharedIDs(posts []Post) []PostID {
+  seen := map[PostID]int{}
+  shared := []PostID{}
+  for _, post := range posts {
+    seen[post.ID]++
+    if seen[post.ID] == 2 && post.ID != "" {
+      shared = append(shared, post.ID)
+    }
+  }
+  return shared
+}
+
+// countID is the number of posts with the ID.
+func countID(posts []Post, id PostID) int {
+  n := 0
+  for _, post := range posts {
+    if post.ID == id {
+      n++
+    }
+  }
+  return n
+}
+
+// checkUniqueID answers 409 and returns false when more than one post has the ID.
+func checkUniqueID(w http.ResponseWriter, posts []Post, id PostID) bool {
+  if n := countID(posts, id); n > 1 {
+    jsonError(w, http.StatusConflict, fmt.Sprintf("%d posts have the ID %q, fix the posts file or reindex with DUPLICATE_IDS=reassign", n, id))
+    return false
+  }
+  return true
+}
+
+// resolveDuplicateIDs checks the posts for shared IDs and, depending on DUPLICATE_IDS, reassigns them or returns an error naming them.
+func resolveDuplicateIDs(ctx context.Context) ([]reassignedID, error) {
+  postsMu.Lock()
+  defer postsMu.Unlock()
+  var posts []Post
+  if err := loadPost(ctx, &posts); err != nil {
+    return nil, err
+  }
+  shared := sharedIDs(posts)
+  if len(shared) == 0 {
+    return nil, nil
+  }
+  if config.DuplicateIDs != duplicateIDsReassign {
+    names := make([]string, len(shared))
+    for i, id := range shared {
+      names[i] = fmt.Sprintf("%q", id)
+    }
+    return nil, fmt.Errorf("%w %s, fix the posts file or set DUPLICATE_IDS=reassign", errSharedIDs, strings.Join(names, ", "))
+  }
+
+  reassigned := []reassignedID{}
+  kept := map[PostID]bool{}
+  for i := range posts {
+    post := &posts[i]
+    if !kept[post.ID] {
+      kept[post.ID] = true
+      continue
+    }
+    id, err := ids.NewID(posts)
+    if err != nil {
+      return nil, fmt.Errorf("Error generating post ID: %w", err)
+    }
+    reassigned = append(reassigned, reassignedID{From: post.ID, To: id, Title: post.Title})
+    post.ID = id
+    kept[id] = true
+  }
+  if err := savePosts(ctx, posts); err != nil {
+    return nil, err
+  }
+  for _, r := range reassigned {
+    log.Printf("Post %q had the ID %s of another post, it's now %s", r.Title, r.From, r.To)
+  }
+  return reassigned, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "os/exec"
+  "path/filepath"
+  "slices"
+  "strings"
+  "testing"
+)
+
+// sharedIDPosts are the test posts with a fourth one reusing the ID of the second, as a hand edit could.
+func sharedIDPosts() []Post {
+  return append(testPosts(), Post{ID: "2", Title: "Copy of the second post", Content: "Pasted by hand.", Author: "John Smith", CreatedAt: "2025-01-04T10:00:00Z"})
+}
+
+func TestSharedIDsAreAConflict(t *testing.T) {
+  setup(t, sharedIDPosts()...)
+
+  if w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/2", nil)); w.Code != http.StatusConflict {
+    t.Errorf("show: got status %d, want %d", w.Code, http.StatusConflict)
+  }
+  r := httptest.NewRequest(http.MethodPatch, "/posts/2", strings.NewReader(`[{"op": "replace", "path": "/Title", "value": "Which one?"}]`))
+  r.Header.Set("Content-Type", "application/json-patch+json")
+  w := serve("PATCH /posts/{id}", patchPost, r)
+  if w.Code != http.StatusConflict {
+    t.Errorf("patch: got status %d, want %d", w.Code, http.StatusConflict)
+  }
+  if got := decode[map[string]string](t, w)["error"]; !strings.HasPrefix(got, `2 posts have the ID "2"`) {
+    t.Errorf("patch: got error %q", got)
+  }
+  // The other posts are fine.
+  if w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/1", nil)); w.Code != http.StatusOK {
+    t.Errorf("other post: got status %d, want %d", w.Code, http.StatusOK)
+  }
+  if got := storedPosts(t); got[1].Title != "Second post" || got[3].Title != "Copy of the second post" {
+    t.Errorf("the posts were modified: %+v", got)
+  }
+}
+
+func TestReindexDetectsSharedIDs(t *testing.T) {
+  setup(t, sharedIDPosts()...)
+  config.AdminToken = testAdminToken
+
+  w := serve("POST /admin/reindex", requireAdmin(reindex), adminRequest(http.MethodPost, "/admin/reindex", nil))
+  if w.Code != http.StatusConflict {
+    t.Fatalf("fail: got status %d, want %d", w.Code, http.StatusConflict)
+  }
+  if got := decode[map[string]string](t, w)["error"]; !strings.Contains(got, `the ID "2"`) {
+    t.Errorf("fail: got error %q, want the shared ID", got)
+  }
+
+  config.DuplicateIDs = duplicateIDsReassign
+  w = serve("POST /admin/reindex", requireAdmin(reindex), adminRequest(http.MethodPost, "/admin/reindex", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("reassign: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  want := []reassignedID{{From: "2", To: "4", Title: "Copy of the second post"}}
+  if got := decode[reindexSummary](t, w).ReassignedIDs; !slices.Equal(got, want) {
+    t.Errorf("reassign: got %+v, want %+v", got, want)
+  }
+  // The first post keeps the ID.
+  got := []PostID{}
+  for _, post := range storedPosts(t) {
+    got = append(got, post.ID)
+  }
+  if !slices.Equal(got, []PostID{"1", "2", "3", "4"}) {
+    t.Errorf("got IDs %v, want [1 2 3 4]", got)
+  }
+}
+
+func TestUnreadablePostsAreNotSharedIDs(t *testing.T) {
+  setup(t)
+  useFileStore(t, `[{"ID": "1", "Title": "First post", "Cont`)
+  if _, err := resolveDuplicateIDs(context.Background()); err == nil || errors.Is(err, errSharedIDs) {
+    t.Errorf("got error %v, want a load error", err)
+  }
+}
+
+// TestSharedIDsStopTheService runs the service in a copy of the test binary, like TestStrictStartupFails.
+func TestSharedIDsStopTheService(t *testing.T) {
+  if os.Getenv("RUN_MAIN") == "1" {
+    main()
+    return
+  }
+  dir := t.TempDir()
+  path := filepath.Join(dir, "posts.json")
+  data := `[{"ID": "1", "Title": "First post", "Content": "First", "Author": "Jane Doe"}, {"ID": "1", "Title": "Copy", "Content": "Copy", "Author": "Jane Doe"}]`
+  if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+    t.Fatal(err)
+  }
+
+  cmd := exec.Command(os.Args[0], "-test.run=^TestSharedIDsStopTheService$")
+  cmd.Dir = dir
+  cmd.Env = append(os.Environ(), "RUN_MAIN=1", "POSTS_FILE="+path)
+  output, err := cmd.CombinedOutput()
+  if _, ok := err.(*exec.ExitError); !ok {
+    t.Fatalf("got error %v, want the service to exit with an error: %s", err, output)
+  }
+  if !strings.Contains(string(output), `more than one post has the ID "1"`) {
+    t.Errorf("got output %s, want the shared ID", output)
+  }
+}
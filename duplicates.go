@@ -0,0 +1,88 @@
+package main
+
+import (
+  "strings"
+)
+
+/*
+  DUPLICATE DETECTION
+
+  Before saving a new post, create compares it with the existing ones. Two posts count as duplicates when they have the same Content (ignoring case and whitespace), or when their Titles are at least DUPLICATE_THRESHOLD similar (0.9 by default). DUPLICATE_CHECK decides what happens then:
+
+  off     nothing, the post is saved
+  warn    the post is saved and the response says which post it looks like in a "warning" field
+  reject  the post isn't saved, create answers 409 Conflict
+*/
+const (
+  duplicateOff    = "off"
+  duplicateWarn   = "warn"
+  duplicateReject = "reject"
+)
+
+// createdPost is the response of create.
+type createdPost struct {
+  Post
+  Warning string `json:"warning,omitempty"`
+}
+
+// findDuplicate returns the existing post most similar to post and their similarity, when it's above the threshold.
+func findDuplicate(posts []Post, post Post) (Post, float64, bool) {
+  content := normalizeText(post.Content)
+  title := normalizeText(post.Title)
+
+  var best Post
+  bestScore := 0.0
+  for _, existing := range posts {
+    if existing.Deleted {
+      continue
+    }
+    score := similarity(title, normalizeText(existing.Title))
+    if normalizeText(existing.Content) == content {
+      score = 1
+    }
+    if score > bestScore {
+      best, bestScore = existing, score
+    }
+  }
+  return best, bestScore, bestScore >= config.DuplicateThreshold
+}
+
+// normalizeText lowercases s and collapses its whitespace, so "Hello  World" and "hello world" compare equal.
+func normalizeText(s string) string {
+  return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// similarity is 1 minus the Levenshtein distance between a and b divided by the length of the longest, 1 for equal strings and 0 for completely different ones.
+func similarity(a, b string) float64 {
+  ra, rb := []rune(a), []rune(b)
+  longest := max(len(ra), len(rb))
+  if longest == 0 {
+    return 1
+  }
+  return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+/*
+  levenshtein returns the number of single character insertions, deletions and substitutions needed to turn a into b.
+
+  It fills in the classic dynamic programming table one row at a time: previous[j] is the distance between the first i-1 runes of a and the first j runes of b, and current is the row being computed for the first i runes of a.
+*/
+func levenshtein(a, b []rune) int {
+  previous := make([]int, len(b)+1)
+  current := make([]int, len(b)+1)
+  for j := range previous {
+    previous[j] = j
+  }
+  for i := 1; i <= len(a); i++ {
+    current[0] = i
+    for j := 1; j <= len(b); j++ {
+      cost := 1
+      if a[i-1] == b[j-1] {
+        cost = 0
+      }
+      current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+    }
+    previous, current = current, previous
+  }
+  return previous[len(b)]
+}
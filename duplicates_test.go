@@ -0,0 +1,39 @@
+package main
+
+import (
+  "net/http"
+  "strings"
+  "testing"
+)
+
+func TestCreateNearDuplicate(t *testing.T) {
+  nearDuplicates := []Post{
+    {Title: "First posts", Content: "Something else entirely.", Author: "Jane Doe"},
+    {Title: "Another title", Content: "the content of  the FIRST post.", Author: "Jane Doe"},
+  }
+  for _, post := range nearDuplicates {
+    setup(t, testPosts()...)
+    w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", post))
+    if w.Code != http.StatusCreated {
+      t.Fatalf("%s, warn: got status %d, want %d: %s", post.Title, w.Code, http.StatusCreated, w.Body)
+    }
+    if got := decode[createdPost](t, w).Warning; !strings.HasPrefix(got, `very similar to post 1 "First post"`) {
+      t.Errorf("%s, warn: got warning %q, want post 1", post.Title, got)
+    }
+
+    setup(t, testPosts()...)
+    config.DuplicateCheck = duplicateReject
+    if w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", post)); w.Code != http.StatusConflict {
+      t.Errorf("%s, reject: got status %d, want %d", post.Title, w.Code, http.StatusConflict)
+    }
+    if got := len(storedPosts(t)); got != 3 {
+      t.Errorf("%s, reject: got %d stored posts, want 3", post.Title, got)
+    }
+  }
+
+  setup(t, testPosts()...)
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "Something new", Content: "Never written before.", Author: "Jane Doe"}))
+  if got := decode[map[string]any](t, w)["warning"]; got != nil {
+    t.Errorf("new post: got warning %v, want none", got)
+  }
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+  "bytes"
+  "encoding/binary"
+  "errors"
+  "unicode/utf16"
+  "unicode/utf8"
+)
+
+/*
+  TEXT ENCODINGS
+
+  Go strings, and encoding/json, expect UTF-8. Files written by Windows tools often start with a byte order mark (BOM) though, and some are even UTF-16, which makes json.Unmarshal fail with "invalid character 'ï' looking for beginning of value".
+
+  decodeText drops a leading UTF-8 BOM and converts UTF-16 (little or big endian, told apart by their BOM) to UTF-8. Anything without a BOM is returned untouched.
+*/
+var (
+  utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+  utf16LEBOM = []byte{0xFF, 0xFE}
+  utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+func decodeText(data []byte) ([]byte, error) {
+  switch {
+  case bytes.HasPrefix(data, utf8BOM):
+    return data[len(utf8BOM):], nil
+  case bytes.HasPrefix(data, utf16LEBOM):
+    return decodeUTF16(data[len(utf16LEBOM):], binary.LittleEndian)
+  case bytes.HasPrefix(data, utf16BEBOM):
+    return decodeUTF16(data[len(utf16BEBOM):], binary.BigEndian)
+  }
+  return data, nil
+}
+
+// decodeUTF16 converts UTF-16 code units, 2 bytes each, to UTF-8.
+func decodeUTF16(data []byte, order binary.ByteOrder) ([]byte, error) {
+  if len(data)%2 != 0 {
+    return nil, errors.New("invalid UTF-16 text, odd number of bytes")
+  }
+  units := make([]uint16, len(data)/2)
+  for i := range units {
+    units[i] = order.Uint16(data[2*i:])
+  }
+
+  decoded := make([]byte, 0, len(data))
+  for _, r := range utf16.Decode(units) {
+    decoded = utf8.AppendRune(decoded, r)
+  }
+  return decoded, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+  "bytes"
+  "encoding/binary"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "unicode/utf16"
+)
+
+// encodeUTF16 encodes s as UTF-16 in the given byte order, after its BOM.
+func encodeUTF16(s string, order binary.AppendByteOrder, bom []byte) []byte {
+  data := append([]byte(nil), bom...)
+  for _, unit := range utf16.Encode([]rune(s)) {
+    data = order.AppendUint16(data, unit)
+  }
+  return data
+}
+
+func TestLoadBOMPrefixedFile(t *testing.T) {
+  setup(t)
+  useFileStore(t, string(utf8BOM)+`[{"ID": "1", "Title": "Café", "Content": "Exported from Windows", "Author": "Jane Doe", "CreatedAt": "2025-01-01T10:00:00Z"}]`)
+
+  w := listPosts("")
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if got := decode[[]Post](t, w); len(got) != 1 || got[0].Title != "Café" {
+    t.Errorf("got %+v, want the post of the file", got)
+  }
+}
+
+func TestCreateUTF16Body(t *testing.T) {
+  const body = `{"Title": "Été", "Content": "Written in UTF-16", "Author": "Jane Doe"}`
+  for name, data := range map[string][]byte{
+    "UTF-8 BOM":     append(append([]byte(nil), utf8BOM...), body...),
+    "UTF-16 LE BOM": encodeUTF16(body, binary.LittleEndian, utf16LEBOM),
+    "UTF-16 BE BOM": encodeUTF16(body, binary.BigEndian, utf16BEBOM),
+  } {
+    setup(t)
+    r := httptest.NewRequest(http.MethodPost, "/create", bytes.NewReader(data))
+    r.Header.Set("Content-Type", "application/json")
+    w := serve("/create", create, r)
+    if w.Code != http.StatusCreated {
+      t.Errorf("%s: got status %d, want %d: %s", name, w.Code, http.StatusCreated, w.Body)
+      continue
+    }
+    if got := storedPosts(t)[0].Title; got != "Été" {
+      t.Errorf("%s: got Title %q, want Été", name, got)
+    }
+  }
+
+  if _, err := decodeText(append(append([]byte(nil), utf16LEBOM...), 'a')); err == nil {
+    t.Error("odd number of UTF-16 bytes: got no error")
+  }
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+  "bytes"
+  "compress/gzip"
+  "crypto/sha256"
+  "encoding/csv"
+  "encoding/hex"
+  "net/http"
+  "strconv"
+  "strings"
+  "time"
+)
+
+/*
+  EXPORT
+
+  GET /export.json and GET /export.csv download every public post at once. The export is built in memory and served with http.ServeContent, which takes care of Range requests: a download manager can ask for "Range: bytes=1000-" and get the rest of the file back as a 206 Partial Content.
+
+  The ETag is a hash of the export, a client resuming a download with If-Range only gets the missing part when the export hasn't changed in the meantime, and the whole file otherwise.
+*/
+func exportJSON(w http.ResponseWriter, r *http.Request) {
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  posts = visiblePosts(posts)
+
+  var buf bytes.Buffer
+  if err := encodeJSON(&buf, posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error encoding posts")
+    return
+  }
+  w.Header().Set("Content-Type", "application/json")
+  serveExport(w, r, "posts.json", posts, buf.Bytes())
+}
+
+// csvHeader lists the columns of the CSV export. Tags are joined with "|" in a single column.
+var csvHeader = []string{"ID", "Title", "Content", "CreatedAt", "Author", "ViewCount", "LastViewed", "UpdatedAt", "Tags"}
+
+func exportCSV(w http.ResponseWriter, r *http.Request) {
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  posts = visiblePosts(posts)
+
+  // csv.Writer quotes the values that need it, e.g. a Content with commas or line breaks.
+  var buf bytes.Buffer
+  writer := csv.NewWriter(&buf)
+  writer.Write(csvHeader)
+  for _, post := range posts {
+    post = post.inDisplayZone()
+    writer.Write([]string{
+      string(post.ID), post.Title, post.Content, post.CreatedAt, post.Author,
+      strconv.FormatInt(post.ViewCount, 10), post.LastViewed, post.UpdatedAt, strings.Join(post.Tags, "|"),
+    })
+  }
+  writer.Flush()
+  if err := writer.Error(); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error encoding posts")
+    return
+  }
+  w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+  serveExport(w, r, "posts.csv", posts, buf.Bytes())
+}
+
+// serveExport sends the export as a download named name.
+func serveExport(w http.ResponseWriter, r *http.Request, name string, posts []Post, export []byte) {
+  sum := sha256.Sum256(export)
+  w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+  w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+
+  // The export is as recent as the most recently modified post.
+  var modified time.Time
+  for _, post := range posts {
+    if t, ok := post.lastModified(); ok && t.After(modified) {
+      modified = t
+    }
+  }
+  // bytes.Reader lets ServeContent seek to the requested range.
+  http.ServeContent(w, r, name, modified, bytes.NewReader(export))
+}
+
+/*
+  GZIPPED EXPORT
+
+  GET /posts/export.json.gz is the JSON export compressed with gzip, a single small file to keep as a backup. Admins get every post, hidden, draft and deleted ones included, so that nothing is missing from the backup.
+
+  The JSON is written to the gzip writer post by post, which compresses it and sends it to the client as it goes, rather than building the whole export in memory first. The response can't be resumed with a Range request like the other exports since its size isn't known upfront.
+
+  The response says Content-Encoding: gzip, so browsers and curl --compressed decompress it. Plain curl keeps it compressed:
+
+  curl -o posts.json.gz http://localhost:3000/posts/export.json.gz
+*/
+func exportJSONGzip(w http.ResponseWriter, r *http.Request) {
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  if isAdmin(r) {
+    w.Header().Set("Cache-Control", "private, no-store")
+  } else {
+    posts = visiblePosts(posts)
+  }
+
+  w.Header().Set("Content-Type", "application/json")
+  w.Header().Set("Content-Encoding", "gzip")
+  w.Header().Set("Content-Disposition", `attachment; filename="posts.json.gz"`)
+  gz := gzip.NewWriter(w)
+  defer gz.Close()
+
+  // Once the first bytes are sent the status can't change anymore, an encoding error can only cut the export short.
+  var buf bytes.Buffer
+  gz.Write([]byte("["))
+  for i, post := range posts {
+    buf.Reset()
+    if i > 0 {
+      buf.WriteByte(',')
+    }
+    if err := encodeJSON(&buf, post); err != nil {
+      return
+    }
+    if _, err := gz.Write(buf.Bytes()); err != nil {
+      return
+    }
+  }
+  gz.Write([]byte("]\n"))
+}
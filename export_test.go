@@ -0,0 +1,96 @@
+package main
+
+import (
+  "compress/gzip"
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestExportRange(t *testing.T) {
+  exports := []struct {
+    path    string
+    handler http.HandlerFunc
+  }{
+    {"/export.json", exportJSON},
+    {"/export.csv", exportCSV},
+  }
+  for _, export := range exports {
+    t.Run(export.path, func(t *testing.T) {
+      setup(t, testPosts()...)
+
+      full := serve("GET "+export.path, export.handler, httptest.NewRequest(http.MethodGet, export.path, nil))
+      if full.Code != http.StatusOK {
+        t.Fatalf("got status %d, want %d", full.Code, http.StatusOK)
+      }
+      if got := full.Header().Get("Accept-Ranges"); got != "bytes" {
+        t.Errorf("got Accept-Ranges %q, want bytes", got)
+      }
+
+      r := httptest.NewRequest(http.MethodGet, export.path, nil)
+      r.Header.Set("Range", "bytes=10-19")
+      w := serve("GET "+export.path, export.handler, r)
+      if w.Code != http.StatusPartialContent {
+        t.Fatalf("range: got status %d, want %d", w.Code, http.StatusPartialContent)
+      }
+      if got, want := w.Body.String(), full.Body.String()[10:20]; got != want {
+        t.Errorf("range: got %q, want %q", got, want)
+      }
+      if got := w.Header().Get("Content-Range"); got == "" {
+        t.Error("range: got no Content-Range")
+      }
+
+      // A resumed download of an export that changed since gets the whole file.
+      r = httptest.NewRequest(http.MethodGet, export.path, nil)
+      r.Header.Set("Range", "bytes=10-")
+      r.Header.Set("If-Range", `"stale"`)
+      if w := serve("GET "+export.path, export.handler, r); w.Code != http.StatusOK || w.Body.String() != full.Body.String() {
+        t.Errorf("stale If-Range: got status %d, want %d and the whole export", w.Code, http.StatusOK)
+      }
+    })
+  }
+}
+
+// gunzipPosts decompresses the gzipped JSON export in the response.
+func gunzipPosts(t *testing.T, w *httptest.ResponseRecorder) []Post {
+  t.Helper()
+  gz, err := gzip.NewReader(w.Body)
+  if err != nil {
+    t.Fatal(err)
+  }
+  var posts []Post
+  if err := json.NewDecoder(gz).Decode(&posts); err != nil {
+    t.Fatal(err)
+  }
+  return posts
+}
+
+func TestExportJSONGzip(t *testing.T) {
+  posts := testPosts()
+  posts[1].Hidden = true
+  posts[2].Deleted = true
+  setup(t, posts...)
+  config.AdminToken = testAdminToken
+
+  w := serve("GET /posts/export.json.gz", exportJSONGzip, httptest.NewRequest(http.MethodGet, "/posts/export.json.gz", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+    t.Errorf("got Content-Encoding %q, want gzip", got)
+  }
+  if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="posts.json.gz"` {
+    t.Errorf("got Content-Disposition %q", got)
+  }
+  if got := gunzipPosts(t, w); len(got) != 1 || got[0].ID != "1" || got[0].Content != posts[0].Content {
+    t.Errorf("public export: got %+v, want the public post", got)
+  }
+
+  // Admins get the full list, for backups.
+  w = serve("GET /posts/export.json.gz", exportJSONGzip, adminRequest(http.MethodGet, "/posts/export.json.gz", nil))
+  got := gunzipPosts(t, w)
+  if len(got) != 3 || got[1].ID != "2" || !got[2].Deleted {
+    t.Errorf("admin export: got %+v, want every post", got)
+  }
+}
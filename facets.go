@@ -0,0 +1,58 @@
+package main
+
+import (
+  "fmt"
+  "net/url"
+  "strings"
+)
+
+/*
+  FACETS
+
+  A list with filter chips needs to know how many posts each chip would leave. With ?facets=tags index answers with the posts and the number of posts for each tag next to them:
+
+  {"data": [...], "facets": {"tags": {"go": 3, "web": 1}}}
+
+  The counts are for every post matching the other parameters (min_views, tags, contributor...), not only the page of them being listed, so they don't change when paging through the results. Tags are counted in lowercase since ?tags= ignores case.
+
+  Listing with facets reads every post even with STREAM_POSTS, like ?rank=hybrid.
+*/
+const tagsFacet = "tags"
+
+// parseFacets tells whether the query asks for the tag facet, tags being the only one for now.
+func parseFacets(query url.Values) (bool, error) {
+  value := query.Get("facets")
+  if value == "" {
+    return false, nil
+  }
+  for _, facet := range strings.Split(value, ",") {
+    if strings.TrimSpace(facet) != tagsFacet {
+      return false, fmt.Errorf("unknown facet %q, use %s", facet, tagsFacet)
+    }
+  }
+  return true, nil
+}
+
+// tagFacets counts the posts of each tag among the posts the query lists, paging aside.
+func tagFacets(query url.Values, posts []Post, includeDeleted bool) (map[string]int, error) {
+  listing, err := parseListing(query, includeDeleted)
+  if err != nil {
+    return nil, err
+  }
+  counts := map[string]int{}
+  for i := range posts {
+    if !listing.keep(&posts[i]) {
+      continue
+    }
+    // A post tagged both "Go" and "go" counts once.
+    seen := map[string]bool{}
+    for _, tag := range posts[i].Tags {
+      tag = strings.ToLower(tag)
+      if !seen[tag] {
+        seen[tag] = true
+        counts[tag]++
+      }
+    }
+  }
+  return counts, nil
+}
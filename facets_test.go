@@ -0,0 +1,66 @@
+package main
+
+import (
+  "maps"
+  "net/http"
+  "strings"
+  "testing"
+)
+
+// listedWithFacets lists the posts with ?facets=tags and the query, and returns the listed posts and the tag counts.
+func listedWithFacets(t *testing.T, query string) ([]Post, map[string]int) {
+  t.Helper()
+  w := listPosts("?facets=tags" + query)
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  got := decode[struct {
+    Data   []Post
+    Facets map[string]map[string]int
+  }](t, w)
+  return got.Data, got.Facets[tagsFacet]
+}
+
+func TestTagFacets(t *testing.T) {
+  posts := append(testPosts(), Post{ID: "4", Title: "Hidden post", Content: "Hidden", Author: "Jane Doe", CreatedAt: "2025-01-04T10:00:00Z", Tags: []string{"go"}, Hidden: true})
+  posts[0].Tags = []string{"go", "web"}
+  posts[1].Tags = []string{"Go"}
+  posts[2].Tags = []string{"web", "css"}
+  setup(t, posts...)
+
+  tests := []struct {
+    query string
+    want  map[string]int
+  }{
+    {"", map[string]int{"go": 2, "web": 2, "css": 1}},
+    {"&tags=web", map[string]int{"go": 1, "web": 2, "css": 1}},
+    {"&contributor=John%20Smith", map[string]int{"go": 1}},
+    {"&tags=rust", map[string]int{}},
+  }
+  for _, test := range tests {
+    listed, facets := listedWithFacets(t, test.query)
+    if !maps.Equal(facets, test.want) {
+      t.Errorf("%s: got facets %v, want %v", test.query, facets, test.want)
+    }
+    // The counts are the ones of the listed posts.
+    counted := map[string]int{}
+    for _, post := range listed {
+      for _, tag := range post.Tags {
+        counted[strings.ToLower(tag)]++
+      }
+    }
+    if !maps.Equal(facets, counted) {
+      t.Errorf("%s: got facets %v for posts tagged %v", test.query, facets, counted)
+    }
+  }
+
+  // Paging doesn't change the counts.
+  listed, facets := listedWithFacets(t, "&limit=1")
+  if len(listed) != 1 || !maps.Equal(facets, tests[0].want) {
+    t.Errorf("limit=1: got %d posts and facets %v, want 1 post and %v", len(listed), facets, tests[0].want)
+  }
+
+  if w := listPosts("?facets=authors"); w.Code != http.StatusBadRequest {
+    t.Errorf("unknown facet: got status %d, want %d", w.Code, http.StatusBadRequest)
+  }
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+  "encoding/xml"
+  "net/http"
+  "sort"
+  "time"
+)
+
+/*
+  RSS FEED HANDLER
+
+  Serves the most recent posts (config.FeedLimit) as an RSS 2.0 feed so readers can subscribe to the blog. encoding/xml works just like encoding/json: struct tags map the fields to elements (or attributes with ",attr") and it takes care of escaping the content.
+
+  RSS expects <author> to be an email address, so the author name goes in the Dublin Core <dc:creator> element instead.
+*/
+type rssFeed struct {
+  XMLName xml.Name   `xml:"rss"`
+  Version string     `xml:"version,attr"`
+  DC      string     `xml:"xmlns:dc,attr"`
+  Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+  Title       string    `xml:"title"`
+  Link        string    `xml:"link"`
+  Description string    `xml:"description"`
+  Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+  Title       string `xml:"title"`
+  Link        string `xml:"link"`
+  GUID        string `xml:"guid"`
+  Description string `xml:"description"`
+  Creator     string `xml:"dc:creator"`
+  PubDate     string `xml:"pubDate,omitempty"`
+}
+
+func feed(w http.ResponseWriter, r *http.Request) {
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  posts = visiblePosts(posts)
+
+  sortNewestFirst(posts)
+  if len(posts) > config.FeedLimit {
+    posts = posts[:config.FeedLimit]
+  }
+
+  channel := rssChannel{
+    Title:       config.FeedTitle,
+    Link:        config.BaseURL,
+    Description: "The latest posts of " + config.FeedTitle,
+    Items:       []rssItem{},
+  }
+  for _, post := range posts {
+    link := permalink(post)
+    item := rssItem{
+      Title:       post.Title,
+      Link:        link,
+      GUID:        link,
+      Description: post.Content,
+      Creator:     post.Author,
+    }
+    if created, ok := displayTime(post.CreatedAt); ok {
+      item.PubDate = created.Format(time.RFC1123Z)
+    }
+    channel.Items = append(channel.Items, item)
+  }
+
+  data, err := xml.MarshalIndent(rssFeed{Version: "2.0", DC: "http://purl.org/dc/elements/1.1/", Channel: channel}, "", "  ")
+  if err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error encoding feed")
+    return
+  }
+  w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+  w.Write([]byte(xml.Header))
+  w.Write(data)
+}
+
+// sortNewestFirst sorts the posts by CreatedAt, most recent first. Posts without a valid CreatedAt go last, in their original order.
+func sortNewestFirst(posts []Post) {
+  sort.SliceStable(posts, func(i, j int) bool {
+    a, okA := parseTimestamp(posts[i].CreatedAt)
+    b, okB := parseTimestamp(posts[j].CreatedAt)
+    if okA != okB {
+      return okA
+    }
+    return a.After(b)
+  })
+}
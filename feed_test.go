@@ -0,0 +1,53 @@
+package main
+
+import (
+  "encoding/xml"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func TestFeed(t *testing.T) {
+  posts := testPosts()
+  posts[0].Title = `Tom & Jerry <3 "quotes"`
+  posts = append(posts, Post{ID: "4", Title: "Hidden", Content: "c", Author: "a", CreatedAt: "2025-01-04T10:00:00Z", Hidden: true})
+  setup(t, posts...)
+  config.FeedLimit = 2
+
+  w := serve("GET /feed.xml", feed, httptest.NewRequest(http.MethodGet, "/feed.xml", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/rss+xml") {
+    t.Errorf("got Content-Type %q", got)
+  }
+  var got struct {
+    Version string `xml:"version,attr"`
+    Items   []struct {
+      Title   string `xml:"title"`
+      Link    string `xml:"link"`
+      Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+      PubDate string `xml:"pubDate"`
+    } `xml:"channel>item"`
+  }
+  if err := xml.Unmarshal(w.Body.Bytes(), &got); err != nil {
+    t.Fatalf("the feed isn't valid XML: %v", err)
+  }
+  if got.Version != "2.0" {
+    t.Errorf("got version %q, want 2.0", got.Version)
+  }
+  // The hidden post is left out, the limit keeps the two most recent of the others.
+  if len(got.Items) != 2 || got.Items[0].Title != "Third post" || got.Items[1].Title != "Second post" {
+    t.Fatalf("got items %+v, want the third and second posts", got.Items)
+  }
+  if got.Items[0].Creator != "Jane Doe" || got.Items[0].PubDate != "Fri, 03 Jan 2025 10:00:00 +0000" {
+    t.Errorf("got creator %q and pubDate %q", got.Items[0].Creator, got.Items[0].PubDate)
+  }
+
+  config.FeedLimit = 10
+  w = serve("GET /feed.xml", feed, httptest.NewRequest(http.MethodGet, "/feed.xml", nil))
+  if !strings.Contains(w.Body.String(), "Tom &amp; Jerry &lt;3") {
+    t.Errorf("the title isn't escaped: %s", w.Body)
+  }
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+  "net/http"
+  "os"
+  "path/filepath"
+)
+
+/*
+  READINESS
+
+  GET /readyz tells a load balancer or orchestrator whether the service can do its job, which includes saving posts. It writes and removes a temporary file in the directory of the posts file, so a full disk or a read-only filesystem shows up here instead of as failing writes:
+
+  200 {"status": "ready"}
+  503 {"status": "unavailable", "reason": "open posts/.readyz-123: read-only file system"}
+
+  There's nothing to write in memory-only or read-only mode, so the service is always ready then.
+*/
+func readyz(w http.ResponseWriter, r *http.Request) {
+  if config.MemoryOnly || config.ReadOnly {
+    writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+    return
+  }
+  dir := filepath.Dir(config.FilePath)
+  if config.PostsDir != "" {
+    dir = config.PostsDir
+  }
+  if err := checkWritable(dir); err != nil {
+    writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable", "reason": err.Error()})
+    return
+  }
+  writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// checkWritable creates, writes and removes a temporary file in dir.
+func checkWritable(dir string) error {
+  file, err := os.CreateTemp(dir, ".readyz-*")
+  if err != nil {
+    return err
+  }
+  // The file is removed whatever happens next.
+  defer os.Remove(file.Name())
+  if _, err := file.WriteString("ok"); err != nil {
+    file.Close()
+    return err
+  }
+  return file.Close()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "path/filepath"
+  "testing"
+)
+
+func TestReadyz(t *testing.T) {
+  setup(t)
+  config.MemoryOnly = false
+  dir := t.TempDir()
+  config.FilePath = filepath.Join(dir, "posts.json")
+
+  w := serve("GET /readyz", readyz, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("writable: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+    t.Errorf("got %d files left behind, want none", len(entries))
+  }
+
+  // Permissions don't stop root, which tests may run as, but a "directory" that is a file stops anyone.
+  notADir := filepath.Join(dir, "file")
+  if err := os.WriteFile(notADir, nil, 0644); err != nil {
+    t.Fatal(err)
+  }
+  config.FilePath = filepath.Join(notADir, "posts.json")
+  w = serve("GET /readyz", readyz, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+  if w.Code != http.StatusServiceUnavailable {
+    t.Fatalf("unwritable: got status %d, want %d", w.Code, http.StatusServiceUnavailable)
+  }
+  if got := decode[map[string]string](t, w); got["status"] != "unavailable" || got["reason"] == "" {
+    t.Errorf("unwritable: got %v, want the status and a reason", got)
+  }
+}
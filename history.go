@@ -0,0 +1,97 @@
+package main
+
+import (
+  "net/http"
+  "strconv"
+
+  "github.com/pmezard/go-difflib/difflib"
+)
+
+/*
+  HISTORY
+
+  Every time an edit changes the Title or Content of a post, the previous version is kept in its Revisions. Revisions are numbered from 1, the original version, up to the current version of the post which is always the last one.
+*/
+type Revision struct {
+  Title     string `json:"Title"`
+  Content   string `json:"Content"`
+  UpdatedAt string `json:"UpdatedAt"`
+}
+
+// recordRevision keeps previous, the version of the post before an edit, when the edit changed its Title or Content.
+func (post *Post) recordRevision(previous Post) {
+  if previous.Title == post.Title && previous.Content == post.Content {
+    return
+  }
+  updatedAt := previous.UpdatedAt
+  if updatedAt == "" {
+    updatedAt = previous.CreatedAt
+  }
+  post.Revisions = append(previous.Revisions, Revision{Title: previous.Title, Content: previous.Content, UpdatedAt: updatedAt})
+}
+
+// revision returns version n of the post, counting from 1.
+func (post *Post) revision(n int) (Revision, bool) {
+  switch {
+  case n >= 1 && n <= len(post.Revisions):
+    return post.Revisions[n-1], true
+  case n == len(post.Revisions)+1:
+    return Revision{Title: post.Title, Content: post.Content, UpdatedAt: post.UpdatedAt}, true
+  }
+  return Revision{}, false
+}
+
+/*
+  DIFF HANDLER
+
+  Returns a unified diff of the Content between two revisions, the previous and current ones by default:
+
+  GET /posts/1/diff?from=1&to=3
+*/
+func postDiff(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+  post := posts[i]
+  latest := len(post.Revisions) + 1
+
+  from, to := max(latest-1, 1), latest
+  revisions := map[string]*int{"from": &from, "to": &to}
+  for name, n := range revisions {
+    value := r.URL.Query().Get(name)
+    if value == "" {
+      continue
+    }
+    parsed, err := strconv.Atoi(value)
+    if err != nil || parsed < 1 || parsed > latest {
+      jsonError(w, http.StatusBadRequest, name+" must be a revision between 1 and "+strconv.Itoa(latest))
+      return
+    }
+    *n = parsed
+  }
+
+  a, _ := post.revision(from)
+  b, _ := post.revision(to)
+  diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+    A:        difflib.SplitLines(a.Content),
+    B:        difflib.SplitLines(b.Content),
+    FromFile: "revision " + strconv.Itoa(from),
+    ToFile:   "revision " + strconv.Itoa(to),
+    Context:  3,
+  })
+  if err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error computing diff")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, map[string]any{"from": from, "to": to, "revisions": latest, "diff": diff})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func TestDiffBetweenRevisions(t *testing.T) {
+  setup(t, Post{ID: "1", Title: "Post", Content: "line one\nline two", Author: "Jane Doe", CreatedAt: "2025-01-01T10:00:00Z"})
+  for _, content := range []string{`line one\nline 2`, `line one\nline 2\nline three`} {
+    w := serve("PATCH /posts/{id}", patchPost, patchRequest(`[{"op": "replace", "path": "/Content", "value": "`+content+`"}]`))
+    if w.Code != http.StatusOK {
+      t.Fatalf("patch: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+    }
+  }
+  if got := len(storedPosts(t)[0].Revisions); got != 2 {
+    t.Fatalf("got %d revisions, want 2", got)
+  }
+
+  w := serve("GET /posts/{id}/diff", postDiff, httptest.NewRequest(http.MethodGet, "/posts/1/diff?from=1&to=2", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  got := decode[map[string]any](t, w)
+  want := "--- revision 1\n+++ revision 2\n@@ -1,2 +1,2 @@\n line one\n-line two\n+line 2\n"
+  if got["diff"] != want {
+    t.Errorf("got diff %q, want %q", got["diff"], want)
+  }
+  if got["revisions"] != float64(3) {
+    t.Errorf("got %v revisions, want 3", got["revisions"])
+  }
+
+  // Without from and to it's the previous revision against the current one.
+  w = serve("GET /posts/{id}/diff", postDiff, httptest.NewRequest(http.MethodGet, "/posts/1/diff", nil))
+  if diff := decode[map[string]any](t, w)["diff"].(string); !strings.Contains(diff, "+line three\n") || strings.Contains(diff, "-line two") {
+    t.Errorf("got diff %q, want revision 2 against 3", diff)
+  }
+}
+
+func TestDiffInvalidRevisions(t *testing.T) {
+  setup(t, testPosts()...)
+  for _, query := range []string{"from=0", "to=3", "from=abc", "from=-1"} {
+    w := serve("GET /posts/{id}/diff", postDiff, httptest.NewRequest(http.MethodGet, "/posts/1/diff?"+query, nil))
+    if w.Code != http.StatusBadRequest {
+      t.Errorf("%s: got status %d, want %d", query, w.Code, http.StatusBadRequest)
+    }
+  }
+}
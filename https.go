@@ -0,0 +1,43 @@
+package main
+
+import (
+  "net/http"
+  "strings"
+)
+
+/*
+  FORCING HTTPS
+
+  Behind a proxy terminating TLS, the requests reach us over plain HTTP whatever the client used. The proxy tells us which scheme the client used in the X-Forwarded-Proto header.
+
+  With FORCE_HTTPS set, requests that didn't come over HTTPS are redirected to the same URL with https. 308 Permanent Redirect, unlike 301, makes clients repeat the request with the same method and body, so a POST stays a POST.
+
+  Health checks are left alone, load balancers usually probe the service over plain HTTP.
+*/
+var healthPaths = []string{"/readyz"}
+
+func forceHTTPS(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if !config.ForceHTTPS || isSecure(r) {
+      next.ServeHTTP(w, r)
+      return
+    }
+    for _, path := range healthPaths {
+      if r.URL.Path == path {
+        next.ServeHTTP(w, r)
+        return
+      }
+    }
+    http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+  })
+}
+
+// isSecure reports whether the client sent the request over HTTPS, to us or to the proxy in front of us.
+func isSecure(r *http.Request) bool {
+  if r.TLS != nil {
+    return true
+  }
+  // Proxies chaining up add their own scheme to the list, the first one is the client's.
+  proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+  return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestForceHTTPS(t *testing.T) {
+  setup(t)
+  config.ForceHTTPS = true
+  ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+  handler := forceHTTPS(ok)
+
+  tests := []struct {
+    name, method, target, proto string
+    status                      int
+    location                    string
+  }{
+    {"plain HTTP", http.MethodGet, "/posts/1?format=html", "http", http.StatusPermanentRedirect, "https://example.com/posts/1?format=html"},
+    {"POST stays a POST", http.MethodPost, "/create", "http", http.StatusPermanentRedirect, "https://example.com/create"},
+    {"HTTPS", http.MethodGet, "/posts/1", "https", http.StatusOK, ""},
+    {"chained proxies", http.MethodGet, "/posts/1", "https, http", http.StatusOK, ""},
+    {"health check", http.MethodGet, "/readyz", "http", http.StatusOK, ""},
+  }
+  for _, test := range tests {
+    r := httptest.NewRequest(test.method, "http://example.com"+test.target, nil)
+    r.Header.Set("X-Forwarded-Proto", test.proto)
+    w := httptest.NewRecorder()
+    handler.ServeHTTP(w, r)
+    if w.Code != test.status {
+      t.Errorf("%s: got status %d, want %d", test.name, w.Code, test.status)
+    }
+    if got := w.Header().Get("Location"); got != test.location {
+      t.Errorf("%s: got Location %q, want %q", test.name, got, test.location)
+    }
+  }
+
+  config.ForceHTTPS = false
+  r := httptest.NewRequest(http.MethodGet, "/posts/1", nil)
+  r.Header.Set("X-Forwarded-Proto", "http")
+  w := httptest.NewRecorder()
+  handler.ServeHTTP(w, r)
+  if w.Code != http.StatusOK {
+    t.Errorf("without FORCE_HTTPS: got status %d, want %d", w.Code, http.StatusOK)
+  }
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "os"
+  "strconv"
+  "strings"
+  "sync"
+
+  "github.com/google/uuid"
+  "github.com/segmentio/ksuid"
+)
+
+/*
+  POST IDS
+
+  IDs are strings so that any of the strategies below can be used, picked with ID_STRATEGY:
+
+  sequential   1, 2, 3... The last ID handed out is kept in a counter file next to the posts, so IDs of deleted posts are never reused.
+  uuid         random UUIDs (version 4), nothing to keep track of.
+  ksuid        K-Sortable Unique IDs, random like UUIDs but they start with a timestamp so they sort by creation time.
+
+  Older posts files store the ID as a JSON number, PostID accepts both.
+*/
+const (
+  sequentialIDs = "sequential"
+  uuidIDs       = "uuid"
+  ksuidIDs      = "ksuid"
+)
+
+type PostID string
+
+// UnmarshalJSON is called by encoding/json instead of the default decoding, which lets numbers through as well as strings.
+func (id *PostID) UnmarshalJSON(data []byte) error {
+  if bytes.HasPrefix(data, []byte(`"`)) {
+    var value string
+    if err := json.Unmarshal(data, &value); err != nil {
+      return err
+    }
+    *id = PostID(value)
+    return nil
+  }
+  var number json.Number
+  if err := json.Unmarshal(data, &number); err != nil {
+    return errors.New("ID must be a string or a number")
+  }
+  *id = PostID(number.String())
+  return nil
+}
+
+// IDGenerator hands out the ID of new posts. posts are the posts already stored.
+type IDGenerator interface {
+  NewID(posts []Post) (PostID, error)
+}
+
+var ids IDGenerator
+
+// newIDGenerator returns the generator selected by the config.
+func newIDGenerator(config Config) IDGenerator {
+  switch config.IDStrategy {
+  case uuidIDs:
+    return uuidGenerator{}
+  case ksuidIDs:
+    return ksuidGenerator{}
+  }
+  if config.MemoryOnly {
+    return &sequentialGenerator{}
+  }
+  return &sequentialGenerator{Path: config.FilePath + ".seq"}
+}
+
+/*
+  sequentialGenerator counts up from the highest ID in use or the last one it handed out, whichever is higher. The counter lives in memory and, when Path is set, in that file so it survives restarts.
+*/
+type sequentialGenerator struct {
+  Path string
+
+  mu   sync.Mutex
+  last int
+}
+
+func (g *sequentialGenerator) NewID(posts []Post) (PostID, error) {
+  g.mu.Lock()
+  defer g.mu.Unlock()
+
+  if g.Path != "" {
+    data, err := os.ReadFile(g.Path)
+    if err != nil && !errors.Is(err, os.ErrNotExist) {
+      return "", err
+    }
+    if err == nil {
+      stored, err := strconv.Atoi(strings.TrimSpace(string(data)))
+      if err != nil {
+        return "", fmt.Errorf("%s is corrupt: %w", g.Path, err)
+      }
+      g.last = max(g.last, stored)
+    }
+  }
+  for _, post := range posts {
+    // IDs from other strategies aren't numbers and don't count.
+    if n, err := strconv.Atoi(string(post.ID)); err == nil {
+      g.last = max(g.last, n)
+    }
+  }
+
+  g.last++
+  if g.Path != "" {
+    if err := os.WriteFile(g.Path, []byte(strconv.Itoa(g.last)+"\n"), 0644); err != nil {
+      return "", err
+    }
+  }
+  return PostID(strconv.Itoa(g.last)), nil
+}
+
+type uuidGenerator struct{}
+
+func (uuidGenerator) NewID(posts []Post) (PostID, error) {
+  id, err := uuid.NewRandom()
+  if err != nil {
+    return "", err
+  }
+  return PostID(id.String()), nil
+}
+
+type ksuidGenerator struct{}
+
+func (ksuidGenerator) NewID(posts []Post) (PostID, error) {
+  id, err := ksuid.NewRandom()
+  if err != nil {
+    return "", err
+  }
+  return PostID(id.String()), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+  "net/http"
+  "path/filepath"
+  "testing"
+
+  "github.com/google/uuid"
+  "github.com/segmentio/ksuid"
+)
+
+func TestIDStrategies(t *testing.T) {
+  tests := []struct {
+    strategy string
+    valid    func(id PostID) bool
+  }{
+    {sequentialIDs, func(id PostID) bool { return id == "1" || id == "2" || id == "3" }},
+    {uuidIDs, func(id PostID) bool { _, err := uuid.Parse(string(id)); return err == nil }},
+    {ksuidIDs, func(id PostID) bool { _, err := ksuid.Parse(string(id)); return err == nil }},
+  }
+  for _, test := range tests {
+    t.Run(test.strategy, func(t *testing.T) {
+      setup(t)
+      config.IDStrategy = test.strategy
+      ids = newIDGenerator(config)
+
+      seen := map[PostID]bool{}
+      for _, title := range []string{"First", "Second", "Third"} {
+        w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: title, Content: "Content of " + title, Author: "Jane Doe"}))
+        if w.Code != http.StatusCreated {
+          t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+        }
+        id := decode[Post](t, w).ID
+        if !test.valid(id) {
+          t.Errorf("got invalid ID %q", id)
+        }
+        if seen[id] {
+          t.Errorf("got ID %q twice", id)
+        }
+        seen[id] = true
+      }
+    })
+  }
+}
+
+func TestSequentialIDsSurviveRestarts(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "posts.json.seq")
+  posts := []Post{{ID: "1"}, {ID: "2"}}
+
+  if id, err := (&sequentialGenerator{Path: path}).NewID(posts); err != nil || id != "3" {
+    t.Fatalf("got ID %q and error %v, want 3", id, err)
+  }
+  // After a restart, with post 3 deleted, 3 isn't handed out again.
+  if id, err := (&sequentialGenerator{Path: path}).NewID(posts); err != nil || id != "4" {
+    t.Errorf("after a restart: got ID %q and error %v, want 4", id, err)
+  }
+}
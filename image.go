@@ -0,0 +1,49 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "net/http"
+  "net/url"
+  "time"
+)
+
+/*
+  FEATURE IMAGES
+
+  FeatureImage is the address of the cover image shown on the post's card. It's optional, but when set it has to be an absolute http or https URL: url.Parse alone accepts nearly anything, "cover.png" included.
+
+  With CHECK_IMAGE_URLS, create also makes sure the image can be fetched, asking for its headers with a HEAD request. It's off by default since it makes create depend on someone else's server.
+*/
+func validateFeatureImage(value string) error {
+  if value == "" {
+    return nil
+  }
+  u, err := url.Parse(value)
+  if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+    return fmt.Errorf("FeatureImage must be an http or https URL, got %q", value)
+  }
+  return nil
+}
+
+const imageCheckTimeout = 5 * time.Second
+
+// checkImageReachable reports an error when the image at value can't be fetched.
+func checkImageReachable(ctx context.Context, value string) error {
+  ctx, cancel := context.WithTimeout(ctx, imageCheckTimeout)
+  defer cancel()
+  request, err := http.NewRequestWithContext(ctx, http.MethodHead, value, nil)
+  if err != nil {
+    return err
+  }
+  response, err := http.DefaultClient.Do(request)
+  if err != nil {
+    return errors.New("FeatureImage can't be reached")
+  }
+  response.Body.Close()
+  if response.StatusCode >= 400 {
+    return fmt.Errorf("FeatureImage can't be reached, got %s", response.Status)
+  }
+  return nil
+}
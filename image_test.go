@@ -0,0 +1,57 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestCreateFeatureImage(t *testing.T) {
+  tests := []struct {
+    image  string
+    status int
+  }{
+    {"https://example.com/cover.png", http.StatusCreated},
+    {"", http.StatusCreated},
+    {"cover.png", http.StatusUnprocessableEntity},
+    {"ftp://example.com/cover.png", http.StatusUnprocessableEntity},
+    {"https://", http.StatusUnprocessableEntity},
+  }
+  for _, test := range tests {
+    setup(t)
+    w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe", FeatureImage: test.image}))
+    if w.Code != test.status {
+      t.Errorf("%q: got status %d, want %d: %s", test.image, w.Code, test.status, w.Body)
+      continue
+    }
+    if test.status != http.StatusCreated {
+      if got := decode[invalidPost](t, w).Fields["FeatureImage"]; got == "" {
+        t.Errorf("%q: got no FeatureImage error", test.image)
+      }
+      continue
+    }
+    // show returns the image.
+    w = serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+    if got := decode[Post](t, w).FeatureImage; got != test.image {
+      t.Errorf("%q: show got FeatureImage %q", test.image, got)
+    }
+  }
+}
+
+func TestCheckImageURLs(t *testing.T) {
+  images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if r.URL.Path != "/cover.png" {
+      http.NotFound(w, r)
+    }
+  }))
+  defer images.Close()
+
+  for path, status := range map[string]int{"/cover.png": http.StatusCreated, "/missing.png": http.StatusUnprocessableEntity} {
+    setup(t)
+    config.CheckImageURLs = true
+    w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe", FeatureImage: images.URL + path}))
+    if w.Code != status {
+      t.Errorf("%s: got status %d, want %d: %s", path, w.Code, status, w.Body)
+    }
+  }
+}
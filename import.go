@@ -0,0 +1,143 @@
+package main
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "net/url"
+)
+
+/*
+  IMPORT HANDLER
+
+  POST /posts/import-url seeds an instance with the posts of another one. It takes the URL of a JSON list of posts, typically the /export.json of the other instance, fetches it and adds its posts to ours (admin only):
+
+  curl -X POST -H "Authorization: Bearer $ADMIN_TOKEN" -d '{"url": "https://old.example.com/export.json"}' http://localhost:3000/posts/import-url
+
+  The download is given IMPORT_TIMEOUT (30s by default) and IMPORT_MAX_BYTES (10MB by default) at most. Anything but a 200 answer, or posts that don't validate, and nothing is imported: the answer is a 502 or a 422 saying why.
+
+  Posts with an ID we already have, deleted posts included, are skipped, so importing the same export twice adds its posts once. Posts without an ID get a new one, every time they're imported, and slugs taken by our posts are numbered, see slug.go. The answer counts what happened:
+
+  {"imported": 12, "skipped": ["1", "2"]}
+*/
+type importSummary struct {
+  Imported int      `json:"imported"`
+  Skipped  []PostID `json:"skipped"`
+}
+
+func importURL(w http.ResponseWriter, r *http.Request) {
+  var request struct {
+    URL string `json:"url"`
+  }
+  if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+    jsonError(w, http.StatusBadRequest, "invalid JSON body")
+    return
+  }
+  defer r.Body.Close()
+  source, err := url.Parse(request.URL)
+  if err != nil || (source.Scheme != "http" && source.Scheme != "https") || source.Host == "" {
+    jsonError(w, http.StatusBadRequest, fmt.Sprintf("url must be an http or https URL, got %q", request.URL))
+    return
+  }
+
+  imported, err := fetchPosts(r, source.String())
+  if err != nil {
+    jsonError(w, http.StatusBadGateway, err.Error())
+    return
+  }
+  problems := []problem{}
+  for _, post := range imported {
+    for _, e := range post.fieldErrors() {
+      problems = append(problems, problem{ID: post.ID, Field: e.Field, Message: e.Err.Error()})
+    }
+  }
+  if len(problems) > 0 {
+    writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": "the posts don't validate, nothing was imported", "problems": problems})
+    return
+  }
+
+  // The download can take a while, the posts are only locked once it's done, see main.go.
+  postsMu.Lock()
+  defer postsMu.Unlock()
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  known := map[PostID]bool{}
+  for _, post := range posts {
+    known[post.ID] = true
+  }
+
+  summary := importSummary{Skipped: []PostID{}}
+  for _, post := range imported {
+    if post.ID != "" && known[post.ID] {
+      summary.Skipped = append(summary.Skipped, post.ID)
+      continue
+    }
+    if post.ID == "" {
+      if post.ID, err = ids.NewID(posts); err != nil {
+        jsonError(w, http.StatusInternalServerError, "Error generating post ID")
+        return
+      }
+    }
+    // Exports show the timestamps in the time zone of the other instance, and come with what's only computed for responses.
+    post.CreatedAt = storedTimestamp(post.CreatedAt)
+    post.UpdatedAt = storedTimestamp(post.UpdatedAt)
+    post.LastViewed = storedTimestamp(post.LastViewed)
+    post.OriginalCreatedAt = storedTimestamp(post.OriginalCreatedAt)
+    post.WordCount = nil
+    if post.Slug != "" {
+      post.Slug = uniqueSlug(posts, post.Slug, post.ID)
+    }
+    known[post.ID] = true
+    posts = append(posts, post)
+    summary.Imported++
+  }
+
+  if summary.Imported > 0 {
+    if err := savePosts(r.Context(), posts); err != nil {
+      jsonError(w, http.StatusInternalServerError, "Error saving posts")
+      return
+    }
+  }
+  writeJSON(w, http.StatusOK, summary)
+}
+
+// fetchPosts downloads the list of posts at source, within IMPORT_TIMEOUT and IMPORT_MAX_BYTES.
+func fetchPosts(r *http.Request, source string) ([]Post, error) {
+  ctx, cancel := context.WithTimeout(r.Context(), config.ImportTimeout.Duration)
+  defer cancel()
+  request, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+  if err != nil {
+    return nil, err
+  }
+  request.Header.Set("Accept", "application/json")
+  response, err := http.DefaultClient.Do(request)
+  if err != nil {
+    return nil, fmt.Errorf("fetching %s failed: %w", source, err)
+  }
+  defer response.Body.Close()
+  if response.StatusCode != http.StatusOK {
+    return nil, fmt.Errorf("fetching %s failed, got %s", source, response.Status)
+  }
+
+  body, err := io.ReadAll(io.LimitReader(response.Body, config.ImportMaxBytes+1))
+  if err != nil {
+    return nil, fmt.Errorf("fetching %s failed: %w", source, err)
+  }
+  if int64(len(body)) > config.ImportMaxBytes {
+    return nil, fmt.Errorf("%s is larger than %d bytes", source, config.ImportMaxBytes)
+  }
+  if body, err = decodeText(body); err != nil {
+    return nil, fmt.Errorf("%s: %w", source, err)
+  }
+  var posts []Post
+  if err := json.Unmarshal(body, &posts); err != nil {
+    return nil, errors.New(source + " isn't a JSON list of posts")
+  }
+  return posts, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "strings"
+  "testing"
+  "time"
+)
+
+// importRequest asks to import the posts at source.
+func importRequest(source string) *http.Request {
+  body, _ := json.Marshal(map[string]string{"url": source})
+  return adminRequest(http.MethodPost, "/posts/import-url", strings.NewReader(string(body)))
+}
+
+func TestImportURL(t *testing.T) {
+  posts := testPosts()
+  posts[0].Slug = "first-post"
+  setup(t, posts...)
+  config.AdminToken = testAdminToken
+  export := []Post{
+    {ID: "1", Title: "First post", Content: "Already here.", Author: "Jane Doe", CreatedAt: "2025-01-01T10:00:00Z"},
+    {ID: "10", Title: "First post", Slug: "first-post", Content: "Same slug, other post.", Author: "Ann Lee", CreatedAt: "2025-02-01T10:00:00Z"},
+    {Title: "No ID", Content: "Gets a new one.", Author: "Ann Lee", CreatedAt: "2025-02-02T10:00:00Z"},
+  }
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    json.NewEncoder(w).Encode(export)
+  }))
+  defer server.Close()
+
+  w := serve("POST /posts/import-url", requireAdmin(importURL), importRequest(server.URL+"/export.json"))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  summary := decode[importSummary](t, w)
+  if summary.Imported != 2 || !slices.Equal(summary.Skipped, []PostID{"1"}) {
+    t.Errorf("got %+v, want 2 imported and post 1 skipped", summary)
+  }
+  stored := storedPosts(t)
+  if len(stored) != 5 || stored[0].Content != "The content of the first post." {
+    t.Fatalf("got stored posts %+v, want the 2 imported posts added", stored)
+  }
+  if stored[3].ID != "10" || stored[3].Slug != "first-post-2" || stored[4].ID == "" {
+    t.Errorf("got imported posts %+v and %+v", stored[3], stored[4])
+  }
+
+  // Importing the same export again only adds the post without an ID.
+  w = serve("POST /posts/import-url", requireAdmin(importURL), importRequest(server.URL+"/export.json"))
+  if summary := decode[importSummary](t, w); summary.Imported != 1 || len(summary.Skipped) != 2 {
+    t.Errorf("second import: got %+v, want 1 imported and 2 skipped", summary)
+  }
+
+  if w := serve("POST /posts/import-url", requireAdmin(importURL), httptest.NewRequest(http.MethodPost, "/posts/import-url", strings.NewReader(`{"url": "`+server.URL+`"}`))); w.Code != http.StatusUnauthorized {
+    t.Errorf("without a token: got status %d, want %d", w.Code, http.StatusUnauthorized)
+  }
+}
+
+func TestImportURLFailures(t *testing.T) {
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    switch r.URL.Path {
+    case "/missing":
+      http.NotFound(w, r)
+    case "/slow":
+      time.Sleep(200 * time.Millisecond)
+      w.Write([]byte(`[]`))
+    case "/large":
+      w.Write([]byte(`[{"Title": "` + strings.Repeat("a", 2000) + `"}]`))
+    case "/html":
+      w.Write([]byte(`<html></html>`))
+    case "/invalid":
+      w.Write([]byte(`[{"ID": "10", "Title": "No content", "Author": "Ann Lee"}]`))
+    }
+  }))
+  defer server.Close()
+
+  tests := []struct {
+    path   string
+    status int
+  }{
+    {"/missing", http.StatusBadGateway},
+    {"/slow", http.StatusBadGateway},
+    {"/large", http.StatusBadGateway},
+    {"/html", http.StatusBadGateway},
+    {"/invalid", http.StatusUnprocessableEntity},
+  }
+  for _, test := range tests {
+    t.Run(test.path, func(t *testing.T) {
+      setup(t, testPosts()...)
+      config.AdminToken = testAdminToken
+      config.ImportTimeout.Duration = 50 * time.Millisecond
+      config.ImportMaxBytes = 1000
+
+      w := serve("POST /posts/import-url", requireAdmin(importURL), importRequest(server.URL+test.path))
+      if w.Code != test.status {
+        t.Fatalf("got status %d, want %d: %s", w.Code, test.status, w.Body)
+      }
+      if got := storedPosts(t); len(got) != 3 {
+        t.Errorf("got %d stored posts, want the 3 we had", len(got))
+      }
+    })
+  }
+
+  setup(t)
+  config.AdminToken = testAdminToken
+  if w := serve("POST /posts/import-url", requireAdmin(importURL), importRequest("file:///etc/passwd")); w.Code != http.StatusBadRequest {
+    t.Errorf("not an http URL: got status %d, want %d", w.Code, http.StatusBadRequest)
+  }
+}
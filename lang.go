@@ -0,0 +1,29 @@
+package main
+
+import (
+  "fmt"
+  "slices"
+  "strings"
+)
+
+/*
+  LANGUAGES
+
+  A bilingual blog lists its languages in LOCALES, e.g. "en,fr". Posts say which one they're written in with Lang, and index can list the posts of one language with ?lang=fr.
+
+  Posts without a Lang, like the ones created before the field existed, are in the primary language: the first of the Locales.
+*/
+func validateLang(lang string) error {
+  if lang != "" && !slices.Contains(config.Locales, lang) {
+    return fmt.Errorf("Lang must be one of %s, got %q", strings.Join(config.Locales, ", "), lang)
+  }
+  return nil
+}
+
+// language returns the language of the post, the primary language when it has none.
+func (post *Post) language() string {
+  if post.Lang == "" {
+    return config.Locales[0]
+  }
+  return post.Lang
+}
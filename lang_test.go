@@ -0,0 +1,40 @@
+package main
+
+import (
+  "net/http"
+  "slices"
+  "testing"
+)
+
+func TestLang(t *testing.T) {
+  setup(t)
+  config.Locales = []string{"en", "fr"}
+
+  for _, post := range []Post{
+    {Title: "Hello", Content: "In English", Author: "Jane Doe"},
+    {Title: "Bonjour", Content: "En français", Author: "Jane Doe", Lang: "fr"},
+  } {
+    w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", post))
+    if w.Code != http.StatusCreated {
+      t.Fatalf("%s: got status %d, want %d: %s", post.Title, w.Code, http.StatusCreated, w.Body)
+    }
+  }
+  if got := storedPosts(t)[0].Lang; got != "en" {
+    t.Errorf("without a Lang: got %q, want the primary language", got)
+  }
+
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "Hallo", Content: "Auf Deutsch", Author: "Jane Doe", Lang: "de"}))
+  if w.Code != http.StatusUnprocessableEntity {
+    t.Errorf("unknown Lang: got status %d, want %d", w.Code, http.StatusUnprocessableEntity)
+  }
+
+  if got := listedIDs(t, "?lang=fr&sort=file"); !slices.Equal(got, []PostID{"2"}) {
+    t.Errorf("lang=fr: got %v, want [2]", got)
+  }
+  if got := listedIDs(t, "?lang=en&sort=file"); !slices.Equal(got, []PostID{"1"}) {
+    t.Errorf("lang=en: got %v, want [1]", got)
+  }
+  if w := listPosts("?lang=de"); w.Code != http.StatusBadRequest {
+    t.Errorf("lang=de: got status %d, want %d", w.Code, http.StatusBadRequest)
+  }
+}
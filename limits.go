@@ -0,0 +1,62 @@
+package main
+
+import (
+  "net/http"
+)
+
+/*
+  REQUEST LIMITS
+
+  Some bots send huge query strings or piles of headers. The server already refuses requests whose headers go over 1MB, limitRequestSize is stricter (and configurable) and answers with 431 Request Header Fields Too Large before any handler parses the query:
+
+  MAX_URL_LENGTH    the length of the path and query string, 8192 by default
+  MAX_HEADER_BYTES  the size of all the headers together, 65536 by default
+*/
+func limitRequestSize(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if len(r.URL.RequestURI()) > config.MaxURLLength {
+      jsonError(w, http.StatusRequestHeaderFieldsTooLarge, "URL too long")
+      return
+    }
+    if headerSize(r.Header) > config.MaxHeaderBytes {
+      jsonError(w, http.StatusRequestHeaderFieldsTooLarge, "request headers too large")
+      return
+    }
+    next.ServeHTTP(w, r)
+  })
+}
+
+// headerSize returns the size of the headers as they were sent, one "Name: value\r\n" line per value.
+func headerSize(header http.Header) int {
+  size := 0
+  for name, values := range header {
+    for _, value := range values {
+      size += len(name) + len(": ") + len(value) + len("\r\n")
+    }
+  }
+  return size
+}
+
+/*
+  CONCURRENCY LIMIT
+
+  Every request loads the posts in memory, so too many of them at once can run a small server out of memory. With MAX_CONCURRENT_REQUESTS set, the requests over the limit are turned away with 503 and a Retry-After header instead of piling up.
+
+  A buffered channel makes a simple semaphore: sending takes one of its slots and blocks when they're all taken, receiving frees one. The select with a default case tries to take a slot without waiting.
+*/
+func limitConcurrency(next http.Handler, limit int) http.Handler {
+  if limit <= 0 {
+    return next
+  }
+  slots := make(chan struct{}, limit)
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    select {
+    case slots <- struct{}{}:
+      defer func() { <-slots }()
+      next.ServeHTTP(w, r)
+    default:
+      w.Header().Set("Retry-After", "1")
+      jsonError(w, http.StatusServiceUnavailable, "too many requests in progress, try again shortly")
+    }
+  })
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func TestLimitRequestSize(t *testing.T) {
+  setup(t, testPosts()...)
+  mux := http.NewServeMux()
+  mux.HandleFunc("/index", index)
+  handler := limitRequestSize(mux)
+
+  tests := []struct {
+    name   string
+    target string
+    header string
+    status int
+  }{
+    {"ordinary request", "/index?sort=views", "", http.StatusOK},
+    {"oversized query string", "/index?tags=" + strings.Repeat("a", config.MaxURLLength), "", http.StatusRequestHeaderFieldsTooLarge},
+    {"oversized headers", "/index", strings.Repeat("a", config.MaxHeaderBytes), http.StatusRequestHeaderFieldsTooLarge},
+  }
+  for _, test := range tests {
+    r := httptest.NewRequest(http.MethodGet, test.target, nil)
+    if test.header != "" {
+      r.Header.Set("X-Padding", test.header)
+    }
+    w := httptest.NewRecorder()
+    handler.ServeHTTP(w, r)
+    if w.Code != test.status {
+      t.Errorf("%s: got status %d, want %d", test.name, w.Code, test.status)
+    }
+    if test.status != http.StatusOK && decode[map[string]string](t, w)["error"] == "" {
+      t.Errorf("%s: got no error message", test.name)
+    }
+  }
+}
+
+func TestLimitConcurrency(t *testing.T) {
+  started, release := make(chan struct{}), make(chan struct{})
+  slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    started <- struct{}{}
+    <-release
+  })
+  handler := limitConcurrency(slow, 2)
+
+  done := make(chan int)
+  for range 2 {
+    go func() {
+      w := httptest.NewRecorder()
+      handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/index", nil))
+      done <- w.Code
+    }()
+    <-started
+  }
+
+  w := httptest.NewRecorder()
+  handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/index", nil))
+  if w.Code != http.StatusServiceUnavailable {
+    t.Errorf("saturated: got status %d, want %d", w.Code, http.StatusServiceUnavailable)
+  }
+  if got := w.Header().Get("Retry-After"); got != "1" {
+    t.Errorf("saturated: got Retry-After %q, want 1", got)
+  }
+
+  close(release)
+  for range 2 {
+    if code := <-done; code != http.StatusOK {
+      t.Errorf("within the limit: got status %d, want %d", code, http.StatusOK)
+    }
+  }
+  // The slots are free again.
+  go func() { <-started }()
+  w = httptest.NewRecorder()
+  handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/index", nil))
+  if w.Code != http.StatusOK {
+    t.Errorf("after the others finished: got status %d, want %d", w.Code, http.StatusOK)
+  }
+}
+
+func TestNoConcurrencyLimit(t *testing.T) {
+  started, release := make(chan struct{}), make(chan struct{})
+  handler := limitConcurrency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    started <- struct{}{}
+    <-release
+  }), 0)
+
+  done := make(chan int)
+  for range 5 {
+    go func() {
+      w := httptest.NewRecorder()
+      handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/index", nil))
+      done <- w.Code
+    }()
+    // Every request gets in while the others are still running.
+    <-started
+  }
+  close(release)
+  for range 5 {
+    if code := <-done; code != http.StatusOK {
+      t.Errorf("got status %d, want %d", code, http.StatusOK)
+    }
+  }
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+  "net/url"
+  "sort"
+  "strconv"
+  "strings"
+  "time"
+)
+
+/*
+  LISTING
+
+  index takes a few query parameters to narrow down and order the list:
+
+  ?min_views=10   only posts viewed at least 10 times
+  ?lang=fr        only posts written in French, fr must be one of the configured Locales
+  ?contributor=Jane Doe  only posts Jane Doe wrote or co-wrote, see authors.go
+  ?tags=go,web    only posts tagged go or web, or both with ?tag_mode=all. Case doesn't matter.
+  ?sort=views     sorts by ViewCount, also shares, created_at, title, order or file (the order of the posts file). A direction can be added as in sort=views:asc, the default is desc except for title and order.
+  ?rank=hybrid    sorts by a blend of recency and popularity instead, see rank.go.
+  ?limit=20       lists 20 posts at most, starting after the first offset ones with ?offset=40. Without a limit the posts_per_page site setting applies, see settings.go.
+
+  ?facets=tags    counts the posts of each tag next to the list, see facets.go.
+
+  Deleted posts are only listed with includeDeleted, they're marked with "Deleted": true.
+
+  Without a sort the posts are sorted by DEFAULT_SORT, order by default: posts with an Order set come first, lowest first, then the newest ones. Posts that still compare equal keep the order they have in the file. Hidden and draft posts are never listed.
+*/
+// listingParams are the query parameters of index.
+var listingParams = []string{"min_views", "lang", "contributor", "tags", "tag_mode", "sort", "rank", "limit", "offset", "include_deleted", "facets"}
+
+func selectPosts(query url.Values, posts []Post, includeDeleted bool) ([]int, error) {
+  listing, err := parseListing(query, includeDeleted)
+  if err != nil {
+    return nil, err
+  }
+
+  selected := []int{}
+  for i := range posts {
+    if listing.keep(&posts[i]) {
+      selected = append(selected, i)
+    }
+  }
+
+  if listing.hybrid {
+    scores := hybridScores(posts, selected, time.Now())
+    // The scores follow the posts around while they're sorted.
+    sort.Stable(byScore{selected, scores})
+  } else {
+    // SliceStable keeps posts that compare equal in file order.
+    sort.SliceStable(selected, func(a, b int) bool {
+      return listing.less(&posts[selected[a]], &posts[selected[b]])
+    })
+  }
+  return page(listing, selected), nil
+}
+
+// listing is what the query string asks index for: which posts, in which order and which page of them.
+type listing struct {
+  keep   func(post *Post) bool
+  less   func(a, b *Post) bool
+  hybrid bool
+  // limit is 0 when the query doesn't ask for a page.
+  limit, offset int
+}
+
+func parseListing(query url.Values, includeDeleted bool) (listing, error) {
+  var minViews int64
+  if value := query.Get("min_views"); value != "" {
+    n, err := strconv.ParseInt(value, 10, 64)
+    if err != nil || n < 0 {
+      return listing{}, errors.New("min_views must be a number greater than or equal to 0")
+    }
+    minViews = n
+  }
+  lang := query.Get("lang")
+  if lang != "" {
+    if err := validateLang(lang); err != nil {
+      return listing{}, err
+    }
+  }
+
+  contributor := strings.TrimSpace(query.Get("contributor"))
+  tags := normalizeTags(strings.Split(query.Get("tags"), ","))
+  tagMode := query.Get("tag_mode")
+  if tagMode == "" {
+    tagMode = "any"
+  }
+  if tagMode != "any" && tagMode != "all" {
+    return listing{}, fmt.Errorf("unknown tag_mode %q, use any or all", tagMode)
+  }
+
+  var l listing
+  l.keep = func(post *Post) bool {
+    if post.Hidden || post.Draft || (post.Deleted && !includeDeleted) || post.ViewCount < minViews {
+      return false
+    }
+    if contributor != "" && !post.writtenBy(contributor) {
+      return false
+    }
+    if len(tags) > 0 && !post.hasTags(tags, tagMode == "all") {
+      return false
+    }
+    return lang == "" || post.language() == lang
+  }
+
+  spec := query.Get("sort")
+  switch rank := query.Get("rank"); rank {
+  case "":
+  case "hybrid":
+    if spec != "" {
+      return listing{}, errors.New("rank and sort can't be used together")
+    }
+    l.hybrid = true
+  default:
+    return listing{}, fmt.Errorf("unknown rank %q, use hybrid", rank)
+  }
+  if !l.hybrid {
+    if spec == "" {
+      spec = config.DefaultSort
+    }
+    var err error
+    if l.less, err = sortOrder(spec); err != nil {
+      return listing{}, err
+    }
+  }
+
+  l.limit = currentSettings().PostsPerPage
+  for _, param := range []struct {
+    name  string
+    value *int
+    min   int
+  }{{"limit", &l.limit, 1}, {"offset", &l.offset, 0}} {
+    if value := query.Get(param.name); value != "" {
+      n, err := strconv.Atoi(value)
+      if err != nil || n < param.min {
+        return listing{}, fmt.Errorf("%s must be a number greater than or equal to %d", param.name, param.min)
+      }
+      *param.value = n
+    }
+  }
+  return l, nil
+}
+
+// page returns the part of the sorted posts asked for by limit and offset. It's generic: T stands for any type, so it pages the positions returned by selectPosts as well as the posts of streamPosts.
+func page[T any](l listing, sorted []T) []T {
+  start := min(l.offset, len(sorted))
+  sorted = sorted[start:]
+  if l.limit > 0 && l.limit < len(sorted) {
+    sorted = sorted[:l.limit]
+  }
+  return sorted
+}
+
+/*
+  sortOrder turns a sort spec like "views:desc" into a function telling whether a post goes before another one. Functions are values in Go, so they can be returned like anything else.
+*/
+func sortOrder(spec string) (func(a, b *Post) bool, error) {
+  field, direction, _ := strings.Cut(spec, ":")
+
+  var less func(a, b *Post) bool
+  descending := true
+  switch field {
+  case "views":
+    less = func(a, b *Post) bool { return a.ViewCount < b.ViewCount }
+  case "created_at":
+    less = func(a, b *Post) bool {
+      ta, okA := parseTimestamp(a.CreatedAt)
+      tb, okB := parseTimestamp(b.CreatedAt)
+      if okA != okB {
+        // Posts without a valid date are always listed last, whatever the direction.
+        return okA != descending
+      }
+      return ta.Before(tb)
+    }
+  case "shares":
+    less = func(a, b *Post) bool { return a.Shares < b.Shares }
+  case "title":
+    less = func(a, b *Post) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+    descending = false
+  case "order":
+    newest, _ := sortOrder("created_at")
+    less = func(a, b *Post) bool {
+      if a.Order != b.Order {
+        // 0 means no Order was set, those posts go after the ordered ones.
+        return b.Order == 0 || (a.Order != 0 && a.Order < b.Order)
+      }
+      return newest(a, b)
+    }
+    descending = false
+  case "file":
+    // No post goes before another one, SliceStable leaves them in file order.
+    less = func(a, b *Post) bool { return false }
+    descending = false
+  default:
+    return nil, fmt.Errorf("can't sort by %q, use views, shares, created_at, title, order or file", field)
+  }
+
+  switch direction {
+  case "":
+  case "asc":
+    descending = false
+  case "desc":
+    descending = true
+  default:
+    return nil, fmt.Errorf("sort direction must be asc or desc, got %q", direction)
+  }
+
+  if descending {
+    return func(a, b *Post) bool { return less(b, a) }, nil
+  }
+  return less, nil
+}
+
+// byScore sorts the selected posts by score, highest first. It implements sort.Interface: Len, Less and Swap.
+type byScore struct {
+  selected []int
+  scores   []float64
+}
+
+func (s byScore) Len() int           { return len(s.selected) }
+func (s byScore) Less(a, b int) bool { return s.scores[a] > s.scores[b] }
+func (s byScore) Swap(a, b int) {
+  s.selected[a], s.selected[b] = s.selected[b], s.selected[a]
+  s.scores[a], s.scores[b] = s.scores[b], s.scores[a]
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "path/filepath"
+  "slices"
+  "strings"
+  "testing"
+  "time"
+)
+
+// listPosts lists the posts for the query and returns the response.
+func listPosts(query string) *httptest.ResponseRecorder {
+  return serve("/index", index, httptest.NewRequest(http.MethodGet, "/index"+query, nil))
+}
+
+func TestMinViews(t *testing.T) {
+  posts := testPosts()
+  posts[0].ViewCount = 5
+  posts[1].ViewCount = 50
+  posts[2].ViewCount = 10
+  setup(t, posts...)
+
+  w := listPosts("?min_views=10&sort=views")
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  got := decode[[]Post](t, w)
+  if len(got) != 2 || got[0].ID != "2" || got[1].ID != "3" {
+    t.Errorf("got %+v, want posts 2 and 3, most viewed first", got)
+  }
+
+  for _, value := range []string{"-1", "ten"} {
+    if w := listPosts("?min_views=" + value); w.Code != http.StatusBadRequest {
+      t.Errorf("min_views=%s: got status %d, want %d", value, w.Code, http.StatusBadRequest)
+    }
+  }
+}
+
+func TestEmptyListMessage(t *testing.T) {
+  setup(t)
+
+  if w := listPosts(""); strings.TrimSpace(w.Body.String()) != "[]" {
+    t.Errorf("by default: got %s, want []", w.Body)
+  }
+
+  config.EmptyListMessage = "no posts yet"
+  w := listPosts("")
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  got := decode[struct {
+    Data    []Post
+    Message string
+  }](t, w)
+  if got.Data == nil || len(got.Data) != 0 || got.Message != "no posts yet" {
+    t.Errorf("got %s, want an empty data array and the message", w.Body)
+  }
+
+  // A list with posts stays a bare array.
+  setup(t, testPosts()...)
+  config.EmptyListMessage = "no posts yet"
+  if got := decode[[]Post](t, listPosts("")); len(got) != 3 {
+    t.Errorf("with posts: got %d posts, want 3", len(got))
+  }
+}
+
+func TestDefaultSort(t *testing.T) {
+  posts := testPosts()
+  posts[0].ViewCount = 20
+  posts[1].ViewCount = 30
+  posts[2].ViewCount = 10
+  tests := []struct {
+    defaultSort string
+    query       string
+    want        []PostID
+  }{
+    {"order", "", []PostID{"3", "2", "1"}},
+    {"file", "", []PostID{"1", "2", "3"}},
+    {"views", "", []PostID{"2", "1", "3"}},
+    {"created_at:asc", "", []PostID{"1", "2", "3"}},
+    // The query still wins.
+    {"views", "?sort=title:desc", []PostID{"3", "2", "1"}},
+  }
+  for _, test := range tests {
+    setup(t, posts...)
+    config.DefaultSort = test.defaultSort
+    if got := listedIDs(t, test.query); !slices.Equal(got, test.want) {
+      t.Errorf("DEFAULT_SORT=%s%s: got %v, want %v", test.defaultSort, test.query, got, test.want)
+    }
+  }
+
+  t.Setenv("DEFAULT_SORT", "popularity")
+  if _, err := loadConfig(""); err == nil || !strings.Contains(err.Error(), `can't sort by "popularity"`) {
+    t.Errorf("invalid DEFAULT_SORT: got error %v", err)
+  }
+}
+
+func TestIndexLastModified(t *testing.T) {
+  posts := append(testPosts(), Post{ID: "4", Title: "Hidden post", Content: "Hidden", Author: "Jane Doe", CreatedAt: "2025-01-04T10:00:00Z", UpdatedAt: "2025-06-01T10:00:00Z", Hidden: true})
+  posts[0].UpdatedAt = "2025-02-01T10:00:00Z"
+  setup(t, posts...)
+  conditional := func(since string) *httptest.ResponseRecorder {
+    r := httptest.NewRequest(http.MethodGet, "/index", nil)
+    r.Header.Set("If-Modified-Since", since)
+    return serve("/index", index, r)
+  }
+
+  // The newest listed post is the updated one, the hidden post isn't listed.
+  const newest = "Sat, 01 Feb 2025 10:00:00 GMT"
+  if w := conditional("Fri, 31 Jan 2025 10:00:00 GMT"); w.Code != http.StatusOK || w.Header().Get("Last-Modified") != newest {
+    t.Errorf("client's copy is older: got status %d and Last-Modified %q, want %d and %q", w.Code, w.Header().Get("Last-Modified"), http.StatusOK, newest)
+  }
+  if views, _ := viewBuffer.unsaved(); views != 3 {
+    t.Fatalf("got %d buffered views, want 3", views)
+  }
+  w := conditional(newest)
+  if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("Last-Modified") != newest {
+    t.Errorf("client's copy is current: got status %d and Last-Modified %q, want %d and %q", w.Code, w.Header().Get("Last-Modified"), http.StatusNotModified, newest)
+  }
+  // Views don't modify the posts, and the 304 isn't a view.
+  if views, _ := viewBuffer.unsaved(); views != 3 {
+    t.Errorf("got %d buffered views, want 3", views)
+  }
+
+  w = serve("PATCH /posts/{id}", patchPost, patchRequest(`[{"op": "replace", "path": "/Title", "value": "Edited"}]`))
+  if w.Code != http.StatusOK {
+    t.Fatalf("patch: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if w := conditional(newest); w.Code != http.StatusOK {
+    t.Errorf("after an edit: got status %d, want %d", w.Code, http.StatusOK)
+  }
+}
+
+func TestEmptyIndexLastModifiedIsTheFiles(t *testing.T) {
+  setup(t)
+  path := useFileStore(t, `[]`)
+  modified := time.Date(2025, 6, 4, 10, 0, 0, 0, time.UTC)
+  if err := os.Chtimes(path, modified, modified); err != nil {
+    t.Fatal(err)
+  }
+  if got := listPosts("").Header().Get("Last-Modified"); got != "Wed, 04 Jun 2025 10:00:00 GMT" {
+    t.Errorf("posts file: got Last-Modified %q, want Wed, 04 Jun 2025 10:00:00 GMT", got)
+  }
+
+  dir := t.TempDir()
+  config.PostsDir = dir
+  storage = newStore(config)
+  created := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+  if err := os.Chtimes(dir, created, created); err != nil {
+    t.Fatal(err)
+  }
+  if got := listPosts("").Header().Get("Last-Modified"); got != "Wed, 01 Jan 2025 10:00:00 GMT" {
+    t.Errorf("empty posts directory: got Last-Modified %q, want Wed, 01 Jan 2025 10:00:00 GMT", got)
+  }
+
+  // A hidden post edited in place changes its file, not the directory.
+  post := filepath.Join(dir, "1.json")
+  if err := os.WriteFile(post, []byte(`{"ID": "1", "Title": "Hidden post", "Content": "Hidden", "Author": "Jane Doe", "Hidden": true}`), 0644); err != nil {
+    t.Fatal(err)
+  }
+  if err := os.Chtimes(post, modified, modified); err != nil {
+    t.Fatal(err)
+  }
+  if err := os.Chtimes(dir, created, created); err != nil {
+    t.Fatal(err)
+  }
+  if got := listPosts("").Header().Get("Last-Modified"); got != "Wed, 04 Jun 2025 10:00:00 GMT" {
+    t.Errorf("posts directory: got Last-Modified %q, want Wed, 04 Jun 2025 10:00:00 GMT", got)
+  }
+}
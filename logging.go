@@ -0,0 +1,34 @@
+package main
+
+import (
+  "log/slog"
+  "net/http"
+  "time"
+)
+
+/*
+  REQUEST LOGGING
+
+  Logging every request buries the interesting ones, so logRequests only warns about the requests that took longer than SLOW_THRESHOLD (1s by default, 0 turns it off):
+
+  2025/06/04 10:12:03 WARN slow request method=POST path=/create status=201 duration=1.204s
+
+  The other requests are logged at the DEBUG level, which is only shown with LOG_LEVEL=debug.
+
+  log/slog is the structured logger of the standard library: each message comes with key value pairs that log processors can filter on. Its default handler writes through the log package, so these lines look like the rest of our logs.
+*/
+func logRequests(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    start := time.Now()
+    recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+    next.ServeHTTP(recorder, r)
+    duration := time.Since(start)
+
+    attributes := []any{"method", r.Method, "path", r.URL.Path, "status", recorder.status, "duration", duration}
+    if threshold := config.SlowThreshold.Duration; threshold > 0 && duration > threshold {
+      slog.Warn("slow request", attributes...)
+      return
+    }
+    slog.Debug("request", attributes...)
+  })
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+  "bytes"
+  "log/slog"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestSlowRequestsAreLogged(t *testing.T) {
+  setup(t)
+  config.SlowThreshold = Duration{20 * time.Millisecond}
+
+  var logs bytes.Buffer
+  previous := slog.Default()
+  slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+  defer slog.SetDefault(previous)
+
+  handler := logRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if r.URL.Path == "/slow" {
+      time.Sleep(50 * time.Millisecond)
+    }
+    w.WriteHeader(http.StatusCreated)
+  }))
+
+  handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/slow", nil))
+  line := logs.String()
+  if !strings.Contains(line, "level=WARN") || !strings.Contains(line, `msg="slow request"`) || !strings.Contains(line, "path=/slow") || !strings.Contains(line, "status=201") || !strings.Contains(line, "duration=") {
+    t.Errorf("slow: got %q, want a warning with the path, status and duration", line)
+  }
+
+  logs.Reset()
+  handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+  if line := logs.String(); !strings.Contains(line, "level=DEBUG") || !strings.Contains(line, "path=/fast") {
+    t.Errorf("fast: got %q, want a debug line", line)
+  }
+}
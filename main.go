@@ -29,12 +29,21 @@ package main
   We can import packages from the standard library. IDE support for Go is usually very robust, that and the fact that the language is statically type means that you can hover the package to read their description. You can also check the online documentation by right cmd+click into it.
 */
 import (
+  "context"
   "encoding/json"
+  "errors"
   "fmt"
-  "io"
+  "log"
+  "log/slog"
   "net/http"
   "os"
+  "os/signal"
+  "strconv"
+  "strings"
+  "sync"
+  "syscall"
   "time"
+  "unicode/utf8"
 )
 
 /*
@@ -49,12 +58,48 @@ import (
 */
 
 type Post struct {
+  ID         PostID `json:"ID"`
   Title      string `json:"Title"`
   Content    string `json:"Content"`
   CreatedAt  string `json:"CreatedAt"`
   Author     string `json:"Author"`
-  ViewCount  int    `json:"ViewCount"`
+  ViewCount  int64  `json:"ViewCount"`
   LastViewed string `json:"LastViewed"`
+  Shares     int64  `json:"Shares,omitempty"`
+  UpdatedAt  string `json:"UpdatedAt,omitempty"`
+  Hidden     bool   `json:"Hidden,omitempty"`
+  // CoAuthors are the other people who wrote the post, see authors.go.
+  CoAuthors []string `json:"CoAuthors,omitempty"`
+  // OriginalCreatedAt is the CreatedAt of a post before it was first bumped, see bump.go.
+  OriginalCreatedAt string `json:"OriginalCreatedAt,omitempty"`
+  // Draft posts aren't published yet, they're left out of the public lists like hidden posts.
+  Draft bool `json:"Draft,omitempty"`
+  // Slug names the post in URLs, e.g. "my-first-post", see slug.go.
+  Slug string `json:"Slug,omitempty"`
+  // Lang is the language the post is written in, one of the configured Locales, see lang.go.
+  Lang string `json:"Lang,omitempty"`
+  // FeatureImage is the URL of the cover image of the post, see image.go.
+  FeatureImage string `json:"FeatureImage,omitempty"`
+  // Tags are free form labels, see tags.go.
+  Tags []string `json:"Tags,omitempty"`
+  // Order places the post in the index, lower first. 0 means no order was set, see order.go.
+  Order int `json:"Order,omitempty"`
+  // Deleted posts stay in the file until /admin/compact purges them, see delete.go.
+  Deleted bool `json:"Deleted,omitempty"`
+  // Comments are what readers wrote about the post, PinnedCommentID the one listed first, see comments.go.
+  Comments        []Comment `json:"Comments,omitempty"`
+  PinnedCommentID int       `json:"PinnedCommentID,omitempty"`
+  // CommentsEnabled is a pointer so that posts that don't have it, nil, keep their comments open. See comments.go.
+  CommentsEnabled *bool `json:"CommentsEnabled,omitempty"`
+  // TrackViews false stops counting the views of the post, see views.go. Like CommentsEnabled, nil means true.
+  TrackViews *bool `json:"TrackViews,omitempty"`
+  // WordCount is only set in responses, see wordcount.go.
+  WordCount *int `json:"WordCount,omitempty"`
+  // Revisions are the previous versions of the post, see history.go.
+  Revisions []Revision `json:"Revisions,omitempty"`
+
+  // overlay is what loadPost added to the stored views, see views.go. Unexported fields are left out of the JSON.
+  overlay *viewOverlay
 }
 
 /*
@@ -72,24 +117,102 @@ type Post struct {
   We'll talk more about the usage of pointers in GO later in this tutorial, for now take a look at the corresponding post functions below.
 */
 func (post *Post) increaseViewCount() {
-  post.ViewCount += 1
+  // The counters can't wrap around, see counters.go.
+  post.ViewCount = saturatingAdd(post.ViewCount, 1)
 }
 
+// Timestamps are stored in UTC and shown in the configured time zone, see timezone.go.
 func (post *Post) setLastViewed() {
-  post.LastViewed = time.Now().Format("2006-01-02")
+  post.LastViewed = time.Now().UTC().Format(time.RFC3339)
 }
 
 func (post *Post) setCreatedAt() {
-  post.CreatedAt = time.Now().Format("2006-01-02")
+  post.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+}
+
+func (post *Post) setUpdatedAt() {
+  post.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+}
+
+// lastModified is when the post last changed: its UpdatedAt, or its CreatedAt for posts that were never updated. modifiedAt is that timestamp as stored.
+func (post *Post) lastModified() (time.Time, bool) {
+  return parseTimestamp(post.modifiedAt())
+}
+
+func (post *Post) modifiedAt() string {
+  if _, ok := parseTimestamp(post.UpdatedAt); ok {
+    return post.UpdatedAt
+  }
+  return post.CreatedAt
+}
+
+/*
+  Methods can return values too. fieldErrors checks the fields every post must have, its co-authors, its slug, its feature image and its tags, and returns every problem found, in the order of the fields, or none when the post is fine.
+*/
+type fieldError struct {
+  Field string
+  Err   error
+}
+
+func (post *Post) fieldErrors() []fieldError {
+  errs := []fieldError{}
+  check := func(field string, err error) {
+    if err != nil {
+      errs = append(errs, fieldError{field, err})
+    }
+  }
+  if post.Title == "" {
+    check("Title", errors.New("Title is required"))
+  }
+  if post.Content == "" {
+    check("Content", errors.New("Content is required"))
+  }
+  if post.Author == "" {
+    check("Author", errors.New("Author is required"))
+  }
+  check("CoAuthors", validateCoAuthors(post.CoAuthors))
+  check("Lang", validateLang(post.Lang))
+  if post.Slug != "" {
+    check("Slug", validateSlug(post.Slug))
+  }
+  check("FeatureImage", validateFeatureImage(post.FeatureImage))
+  check("Tags", validateTags(post.Tags))
+  return errs
+}
+
+/*
+  contentErrors adds the checks of what clients write to fieldErrors: the profanity filter, see profanity.go, and MIN_CONTENT_LEN. With PROFANITY_MODE=mask it masks the Title and the Content rather than reporting them. Every route that takes a Title or a Content from a client goes through it.
+*/
+func (post *Post) contentErrors() []fieldError {
+  errs := post.fieldErrors()
+  switch config.ProfanityMode {
+  case profanityMask:
+    post.Title = maskProfanity(post.Title)
+    post.Content = maskProfanity(post.Content)
+  case profanityReject:
+    for _, field := range []struct{ name, value string }{{"Title", post.Title}, {"Content", post.Content}} {
+      if hasProfanity(field.value) {
+        errs = append(errs, fieldError{field.name, fmt.Errorf("%s uses words that aren't allowed", field.name)})
+      }
+    }
+  }
+  // MIN_CONTENT_LEN counts characters, not bytes, and padding the Content with spaces doesn't help.
+  if length := utf8.RuneCountInString(strings.TrimSpace(post.Content)); post.Content != "" && length < config.MinContentLength {
+    errs = append(errs, fieldError{"Content", fmt.Errorf("Content must be at least %d characters long, got %d", config.MinContentLength, length)})
+  }
+  return errs
 }
 
 /*
   GLOBAL PACKAGE VARIABLES
 
-  This is a global variable and will be available in all functions within this package.
+  These are global variables and will be available in all functions within this package. config holds the settings of the service, see config.go. main replaces the defaults with the loaded config before it starts serving requests.
+
+  storage is where the posts are kept, see store.go.
 */
 var (
-  filePath string = "posts.json"
+  config  Config = defaultConfig()
+  storage Store
 )
 
 /*
@@ -99,19 +222,218 @@ var (
 */
 func main() {
   /*
-    The simplest way to setup a web server is by using the http.HandleFunc which takes in a path and a handler function for that particular request. In our case we'll have three different routes one for every feature we'll be supporting:
+    The os package gives us access to the environment. log.Fatal prints the error and exits the program, there's no point in starting with a broken config.
+  */
+  loaded, err := loadConfig(os.Getenv("CONFIG_FILE"))
+  if err != nil {
+    log.Fatalf("Invalid config: %v", err)
+  }
+  config = loaded
+  slog.SetLogLoggerLevel(config.LogLevel)
+  viewDebouncer.window = config.ViewDebounce.Duration
+  createCooldown.window = config.CreateCooldown.Duration
+  storage = newStore(config)
+  // The posts directory is created right away, readyz checks it can be written to.
+  if config.PostsDir != "" {
+    if err := os.MkdirAll(config.PostsDir, 0755); err != nil {
+      log.Fatalf("Error creating %s: %v", config.PostsDir, err)
+    }
+  }
+  ids = newIDGenerator(config)
+  shutdownTracing, err := setupTracing(context.Background(), config.TracingEndpoint)
+  if err != nil {
+    log.Fatalf("Error setting up tracing: %v", err)
+  }
+  defer shutdownTracing(context.Background())
+
+  if config.EncryptionKey != "" {
+    // The key was already checked by config.validate, so this can't fail.
+    contentCipher, _ = newContentCipher(config.EncryptionKey)
+  }
+
+  if config.ViewLog != "" {
+    replayed, writes, err := viewBuffer.useLog(config.ViewLog)
+    if err != nil {
+      log.Fatal(err)
+    }
+    if replayed > 0 || writes > 0 {
+      log.Printf("Replayed %d views and %d creates and shares from %s", replayed, writes, config.ViewLog)
+    }
+  }
+  if err := loadSettings(); err != nil {
+    log.Fatalf("Error loading settings: %v", err)
+  }
+
+  // Posts sharing an ID are dealt with before the self-check reports them, see duplicateids.go. Only DUPLICATE_IDS=fail stops the service, posts that can't be loaded are left to the self-check and STRICT_STARTUP.
+  if _, err := resolveDuplicateIDs(context.Background()); errors.Is(err, errSharedIDs) {
+    log.Fatal(err)
+  } else if err != nil {
+    log.Printf("Error checking the posts for shared IDs: %v", err)
+  }
+  if err := startupCheck(context.Background()); err != nil {
+    if config.StrictStartup {
+      log.Fatalf("Self-check failed: %v", err)
+    }
+    log.Printf("Self-check failed, starting anyway: %v", err)
+  }
+
+  /*
+    The simplest way to setup a web server is by using the http.HandleFunc which takes in a path and a handler function for that particular request. In our case we'll have a route for every feature we'll be supporting:
     - List Posts
+    - Show a Post
+    - IDs and slugs of the Posts
+    - Whether a slug is available
+    - Posts modified since a given time
     - Create a Post
+    - Preview a Post before creating it
+    - Update a Post (JSON Patch)
+    - Compare revisions of a Post
+    - Update many Posts at once
+    - Reorder Posts
+    - Set the manual order of a Post
+    - Hide or show a Post
+    - Duplicate a Post
+    - Bump a Post back to the top
+    - Open Graph metadata of a Post
+    - Permalink of a Post
+    - Newer and older neighbors of a Post
+    - Add the views counted by a client to several Posts
+    - Count shares of a Post
+    - Count a view of a Post and rank it, or get its rank by views
+    - Comment on a Post, pin a comment and close the comments
+    - Restore a Post from the latest backup (admin only)
+    - Delete a Post
+    - Import Posts from another instance (admin only)
+    - Archive of posts by month
+    - Most used words
+    - Author suggestions
+    - Featured authors
+    - RSS feed
+    - Sitemap
+    - Export of all the Posts as JSON, gzipped JSON or CSV
+    - Maintenance of the posts file (admin only)
+    - Dashboard summing up the posts (admin only)
+    - Validation of a candidate posts file
+    - JSON Schema of a Post
+    - Site settings
+    - Readiness check
 
     It's worth noting that, unlike ruby, functions in go are first class citizens, meaning that you can pass them as arguments to other functions. That's why we're able to provide handler functions.
   */
-  http.HandleFunc("/index", index)
-  http.HandleFunc("/create", create)
+  handleRead("/index", index, listingParams...)
+  handleWrite("/create", create)
+  handleStateless("POST /posts/preview", preview)
+  /*
+    Since Go 1.22 patterns can also include a method and wildcards. "PATCH /posts/{id}" only matches PATCH requests and the {id} segment can be read in the handler with r.PathValue("id").
+  */
+  handleRead("GET /posts", modifiedPosts, "modified_since")
+  handleRead("GET /posts/ids", postIDs)
+  handleRead("GET /posts/slug-available", slugAvailable, "slug", "id")
+  handleRead("GET /posts/{id}", show)
+  handleWrite("PATCH /posts/{id}", patchPost)
+  handleWrite("DELETE /posts/{id}", deletePost)
+  handleStateless("OPTIONS /posts/{id}", postOptions)
+  handleRead("GET /posts/{id}/diff", postDiff, "from", "to")
+  handleRead("GET /posts/{id}/view-rank", viewRank)
+  handleRead("GET /posts/{id}/og", postOpenGraph, "format")
+  handleRead("GET /posts/{id}/permalink", postPermalink)
+  handleRead("GET /posts/{id}/neighbors", neighbors)
+  handleWrite("POST /posts/{id}/move", move)
+  handleWrite("POST /posts/{id}/order", setOrder)
+  handleWrite("PATCH /posts/bulk", bulkUpdate)
+  handleWrite("POST /posts/views", batchViews)
+  handleWrite("POST /posts/{id}/toggle-visibility", toggleVisibility)
+  handleWrite("POST /posts/{id}/duplicate", duplicate)
+  handleWrite("POST /posts/{id}/bump", bump)
+  handleWrite("POST /posts/{id}/share", share)
+  // Views are buffered, flushViews takes postsMu when it writes them.
+  handleStateless("POST /posts/{id}/increment-view", incrementView)
+  handleRead("GET /posts/{id}/comments", listComments)
+  handleWrite("POST /posts/{id}/comments", addComment)
+  handleWrite("POST /posts/{id}/pinned-comment", pinComment)
+  handleWrite("DELETE /posts/{id}/pinned-comment", unpinComment)
+  handleWrite("POST /posts/{id}/toggle-comments", toggleComments)
+  handleWrite("POST /posts/{id}/restore-from-backup", requireAdmin(restoreFromBackup))
+  handleStateless("POST /posts/import-url", writeGuard(requireAdmin(importURL)))
+  handleRead("GET /posts/archive", archive)
+  handleRead("GET /posts/wordfreq", wordFrequency, "top")
+  handleRead("GET /authors", authors, "q")
+  handleRead("GET /authors/featured", featuredAuthors)
+  handleRead("GET /feed.xml", feed)
+  handleRead("GET /sitemap.xml", sitemap)
+  handleRead("GET /export.json", exportJSON)
+  handleRead("GET /export.csv", exportCSV)
+  handleRead("GET /posts/export.json.gz", exportJSONGzip)
+  handleWrite("POST /admin/compact", requireAdmin(compact))
+  // flushViews takes postsMu itself, handleWrite would deadlock.
+  handleStateless("POST /admin/reindex", requireAdmin(reindex))
+  handleStateless("GET /admin/dashboard", requireAdmin(adminDashboard))
+  handleStateless("POST /validate", validatePosts)
+  handleRead("GET /schema/post", postSchema)
+  handleRead("GET /settings", getSettings)
+  handleStateless("PUT /settings", writeGuard(requireAdmin(putSettings)))
+  handleStateless("GET /readyz", readyz)
 
   // The fmt package offers methods to print info to stdout
-  fmt.Println("Server running on http://localhost:3000")
-  // Finally we're ready to listen for request and sever responses
-  http.ListenAndServe(":3000", nil)
+  if config.ReadOnly {
+    fmt.Println("Read-only mode enabled, writes are disabled")
+  }
+  if config.MemoryOnly {
+    fmt.Println("Memory-only mode enabled, posts won't be saved to disk")
+  }
+  // Finally we're ready to listen for request and sever responses. http.HandleFunc registered the routes on http.DefaultServeMux, which we wrap so unknown paths get a JSON 404, see notfound.go.
+  // Each line wraps the handler built so far, so the last wrapper runs first: a request is logged (see logging.go), counted as in flight, then goes through the concurrency limit, the HTTPS redirect, the size limits and the pretty printing choice before reaching the routes.
+  var handler http.Handler = jsonNotFound(http.DefaultServeMux)
+  handler = prettyPrint(handler)
+  handler = limitRequestSize(handler)
+  handler = forceHTTPS(handler)
+  handler = limitConcurrency(handler, config.MaxConcurrentRequests)
+  handler = trackInFlight(handler)
+  handler = logRequests(handler)
+  server := &http.Server{Addr: ":" + strconv.Itoa(config.Port), Handler: handler}
+
+  /*
+    GOROUTINES
+
+    The go keyword runs a function in a goroutine, a lightweight thread managed by Go, and carries on without waiting for it. Here the server runs in its own goroutine so main can wait for a signal to stop it.
+
+    signal.NotifyContext returns a context that is cancelled when the process gets Ctrl+C (SIGINT) or a SIGTERM, and ctx.Done() is a channel that is closed at that moment. Receiving from it with <- blocks until then.
+  */
+  ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+  defer stop()
+
+  if config.ViewFlushInterval.Duration > 0 {
+    go flushViewsEvery(ctx, config.ViewFlushInterval.Duration)
+  }
+  if config.BackupDir != "" {
+    go backupEvery(ctx, config.BackupInterval.Duration)
+  }
+  if cached, ok := storage.(*CachedStore); ok {
+    go func() {
+      if err := watchPosts(ctx, cached); err != nil {
+        log.Printf("Error watching the posts file: %v", err)
+      }
+    }()
+  }
+
+  go func() {
+    var err error
+    if config.TLSCert != "" {
+      fmt.Printf("Server running on https://localhost:%d\n", config.Port)
+      // Serving over TLS also enables HTTP/2, the net/http server negotiates it with the clients that support it.
+      err = server.ListenAndServeTLS(config.TLSCert, config.TLSKey)
+    } else {
+      fmt.Printf("Server running on http://localhost:%d\n", config.Port)
+      err = server.ListenAndServe()
+    }
+    // ErrServerClosed only means Shutdown was called below.
+    if !errors.Is(err, http.ErrServerClosed) {
+      log.Fatal(err)
+    }
+  }()
+
+  <-ctx.Done()
+  shutdown(server, config.ShutdownTimeout.Duration)
 }
 
 /*
@@ -120,38 +442,156 @@ func main() {
   The index function that will be handling the index response.
 */
 func index(w http.ResponseWriter, r *http.Request) {
+  // Admins auditing deletions can ask for the deleted posts too, anyone else gets the public list whatever they ask for. That list can't be cached by proxies, it's not for everyone.
+  includeDeleted := r.URL.Query().Get("include_deleted") == "true" && isAdmin(r)
+  if includeDeleted {
+    w.Header().Set("Cache-Control", "private, no-store")
+  }
+
   /*
     You can define variables ahead of time this way. In most cases you need to provide the type as part of the definition.
 
     In this case we're declaring a post slice which is a dynamic type of list which types can grow or shrink as needed. This is not to be confused with arrays which should have fixed size that must be declared at creation time. Our example requires a slice because the number of posts is variable.
   */
-  var posts []Post
-  /*
-    GO POINTERS
+  var listed []Post
+  streamed := false
+  withFacets, err := parseFacets(r.URL.Query())
+  if err != nil {
+    jsonError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+  var tagCounts map[string]int
+  // With STREAM_POSTS the posts are read one at a time and only the listed ones are kept, see stream.go. Facets need all of them.
+  if config.StreamPosts && !withFacets {
+    listing, err := parseListing(r.URL.Query(), includeDeleted)
+    if err != nil {
+      jsonError(w, http.StatusBadRequest, err.Error())
+      return
+    }
+    if listed, streamed, err = streamPosts(r.Context(), listing); err != nil {
+      jsonError(w, http.StatusInternalServerError, err.Error())
+      return
+    }
+  }
 
-    Similar to C in go you can access the reference of a piece of data by using the & operator. One of the most common use cases to do this is when you want to mutate the variable that is being passed into a function. If you do not do this, Go will pass a copy of the value instead, and any modifications will only affect the copy, not the original variable. For a more in depth explanation on the topic read https://www.digitalocean.com/community/conceptual-articles/understanding-pointers-in-go.
+  if !streamed {
+    var posts []Post
+    /*
+      GO POINTERS
 
-    In our particular example we want to load all the posts into the posts variable passed in to have them available within the scope of the index function.
-  */
-  loadPost(&posts, w)
+      Similar to C in go you can access the reference of a piece of data by using the & operator. One of the most common use cases to do this is when you want to mutate the variable that is being passed into a function. If you do not do this, Go will pass a copy of the value instead, and any modifications will only affect the copy, not the original variable. For a more in depth explanation on the topic read https://www.digitalocean.com/community/conceptual-articles/understanding-pointers-in-go.
+
+      In our particular example we want to load all the posts into the posts variable passed in to have them available within the scope of the index function.
+    */
+    if err := loadPost(r.Context(), &posts); err != nil {
+      jsonError(w, http.StatusInternalServerError, err.Error())
+      return
+    }
+
+    // selectPosts applies the filters, the sort order and the paging from the query string (e.g. ?min_views=10&sort=views&limit=20), see listing.go. It returns the positions of the listed posts in the posts slice.
+    selected, err := selectPosts(r.URL.Query(), posts, includeDeleted)
+    if err != nil {
+      jsonError(w, http.StatusBadRequest, err.Error())
+      return
+    }
+    listed = make([]Post, 0, len(selected))
+    for _, i := range selected {
+      listed = append(listed, posts[i])
+    }
+    if withFacets {
+      // The query was already checked by selectPosts, this can't fail.
+      tagCounts, _ = tagFacets(r.URL.Query(), posts, includeDeleted)
+    }
+  }
+
+  // Like show, index answers 304 Not Modified when none of the listed posts changed since the client's copy, see listModified. The client doesn't get the posts again, so they aren't viewed again either.
+  modified, hasModified := listModified(listed)
+  if hasModified && notModifiedSince(r, modified) {
+    w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+    w.WriteHeader(http.StatusNotModified)
+    return
+  }
 
-  for i := 0; i < len(posts); i++ {
+  for i := 0; i < len(listed); i++ {
     // We can declare variables using the short variable declaration operator := . In this case go will inference the variable type based on the value assigned so it's not required to explicitly define the type at declaration time.
-    post := &posts[i]
+    post := &listed[i]
     /*
-      We're using the receiver functions declared above to modify the ViewCount and LastView properties.
+      countView uses the receiver functions declared above to modify the ViewCount and LastView properties. The views are written to the file later on, in one go with the other views, see views.go.
     */
-    post.increaseViewCount()
-    post.setLastViewed()
+    if !post.Deleted {
+      countView(post, r)
+    }
+  }
+  saveCountedViews(r)
+  // The header goes with the posts as they're sent, views included. Views don't modify a post, so it's the same date the 304 was decided on.
+  if hasModified {
+    w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+  }
+
+  // ?facets=tags puts the list in an object next to the counts, see facets.go.
+  if withFacets {
+    response := map[string]any{"data": listed, "facets": map[string]any{tagsFacet: tagCounts}}
+    if len(listed) == 0 && config.EmptyListMessage != "" {
+      response["message"] = config.EmptyListMessage
+    }
+    writeJSON(w, http.StatusOK, response)
+    return
   }
 
-  // Saves the post to the file.
-  savePosts(posts)
+  // An empty list can come back as a message for the frontend to show instead of a bare [], when EMPTY_LIST_MESSAGE is set.
+  if len(listed) == 0 && config.EmptyListMessage != "" {
+    writeJSON(w, http.StatusOK, map[string]any{"data": listed, "message": config.EmptyListMessage})
+    return
+  }
 
-  // We set the response headers to json so that the browser knows what kind of data we're returning
-  w.Header().Set("Content-Type", "application/json")
-  // Finally we marshall back the posts to json into the response
-  json.NewEncoder(w).Encode(posts)
+  // Finally we marshall back the posts to json into the response. writeJSON also sets the response headers to json so that the browser knows what kind of data we're returning
+  writeJSON(w, http.StatusOK, listed)
+}
+
+/*
+  SHOW HANDLER
+
+  Returns a single post given its ID and counts the visit as a view.
+*/
+func show(w http.ResponseWriter, r *http.Request) {
+  // PostID is declared as a string, so converting the path segment is just a type conversion.
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  // Permalinks use the slug of the post, see permalink.go, and older links its ID: the path segment can be either. IDs come first, a slug that happens to be another post's ID doesn't hide it. Segments that are neither are a 404.
+  i := findPost(posts, id)
+  if i == -1 {
+    i = findPostBySlug(posts, string(id))
+  }
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+  if !checkUniqueID(w, posts, posts[i].ID) {
+    return
+  }
+
+  post := &posts[i]
+
+  // Browsers send back the Last-Modified date they got in If-Modified-Since, if the post hasn't changed since then they can use their cached copy.
+  if modified, ok := post.lastModified(); ok {
+    w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+    if notModifiedSince(r, modified) {
+      w.WriteHeader(http.StatusNotModified)
+      return
+    }
+  }
+
+  countView(post, r)
+  saveCountedViews(r)
+  // Only the response gets the snippets, see snippets.go.
+  shown := *post
+  shown.Content = expandSnippets(shown.Content)
+  writeJSON(w, http.StatusOK, shown)
 }
 
 /*
@@ -165,70 +605,291 @@ func create(w http.ResponseWriter, r *http.Request) {
     http.Error(w, "Please submit a post request", http.StatusMethodNotAllowed)
     return
   }
+  // Clients that just created a post have to wait CREATE_COOLDOWN before the next one, see cooldown.go.
+  if !checkCooldown(w, r) {
+    return
+  }
 
   /*
-    Uses the io package to read the local file where the posts are being saved. Notice that Go supports multiple return values and parallel assignment.
-    In this case we're reading the request Body which contains the post params and assign it to the body variable.
+    Notice that Go supports multiple return values and parallel assignment.
+    In this case we're reading the request Body which contains the post params and assign it to the body variable. readBody uses the io package for that, and also takes care of gzip compressed bodies and of the size limit, see body.go.
   */
-
-  body, err := io.ReadAll(r.Body)
+  body, status, err := readBody(r)
   // This is the common pattern for error handling in Go. Normally methods will return an error object and the caller checks if the error is nil.
   if err != nil {
-    http.Error(w, "Error reading request body", http.StatusBadRequest)
+    jsonError(w, status, err.Error())
     return
   }
   /*
     The defer keyword schedules a function call (in this case, r.Body.Close()) to run after the surrounding function exits, regardless of whether it exits normally or due to an error.
-    It's important to close the request body to free resources. We need to do this because we implicitly opened it in the body, err := io.ReadAll(r.Body).
+    It's important to close the request body to free resources. We need to do this because we implicitly opened it when reading it.
   */
   defer r.Body.Close()
 
+  // json.Unmarshal would keep the last of two keys with the same name, see body.go.
+  if err := checkDuplicateKeys(body); err != nil {
+    jsonError(w, http.StatusBadRequest, err.Error())
+    return
+  }
   var newPost Post
   // We then set deserialize the json into a Post struct to be able to access the pointer receiver functions.
-  json.Unmarshal(body, &newPost)
+  if err := json.Unmarshal(body, &newPost); err != nil {
+    jsonError(w, http.StatusBadRequest, "invalid JSON body")
+    return
+  }
 
-  newPost.setCreatedAt()
+  // Single author blogs can leave the Author out and get the configured default instead.
+  if newPost.Author == "" {
+    newPost.Author = config.DefaultAuthor
+  }
+  if newPost.Lang == "" {
+    newPost.Lang = config.Locales[0]
+  }
+  // Every invalid field is reported at once, so a form can point them all out.
+  if errs := newPost.contentErrors(); len(errs) > 0 {
+    writeJSON(w, http.StatusUnprocessableEntity, newInvalidPost(errs))
+    return
+  }
+  newPost.CoAuthors = normalizeCoAuthors(newPost.Author, newPost.CoAuthors)
+  if config.CheckImageURLs && newPost.FeatureImage != "" {
+    if err := checkImageReachable(r.Context(), newPost.FeatureImage); err != nil {
+      jsonError(w, http.StatusUnprocessableEntity, err.Error())
+      return
+    }
+  }
+
+  // The client can't preset the counters, comments or revisions of the post, see readOnlyFields.
+  clearReadOnlyFields(&newPost)
+  if config.PreserveDates && newPost.CreatedAt != "" {
+    if err := checkCreatedAt(newPost.CreatedAt, time.Now()); err != nil {
+      jsonError(w, http.StatusUnprocessableEntity, err.Error())
+      return
+    }
+    newPost.CreatedAt = storedTimestamp(newPost.CreatedAt)
+  } else {
+    newPost.setCreatedAt()
+  }
+  newPost.setUpdatedAt()
   newPost.setLastViewed()
-  newPost.ViewCount = 0
 
   /*
     For simplicity sake we're just going to load all the post in memory and the append the new post at the end before saving.
   */
   var posts []Post
-  loadPost(&posts, w)
-  posts = append(posts, newPost)
-  savePosts(posts)
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  if !checkPostLimit(w, posts, newPost.Author) {
+    return
+  }
+
+  // A post very similar to an existing one is most likely posted twice by mistake, see duplicates.go.
+  warning := ""
+  if config.DuplicateCheck != duplicateOff {
+    if original, score, ok := findDuplicate(posts, newPost); ok {
+      message := fmt.Sprintf("very similar to post %s %q (similarity %.2f)", original.ID, original.Title, score)
+      if config.DuplicateCheck == duplicateReject {
+        jsonError(w, http.StatusConflict, message)
+        return
+      }
+      warning = message
+    }
+  }
 
-  fmt.Fprintf(w, "Post successfully created")
+  // The ID comes from the generator picked with ID_STRATEGY, see ids.go.
+  newPost.ID, err = ids.NewID(posts)
+  if err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error generating post ID")
+    return
+  }
+  if newPost.Slug == "" {
+    newPost.Slug = uniqueSlug(posts, slugify(newPost.Title), newPost.ID)
+  } else if slugTaken(posts, newPost.Slug, newPost.ID) {
+    jsonError(w, http.StatusConflict, fmt.Sprintf("Slug %q is taken", newPost.Slug))
+    return
+  }
+  if !saveNewPost(w, r, posts, newPost) {
+    return
+  }
+
+  // The client can't guess the ID of the new post, so we send it back whole. Embedding Post in the response struct puts its fields next to the warning.
+  writeJSON(w, http.StatusCreated, createdPost{Post: newPost, Warning: warning})
 }
 
-func savePosts(posts []Post) error {
-  /*
-    Serializes the posts back to a json object
-    prefix: "" means that no prefix should be added at the beginning of the line
-    indent: "  " means that each level should have a 2 spaces indentation
-  */
-  data, err := json.MarshalIndent(posts, "", "  ")
+/*
+  invalidPost is the 422 answer of create. error is the first problem, like in any other error response, and fields has the problem of every invalid field:
+
+  {"error": "Title is required", "fields": {"Title": "Title is required", "Tags": "tags can't be empty"}}
+*/
+type invalidPost struct {
+  Error  string            `json:"error"`
+  Fields map[string]string `json:"fields"`
+}
+
+func newInvalidPost(errs []fieldError) invalidPost {
+  invalid := invalidPost{Error: errs[0].Err.Error(), Fields: map[string]string{}}
+  for _, e := range errs {
+    // A field can only have one problem, e.g. an empty Content isn't also too short.
+    invalid.Fields[e.Field] = e.Err.Error()
+  }
+  return invalid
+}
+
+// checkPostLimit answers with a 403 and reports false when author already has MAX_POSTS_PER_AUTHOR posts. On a shared instance every author can only have so many posts, deleted ones don't count.
+func checkPostLimit(w http.ResponseWriter, posts []Post, author string) bool {
+  if config.MaxPostsPerAuthor > 0 && postsBy(posts, author) >= config.MaxPostsPerAuthor {
+    jsonError(w, http.StatusForbidden, fmt.Sprintf("%s already has the maximum of %d posts", author, config.MaxPostsPerAuthor))
+    return false
+  }
+  return true
+}
+
+/*
+  saveNewPost appends newPost to posts and saves them, answering with a 500 and reporting false when that fails. create and duplicate both go through it, so every new post is logged and starts the cooldown of the client the same way.
+
+  The create is logged before it's saved, so a crash in the middle of the save doesn't lose it, see wal.go. The log holds the post as it's stored. Only a post that was saved starts the cooldown, see cooldown.go.
+*/
+func saveNewPost(w http.ResponseWriter, r *http.Request, posts []Post, newPost Post) bool {
+  logged, err := encryptPosts([]Post{newPost})
   if err != nil {
-    return err
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return false
+  }
+  viewBuffer.logWrite(viewLogEntry{Op: walCreate, ID: newPost.ID, Post: &logged[0]})
+  if err := savePosts(r.Context(), append(posts, newPost)); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return false
+  }
+  createCooldown.seenAt(clientIP(r), time.Now())
+  return true
+}
+
+// postsBy returns the number of posts by author, leaving out the deleted ones.
+func postsBy(posts []Post, author string) int {
+  count := 0
+  for _, post := range posts {
+    if post.Author == author && !post.Deleted {
+      count++
+    }
   }
+  return count
+}
 
-  // Writes the post back into the local file
-  return os.WriteFile(filePath, data, 0644)
+// findPost returns the position of the post with the given ID in the slice, or -1 when there's none. Deleted posts are never found.
+func findPost(posts []Post, id PostID) int {
+  for i, post := range posts {
+    if post.ID == id && !post.Deleted {
+      return i
+    }
+  }
+  return -1
 }
 
 /*
-  Notice the "posts *[]Post" in the function signature. This is used to indicate that the function expects a reference to the posts slice. See the GO POINTERS comment from above.
+  MIDDLEWARE
+
+  A middleware is a function that takes a handler and returns a new handler wrapping it. This lets us run the same piece of logic before (or after) several handlers without repeating it in each of them.
+
+  Routes are registered through handleRead or handleWrite, which wrap the handler with the middleware that applies to that kind of route.
 */
-func loadPost(posts *[]Post, w http.ResponseWriter) {
-  data, err := os.ReadFile(filePath)
 
+// handleRead registers a route that serves posts without modifying them. params are the query parameters it takes, the others are refused with STRICT_QUERY, see query.go.
+func handleRead(pattern string, handler http.HandlerFunc, params ...string) {
+  http.HandleFunc(pattern, traced(pattern, cacheable(strictQuery(params, handler))))
+}
+
+// handleWrite registers a route that modifies the posts file.
+func handleWrite(pattern string, handler http.HandlerFunc) {
+  http.HandleFunc(pattern, traced(pattern, noStore(writeGuard(lockPosts(handler)))))
+}
+
+// handleStateless registers a route that neither reads nor writes the posts, its response only depends on the request.
+func handleStateless(pattern string, handler http.HandlerFunc) {
+  http.HandleFunc(pattern, traced(pattern, noStore(handler)))
+}
+
+// postsMu is held by whatever is modifying the posts file, so two writes can't overwrite each other's changes.
+var postsMu sync.Mutex
+
+// lockPosts runs the handler while holding postsMu, from loading the posts to saving them.
+func lockPosts(next http.HandlerFunc) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    postsMu.Lock()
+    defer postsMu.Unlock()
+    next(w, r)
+  }
+}
+
+// writeGuard rejects the request when the service runs in read-only mode, otherwise it hands the request over to the wrapped handler.
+func writeGuard(next http.HandlerFunc) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    if config.ReadOnly {
+      jsonError(w, http.StatusServiceUnavailable, "service is in read-only mode")
+      return
+    }
+    next(w, r)
+  }
+}
+
+// cacheable lets browsers and proxies cache the response for config.CacheMaxAge seconds.
+func cacheable(next http.HandlerFunc) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    if config.CacheMaxAge > 0 {
+      w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(config.CacheMaxAge))
+    }
+    next(w, r)
+  }
+}
+
+// noStore makes sure responses to writes are never cached.
+func noStore(next http.HandlerFunc) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Cache-Control", "no-store")
+    next(w, r)
+  }
+}
+
+// savePosts writes the posts to the configured storage, see store.go. Content is encrypted on the way out when a key is configured, see crypto.go. Buffered views are left to flushViews.
+func savePosts(ctx context.Context, posts []Post) (err error) {
+  _, span := tracer.Start(ctx, "savePosts")
+  defer func() { endSpan(span, err) }()
+
+  stored := make([]Post, len(posts))
+  copy(stored, posts)
+  for i := range stored {
+    stored[i].removeOverlay()
+  }
+  encrypted, err := encryptPosts(stored)
   if err != nil {
-    http.Error(w, "Error reading request body", http.StatusInternalServerError)
+    return err
   }
+  err = storage.Save(encrypted)
+  // The creates and shares logged before the save are in the file now, or were answered with an error, see wal.go.
+  viewBuffer.saved()
+  return err
+}
 
-  // This is how we 'transform' the unstructured json into a list of posts structs. The process is commonly referred as unmarshalling or deserialization.
-  json.Unmarshal(data, &posts)
+/*
+  Notice the "posts *[]Post" in the function signature. This is used to indicate that the function expects a reference to the posts slice. See the GO POINTERS comment from above.
+
+  loadPost returns an error when the posts can't be read, e.g. when the file doesn't contain valid JSON. Callers must not save anything in that case: writing back the (empty) posts slice would wipe out whatever is left in the file.
+
+  Like savePosts it takes the request context first, by convention, so it can be traced as part of the request (see tracing.go). The named return value err lets the deferred function see the error we end up returning.
+*/
+func loadPost(ctx context.Context, posts *[]Post) (err error) {
+  _, span := tracer.Start(ctx, "loadPost")
+  defer func() { endSpan(span, err) }()
+
+  loaded, err := storage.Load()
+  if err != nil {
+    return err
+  }
+  if err := decryptPosts(loaded); err != nil {
+    return err
+  }
+  viewBuffer.apply(loaded)
+  *posts = loaded
 
   /*
     Contrary to C, you can still use the "."" (dot) operator to access the data from the pointer reference, as oppose to "->". In this case we just need to do post.Title.
@@ -237,4 +898,5 @@ func loadPost(posts *[]Post, w http.ResponseWriter) {
     title := post.Title
     fmt.Printf("Loading Post '%s' in memory\n", title)
   }
+  return nil
 }
@@ -0,0 +1,330 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+/*
+  TEST HELPERS
+
+  The handlers share the globals declared in main.go and next to the features (the config, the store, the view buffer...). setup puts them back to their defaults before every test and keeps the posts in a MemoryStore, so tests neither read posts.json nor see each other's posts. Timestamps are shown in UTC rather than in the time zone of the machine running the tests.
+*/
+func setup(t *testing.T, posts ...Post) {
+  t.Helper()
+  config = defaultConfig()
+  config.MemoryOnly = true
+  config.Timezone = Location{time.UTC}
+  storage = &MemoryStore{}
+  ids = newIDGenerator(config)
+  viewBuffer = &viewBufferMap{pending: map[PostID]bufferedViews{}}
+  viewDebouncer = &debouncer{window: config.ViewDebounce.Duration, seen: map[string]time.Time{}}
+  createCooldown = &debouncer{seen: map[string]time.Time{}}
+  viewCap = &viewCapLimiter{windows: map[PostID]viewWindow{}}
+  contentCipher = nil
+  siteSettings = Settings{Title: config.FeedTitle}
+  if len(posts) > 0 {
+    if err := storage.Save(posts); err != nil {
+      t.Fatal(err)
+    }
+  }
+}
+
+// serve sends the request to handler, registered on pattern so r.PathValue works, and returns the response.
+func serve(pattern string, handler http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+  mux := http.NewServeMux()
+  mux.HandleFunc(pattern, handler)
+  w := httptest.NewRecorder()
+  mux.ServeHTTP(w, r)
+  return w
+}
+
+// newJSONRequest returns a request with v encoded as its JSON body.
+func newJSONRequest(t *testing.T, method, target string, v any) *http.Request {
+  t.Helper()
+  body, err := json.Marshal(v)
+  if err != nil {
+    t.Fatal(err)
+  }
+  r := httptest.NewRequest(method, target, bytes.NewReader(body))
+  r.Header.Set("Content-Type", "application/json")
+  return r
+}
+
+// decode decodes the JSON body of the response into a T.
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+  t.Helper()
+  var v T
+  if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+    t.Fatalf("decoding %q: %v", w.Body.String(), err)
+  }
+  return v
+}
+
+// storedPosts returns the posts as they're stored, without the buffered views.
+func storedPosts(t *testing.T) []Post {
+  t.Helper()
+  posts, err := storage.Load()
+  if err != nil {
+    t.Fatal(err)
+  }
+  return posts
+}
+
+// testPosts are a few valid posts, created one day apart.
+func testPosts() []Post {
+  return []Post{
+    {ID: "1", Title: "First post", Content: "The content of the first post.", Author: "Jane Doe", CreatedAt: "2025-01-01T10:00:00Z"},
+    {ID: "2", Title: "Second post", Content: "The content of the second post.", Author: "John Smith", CreatedAt: "2025-01-02T10:00:00Z"},
+    {ID: "3", Title: "Third post", Content: "The content of the third post.", Author: "Jane Doe", CreatedAt: "2025-01-03T10:00:00Z"},
+  }
+}
+
+func TestReadOnlyBlocksWritesAndServesReads(t *testing.T) {
+  setup(t, testPosts()...)
+  config.ReadOnly = true
+
+  w := serve("POST /create", writeGuard(create), newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"}))
+  if w.Code != http.StatusServiceUnavailable {
+    t.Fatalf("create: got status %d, want %d", w.Code, http.StatusServiceUnavailable)
+  }
+  if got := decode[map[string]string](t, w)["error"]; got != "service is in read-only mode" {
+    t.Errorf("create: got error %q", got)
+  }
+  if w := serve("DELETE /posts/{id}", writeGuard(deletePost), httptest.NewRequest(http.MethodDelete, "/posts/1", nil)); w.Code != http.StatusServiceUnavailable {
+    t.Errorf("delete: got status %d, want %d", w.Code, http.StatusServiceUnavailable)
+  }
+  if got := len(storedPosts(t)); got != 3 {
+    t.Errorf("got %d stored posts, want 3", got)
+  }
+
+  w = serve("/index", index, httptest.NewRequest(http.MethodGet, "/index", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("index: got status %d, want %d", w.Code, http.StatusOK)
+  }
+  if got := len(decode[[]Post](t, w)); got != 3 {
+    t.Errorf("index: got %d posts, want 3", got)
+  }
+  w = serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/2", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("show: got status %d, want %d", w.Code, http.StatusOK)
+  }
+  // Views aren't counted in read-only mode, they would have to be written.
+  if got := decode[Post](t, w); got.Title != "Second post" || got.ViewCount != 0 {
+    t.Errorf("show: got %q with %d views", got.Title, got.ViewCount)
+  }
+}
+
+func TestCacheControl(t *testing.T) {
+  tests := []struct {
+    maxAge int
+    want   string
+  }{
+    {0, ""},
+    {60, "public, max-age=60"},
+    {3600, "public, max-age=3600"},
+  }
+  for _, test := range tests {
+    setup(t, testPosts()...)
+    config.CacheMaxAge = test.maxAge
+
+    w := serve("/index", cacheable(index), httptest.NewRequest(http.MethodGet, "/index", nil))
+    if got := w.Header().Get("Cache-Control"); got != test.want {
+      t.Errorf("CACHE_MAX_AGE=%d: index got Cache-Control %q, want %q", test.maxAge, got, test.want)
+    }
+    w = serve("GET /posts/{id}", cacheable(show), httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+    if got := w.Header().Get("Cache-Control"); got != test.want {
+      t.Errorf("CACHE_MAX_AGE=%d: show got Cache-Control %q, want %q", test.maxAge, got, test.want)
+    }
+    w = serve("/create", noStore(create), newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"}))
+    if got := w.Header().Get("Cache-Control"); got != "no-store" {
+      t.Errorf("CACHE_MAX_AGE=%d: create got Cache-Control %q, want no-store", test.maxAge, got)
+    }
+  }
+}
+
+func TestShowIfModifiedSince(t *testing.T) {
+  posts := testPosts()
+  posts[0].UpdatedAt = "2025-02-01T10:00:00Z"
+  setup(t, posts...)
+
+  w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+  if got := w.Header().Get("Last-Modified"); got != "Sat, 01 Feb 2025 10:00:00 GMT" {
+    t.Errorf("got Last-Modified %q, want the UpdatedAt", got)
+  }
+  w = serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/2", nil))
+  if got := w.Header().Get("Last-Modified"); got != "Thu, 02 Jan 2025 10:00:00 GMT" {
+    t.Errorf("never updated: got Last-Modified %q, want the CreatedAt", got)
+  }
+
+  tests := []struct {
+    since  string
+    status int
+  }{
+    {"Sat, 01 Feb 2025 10:00:00 GMT", http.StatusNotModified},
+    {"Mon, 01 Sep 2025 08:00:00 GMT", http.StatusNotModified},
+    {"Sat, 01 Feb 2025 09:59:59 GMT", http.StatusOK},
+    {"not a date", http.StatusOK},
+  }
+  for _, test := range tests {
+    r := httptest.NewRequest(http.MethodGet, "/posts/1", nil)
+    r.Header.Set("If-Modified-Since", test.since)
+    w := serve("GET /posts/{id}", show, r)
+    if w.Code != test.status {
+      t.Errorf("If-Modified-Since %q: got status %d, want %d", test.since, w.Code, test.status)
+    }
+    if test.status == http.StatusNotModified && w.Body.Len() != 0 {
+      t.Errorf("If-Modified-Since %q: got body %q, want none", test.since, w.Body)
+    }
+  }
+}
+
+func TestDefaultAuthor(t *testing.T) {
+  tests := []struct {
+    name          string
+    defaultAuthor string
+    author        string
+    status        int
+    want          string
+  }{
+    {"default applied", "Jane Doe", "", http.StatusCreated, "Jane Doe"},
+    {"explicit override", "Jane Doe", "John Smith", http.StatusCreated, "John Smith"},
+    {"no default", "", "", http.StatusUnprocessableEntity, ""},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t)
+      config.DefaultAuthor = test.defaultAuthor
+
+      w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: test.author}))
+      if w.Code != test.status {
+        t.Fatalf("got status %d, want %d: %s", w.Code, test.status, w.Body)
+      }
+      if test.status != http.StatusCreated {
+        if got := decode[invalidPost](t, w).Fields["Author"]; got != "Author is required" {
+          t.Errorf("got Author error %q", got)
+        }
+        return
+      }
+      if got := decode[Post](t, w).Author; got != test.want {
+        t.Errorf("got Author %q, want %q", got, test.want)
+      }
+    })
+  }
+}
+
+func TestMaxPostsPerAuthor(t *testing.T) {
+  posts := testPosts()
+  // Deleted posts don't count.
+  posts = append(posts, Post{ID: "4", Title: "Deleted post", Content: "Gone", Author: "John Smith", CreatedAt: "2025-01-04T10:00:00Z", Deleted: true})
+  setup(t, posts...)
+  config.MaxPostsPerAuthor = 2
+
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"}))
+  if w.Code != http.StatusForbidden {
+    t.Fatalf("Jane Doe: got status %d, want %d: %s", w.Code, http.StatusForbidden, w.Body)
+  }
+  if got := decode[map[string]string](t, w)["error"]; got != "Jane Doe already has the maximum of 2 posts" {
+    t.Errorf("Jane Doe: got error %q", got)
+  }
+  if w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "John Smith"})); w.Code != http.StatusCreated {
+    t.Errorf("John Smith: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+
+  config.MaxPostsPerAuthor = 0
+  if w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "Another", Content: "Another content", Author: "Jane Doe"})); w.Code != http.StatusCreated {
+    t.Errorf("no limit: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+}
+
+func TestCreateIgnoresServiceManagedFields(t *testing.T) {
+  setup(t, testPosts()...)
+
+  sent := Post{
+    ID: "1", Title: "New", Content: "New content", Author: "Jane Doe",
+    UpdatedAt: "2025-06-01T00:00:00Z", ViewCount: 1000, LastViewed: "2020-01-01T00:00:00Z", Shares: 500, Deleted: true,
+    Comments: []Comment{{ID: 1, Author: "Bot", Content: "First!"}}, PinnedCommentID: 1,
+    Revisions: []Revision{{Title: "Old", Content: "Old"}}, OriginalCreatedAt: "2020-01-01T00:00:00Z",
+  }
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", sent))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  got := storedPosts(t)[3]
+  if got.ID != "4" || got.UpdatedAt == sent.UpdatedAt || got.ViewCount != 0 || got.Shares != 0 || got.Deleted {
+    t.Errorf("got ID %q, UpdatedAt %q, %d views, %d shares and Deleted %v, want the service's values", got.ID, got.UpdatedAt, got.ViewCount, got.Shares, got.Deleted)
+  }
+  if got.LastViewed == sent.LastViewed || got.OriginalCreatedAt != "" {
+    t.Errorf("got LastViewed %q and OriginalCreatedAt %q, want the service's values", got.LastViewed, got.OriginalCreatedAt)
+  }
+  if len(got.Comments) != 0 || got.PinnedCommentID != 0 || len(got.Revisions) != 0 {
+    t.Errorf("got comments %+v, pinned comment %d and revisions %+v, want none", got.Comments, got.PinnedCommentID, got.Revisions)
+  }
+}
+
+func TestMinContentLength(t *testing.T) {
+  tests := []struct {
+    name    string
+    min     int
+    content string
+    status  int
+  }{
+    // Characters are counted, not bytes: é takes two.
+    {"just under", 10, "ééééééééé", http.StatusUnprocessableEntity},
+    {"exactly", 10, "éééééééééé", http.StatusCreated},
+    {"just over", 10, "ééééééééééé", http.StatusCreated},
+    {"surrounding spaces don't count", 10, "  ééééééééé  ", http.StatusUnprocessableEntity},
+    {"disabled", 0, "é", http.StatusCreated},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t)
+      config.MinContentLength = test.min
+      w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: test.content, Author: "Jane Doe"}))
+      if w.Code != test.status {
+        t.Fatalf("got status %d, want %d: %s", w.Code, test.status, w.Body)
+      }
+      if test.status != http.StatusCreated {
+        if got, want := decode[invalidPost](t, w).Fields["Content"], "Content must be at least 10 characters long, got 9"; got != want {
+          t.Errorf("got Content error %q, want %q", got, want)
+        }
+      }
+    })
+  }
+}
+
+func TestCreateReportsEveryInvalidField(t *testing.T) {
+  setup(t)
+  post := Post{Title: "", Content: "", Author: "", Slug: "Not A Slug", Tags: []string{"go", " "}}
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", post))
+  if w.Code != http.StatusUnprocessableEntity {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+  }
+  got := decode[invalidPost](t, w)
+  want := map[string]string{
+    "Title":   "Title is required",
+    "Content": "Content is required",
+    "Author":  "Author is required",
+    "Slug":    `Slug "Not A Slug" must be lowercase words separated by hyphens, e.g. "not-a-slug"`,
+    "Tags":    "tags can't be empty",
+  }
+  if len(got.Fields) != len(want) {
+    t.Errorf("got fields %v, want %v", got.Fields, want)
+  }
+  for field, message := range want {
+    if got.Fields[field] != message {
+      t.Errorf("%s: got %q, want %q", field, got.Fields[field], message)
+    }
+  }
+  // error is the first problem, like in the other error responses.
+  if got.Error != "Title is required" {
+    t.Errorf("got error %q, want %q", got.Error, "Title is required")
+  }
+  if posts := storedPosts(t); len(posts) != 0 {
+    t.Errorf("got %d stored posts, want none", len(posts))
+  }
+}
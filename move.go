@@ -0,0 +1,101 @@
+package main
+
+import (
+  "encoding/json"
+  "net/http"
+)
+
+/*
+  MOVE HANDLER
+
+  Posts that index can't tell apart by their Order and CreatedAt are listed in the order they're stored in the file, so moving a post around in the file changes its position among them. For full control over the order see order.go. The body says where the post should go, either as a position in the list or relative to another post:
+
+  {"index": 0}     moves the post to the front
+  {"before": 3}    moves it right before post 3
+  {"after": 3}     moves it right after post 3
+
+  Positions outside of the list are clamped to the first or last place.
+
+  The fields are pointers so we can tell a missing field (nil) apart from a field that was set to 0.
+*/
+type moveRequest struct {
+  Index  *int    `json:"index"`
+  Before *PostID `json:"before"`
+  After  *PostID `json:"after"`
+}
+
+func move(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var request moveRequest
+  if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+    jsonError(w, http.StatusBadRequest, "invalid JSON body")
+    return
+  }
+  defer r.Body.Close()
+
+  set := 0
+  if request.Index != nil {
+    set++
+  }
+  for _, field := range []*PostID{request.Before, request.After} {
+    if field != nil {
+      set++
+    }
+  }
+  if set != 1 {
+    jsonError(w, http.StatusBadRequest, "specify exactly one of index, before or after")
+    return
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  from := findPost(posts, id)
+  if from == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  // Take the post out of the slice first, the target position is computed on the remaining posts.
+  post := posts[from]
+  rest := append(posts[:from:from], posts[from+1:]...)
+
+  var to int
+  switch {
+  case request.Index != nil:
+    to = min(max(*request.Index, 0), len(rest))
+  default:
+    target := request.Before
+    if target == nil {
+      target = request.After
+    }
+    if *target == id {
+      jsonError(w, http.StatusBadRequest, "a post can't be moved relative to itself")
+      return
+    }
+    to = findPost(rest, *target)
+    if to == -1 {
+      jsonError(w, http.StatusUnprocessableEntity, "target post not found")
+      return
+    }
+    if request.After != nil {
+      to++
+    }
+  }
+
+  // slices can be grown and reassembled with append, here we put the post back at its new position.
+  moved := append(rest[:to:to], append([]Post{post}, rest[to:]...)...)
+  if err := savePosts(r.Context(), moved); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  order := make([]PostID, len(moved))
+  for i, post := range moved {
+    order[i] = post.ID
+  }
+  writeJSON(w, http.StatusOK, map[string]any{"order": order})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "strings"
+  "testing"
+)
+
+// storedOrder returns the IDs of the stored posts, in the order of the file.
+func storedOrder(t *testing.T) []PostID {
+  t.Helper()
+  order := []PostID{}
+  for _, post := range storedPosts(t) {
+    order = append(order, post.ID)
+  }
+  return order
+}
+
+func TestMove(t *testing.T) {
+  tests := []struct {
+    name string
+    id   string
+    body string
+    want []PostID
+  }{
+    {"to the front", "3", `{"index": 0}`, []PostID{"3", "1", "2"}},
+    {"clamped to the end", "1", `{"index": 99}`, []PostID{"2", "3", "1"}},
+    {"clamped to the front", "2", `{"index": -5}`, []PostID{"2", "1", "3"}},
+    {"before", "3", `{"before": "2"}`, []PostID{"1", "3", "2"}},
+    {"after", "1", `{"after": "2"}`, []PostID{"2", "1", "3"}},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t, testPosts()...)
+      w := serve("POST /posts/{id}/move", move, httptest.NewRequest(http.MethodPost, "/posts/"+test.id+"/move", strings.NewReader(test.body)))
+      if w.Code != http.StatusOK {
+        t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+      }
+      if got := decode[map[string][]PostID](t, w)["order"]; !slices.Equal(got, test.want) {
+        t.Errorf("response: got order %v, want %v", got, test.want)
+      }
+      if got := storedOrder(t); !slices.Equal(got, test.want) {
+        t.Errorf("stored: got order %v, want %v", got, test.want)
+      }
+    })
+  }
+}
+
+func TestMoveInvalid(t *testing.T) {
+  tests := []struct {
+    name   string
+    id     string
+    body   string
+    status int
+  }{
+    {"no position", "1", `{}`, http.StatusBadRequest},
+    {"two positions", "1", `{"index": 0, "after": "2"}`, http.StatusBadRequest},
+    {"relative to itself", "1", `{"before": "1"}`, http.StatusBadRequest},
+    {"unknown target", "1", `{"before": "9"}`, http.StatusUnprocessableEntity},
+    {"unknown post", "9", `{"index": 0}`, http.StatusNotFound},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t, testPosts()...)
+      w := serve("POST /posts/{id}/move", move, httptest.NewRequest(http.MethodPost, "/posts/"+test.id+"/move", strings.NewReader(test.body)))
+      if w.Code != test.status {
+        t.Fatalf("got status %d, want %d: %s", w.Code, test.status, w.Body)
+      }
+      if got := storedOrder(t); !slices.Equal(got, []PostID{"1", "2", "3"}) {
+        t.Errorf("got order %v, want it unchanged", got)
+      }
+    })
+  }
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+  "net/http"
+)
+
+/*
+  NEIGHBORS HANDLER
+
+  GET /posts/{id}/neighbors returns the posts around this one by creation date, for a post page's navigation: prev is the next newer post and next the next older one, as they come in the feed. They're null at the ends of the list:
+
+  {"prev": {"ID": "4", ...}, "next": null}
+
+  Only published posts are neighbors, see visiblePosts, and a post that isn't published itself has none, it's a 404. Fetching the neighbors doesn't count as viewing them.
+*/
+func neighbors(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  published := visiblePosts(posts)
+  sortNewestFirst(published)
+  i := findPost(published, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  var prev, next *Post
+  if i > 0 {
+    prev = &published[i-1]
+  }
+  if i < len(published)-1 {
+    next = &published[i+1]
+  }
+  writeJSON(w, http.StatusOK, map[string]*Post{"prev": prev, "next": next})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+// postNeighbors returns the IDs of the neighbors of post id, "" at the ends of the list.
+func postNeighbors(t *testing.T, id string) (prev, next PostID) {
+  t.Helper()
+  w := serve("GET /posts/{id}/neighbors", neighbors, httptest.NewRequest(http.MethodGet, "/posts/"+id+"/neighbors", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  got := decode[map[string]*Post](t, w)
+  if got["prev"] != nil {
+    prev = got["prev"].ID
+  }
+  if got["next"] != nil {
+    next = got["next"].ID
+  }
+  return prev, next
+}
+
+func TestNeighbors(t *testing.T) {
+  // The file isn't in creation order.
+  posts := testPosts()
+  posts[0], posts[1] = posts[1], posts[0]
+  setup(t, posts...)
+
+  tests := []struct {
+    id         string
+    prev, next PostID
+  }{
+    {"2", "3", "1"},
+    {"3", "", "2"},
+    {"1", "2", ""},
+  }
+  for _, test := range tests {
+    if prev, next := postNeighbors(t, test.id); prev != test.prev || next != test.next {
+      t.Errorf("post %s: got prev %q and next %q, want %q and %q", test.id, prev, next, test.prev, test.next)
+    }
+  }
+  for _, post := range storedPosts(t) {
+    if post.ViewCount != 0 {
+      t.Errorf("post %s: got %d views, want none", post.ID, post.ViewCount)
+    }
+  }
+}
+
+func TestNeighborsArePublished(t *testing.T) {
+  posts := append(testPosts(), Post{ID: "4", Title: "Draft", Content: "Draft", Author: "Jane Doe", CreatedAt: "2025-01-04T10:00:00Z", Draft: true})
+  posts[1].Hidden = true
+  setup(t, posts...)
+
+  if prev, next := postNeighbors(t, "3"); prev != "" || next != "1" {
+    t.Errorf("got prev %q and next %q, want none and 1", prev, next)
+  }
+  for _, id := range []string{"2", "4"} {
+    if w := serve("GET /posts/{id}/neighbors", neighbors, httptest.NewRequest(http.MethodGet, "/posts/"+id+"/neighbors", nil)); w.Code != http.StatusNotFound {
+      t.Errorf("post %s: got status %d, want %d", id, w.Code, http.StatusNotFound)
+    }
+  }
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+  "net/http"
+)
+
+/*
+  NOT FOUND
+
+  Paths that don't match any route get a plain text 404 from the ServeMux. jsonNotFound wraps the mux and turns those into a JSON error like the ones our handlers return:
+
+  {"error": "not found", "path": "/nope"}
+
+  mux.Handler tells us which pattern a request matches without serving it, an empty pattern means no route matched. The mux's own response is still used for the other cases, e.g. a 405 for a known path with the wrong method.
+*/
+func jsonNotFound(mux *http.ServeMux) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if _, pattern := mux.Handler(r); pattern != "" {
+      mux.ServeHTTP(w, r)
+      return
+    }
+    mux.ServeHTTP(&notFoundWriter{ResponseWriter: w, path: r.URL.Path}, r)
+  })
+}
+
+// notFoundWriter replaces a 404 response with our JSON error and drops the plain text body that follows.
+type notFoundWriter struct {
+  http.ResponseWriter
+  path     string
+  replaced bool
+}
+
+func (w *notFoundWriter) WriteHeader(status int) {
+  if status != http.StatusNotFound {
+    w.ResponseWriter.WriteHeader(status)
+    return
+  }
+  w.replaced = true
+  w.Header().Del("X-Content-Type-Options")
+  writeJSON(w.ResponseWriter, http.StatusNotFound, map[string]string{"error": "not found", "path": w.path})
+}
+
+func (w *notFoundWriter) Write(body []byte) (int, error) {
+  if w.replaced {
+    return len(body), nil
+  }
+  return w.ResponseWriter.Write(body)
+}
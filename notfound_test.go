@@ -0,0 +1,39 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestJSONNotFound(t *testing.T) {
+  setup(t, testPosts()...)
+  mux := http.NewServeMux()
+  mux.HandleFunc("GET /posts/{id}", show)
+  handler := jsonNotFound(mux)
+
+  w := httptest.NewRecorder()
+  handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/nope", nil))
+  if w.Code != http.StatusNotFound {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusNotFound)
+  }
+  if got := w.Header().Get("Content-Type"); got != "application/json" {
+    t.Errorf("got Content-Type %q, want application/json", got)
+  }
+  got := decode[map[string]string](t, w)
+  if got["error"] != "not found" || got["path"] != "/nope" {
+    t.Errorf("got %v, want the not found error and the path", got)
+  }
+
+  // Known routes and wrong methods keep their own response.
+  w = httptest.NewRecorder()
+  handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+  if w.Code != http.StatusOK {
+    t.Errorf("known route: got status %d, want %d", w.Code, http.StatusOK)
+  }
+  w = httptest.NewRecorder()
+  handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/posts/1", nil))
+  if w.Code != http.StatusMethodNotAllowed {
+    t.Errorf("wrong method: got status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+  }
+}
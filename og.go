@@ -0,0 +1,84 @@
+package main
+
+import (
+  "fmt"
+  "html"
+  "net/http"
+  "strings"
+)
+
+/*
+  OPEN GRAPH HANDLER
+
+  GET /posts/{id}/og returns the Open Graph metadata of the post (https://ogp.me), which social networks read to show a preview card when the post is shared:
+
+  {"og:type": "article", "og:site_name": "Posts", "og:title": "My First Post", "og:description": "This is the content of the post.", "og:url": "http://localhost:3000/posts/3", "og:image": "https://example.com/cover.png"}
+
+  The description is the excerpt of the post, see render.go, and the site name the title of the site settings, see settings.go. Posts without a FeatureImage get the DEFAULT_IMAGE, and no og:image when that isn't set either.
+
+  With ?format=html the same metadata comes as the <meta> tags to paste in the <head> of the page, in the order of the list of ogProperty.
+*/
+type ogProperty struct {
+  property, content string
+}
+
+func postOpenGraph(w http.ResponseWriter, r *http.Request) {
+  format := r.URL.Query().Get("format")
+  if format != "" && format != "json" && format != "html" {
+    jsonError(w, http.StatusBadRequest, fmt.Sprintf("unknown format %q, use json or html", format))
+    return
+  }
+
+  id := PostID(r.PathValue("id"))
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  post := posts[i]
+  post.Content = expandSnippets(post.Content)
+  rendered, err := render(post)
+  if err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error rendering post")
+    return
+  }
+  image := post.FeatureImage
+  if image == "" {
+    image = config.DefaultImage
+  }
+  properties := []ogProperty{
+    {"og:type", "article"},
+    {"og:site_name", currentSettings().Title},
+    {"og:title", post.Title},
+    {"og:description", rendered.Excerpt},
+    {"og:url", permalink(post)},
+    {"og:image", image},
+  }
+
+  if format != "html" {
+    // A map rather than a struct, JSON_CASE doesn't rename map keys.
+    og := map[string]string{}
+    for _, p := range properties {
+      if p.content != "" {
+        og[p.property] = p.content
+      }
+    }
+    writeJSON(w, http.StatusOK, og)
+    return
+  }
+  var tags strings.Builder
+  for _, p := range properties {
+    if p.content != "" {
+      // EscapeString keeps a quote in the title from ending the attribute.
+      fmt.Fprintf(&tags, "<meta property=\"%s\" content=\"%s\">\n", p.property, html.EscapeString(p.content))
+    }
+  }
+  w.Header().Set("Content-Type", "text/html; charset=utf-8")
+  w.Write([]byte(tags.String()))
+}
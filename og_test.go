@@ -0,0 +1,47 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func TestOpenGraph(t *testing.T) {
+  posts := testPosts()
+  posts[0].Title = `Say "hello"`
+  posts[0].Slug = "say-hello"
+  posts[0].Content = "# Hello\n\nSome **bold** text."
+  posts[0].FeatureImage = "https://example.com/cover.png"
+  setup(t, posts...)
+  config.BaseURL = "https://blog.example.com"
+  config.DefaultImage = "https://example.com/default.png"
+
+  w := serve("GET /posts/{id}/og", postOpenGraph, httptest.NewRequest(http.MethodGet, "/posts/1/og", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  og := decode[map[string]string](t, w)
+  want := map[string]string{
+    "og:title":       `Say "hello"`,
+    "og:description": "Hello Some bold text.",
+    "og:url":         "https://blog.example.com/posts/say-hello",
+    "og:image":       "https://example.com/cover.png",
+  }
+  for property, content := range want {
+    if og[property] != content {
+      t.Errorf("got %s %q, want %q", property, og[property], content)
+    }
+  }
+
+  // Without a FeatureImage the post gets the default one.
+  w = serve("GET /posts/{id}/og", postOpenGraph, httptest.NewRequest(http.MethodGet, "/posts/2/og", nil))
+  if got := decode[map[string]string](t, w)["og:image"]; got != config.DefaultImage {
+    t.Errorf("got og:image %q, want %q", got, config.DefaultImage)
+  }
+
+  w = serve("GET /posts/{id}/og", postOpenGraph, httptest.NewRequest(http.MethodGet, "/posts/1/og?format=html", nil))
+  if tag := `<meta property="og:title" content="Say &#34;hello&#34;">`; !strings.Contains(w.Body.String(), tag) {
+    t.Errorf("got %s, want the tag %s", w.Body, tag)
+  }
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+  "net/http"
+  "strings"
+)
+
+/*
+  OPTIONS
+
+  An OPTIONS request asks what can be done with a resource. For a post the answer is in the Allow header, listing the methods of the /posts/{id} routes registered in main. HEAD comes for free with GET, the ServeMux answers it with the GET handler minus the body.
+
+  A PUT would replace the post as a whole, updates go through PATCH instead so it's not listed.
+*/
+var postMethods = []string{http.MethodGet, http.MethodHead, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+
+func postOptions(w http.ResponseWriter, r *http.Request) {
+  w.Header().Set("Allow", strings.Join(postMethods, ", "))
+  w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestPostOptions(t *testing.T) {
+  setup(t, testPosts()...)
+
+  w := serve("OPTIONS /posts/{id}", postOptions, httptest.NewRequest(http.MethodOptions, "/posts/1", nil))
+  if w.Code != http.StatusNoContent {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusNoContent)
+  }
+  if got, want := w.Header().Get("Allow"), "GET, HEAD, PATCH, DELETE, OPTIONS"; got != want {
+    t.Errorf("got Allow %q, want %q", got, want)
+  }
+  if w.Body.Len() != 0 {
+    t.Errorf("got body %q, want none", w.Body)
+  }
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+  "encoding/json"
+  "net/http"
+)
+
+/*
+  ORDER HANDLER
+
+  Sets the Order of a post, which index sorts by before anything else:
+
+  POST /posts/3/order {"order": 1}    lists post 3 first, i.e. pins it to the top
+  POST /posts/3/order {"order": 0}    clears it, post 3 goes back among the unordered posts
+
+  Posts without an Order, like the ones created before the field existed, come after all the ordered ones.
+*/
+type orderRequest struct {
+  Order *int `json:"order"`
+}
+
+func setOrder(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var request orderRequest
+  if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+    jsonError(w, http.StatusBadRequest, "invalid JSON body")
+    return
+  }
+  defer r.Body.Close()
+  if request.Order == nil || *request.Order < 0 {
+    jsonError(w, http.StatusBadRequest, "order must be a number greater than or equal to 0")
+    return
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  post := &posts[i]
+  post.Order = *request.Order
+  post.setUpdatedAt()
+  if err := savePosts(r.Context(), posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, map[string]any{"id": post.ID, "order": post.Order})
+}
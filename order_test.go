@@ -0,0 +1,41 @@
+package main
+
+import (
+  "net/http"
+  "slices"
+  "testing"
+)
+
+func TestSetOrder(t *testing.T) {
+  setup(t, append(testPosts(), Post{ID: "4", Title: "Fourth post", Content: "The content of the fourth post.", Author: "Jane Doe", CreatedAt: "2025-01-04T10:00:00Z"})...)
+
+  // Without any Order the newest posts come first.
+  if got, want := listedIDs(t, ""), []PostID{"4", "3", "2", "1"}; !slices.Equal(got, want) {
+    t.Fatalf("got %v, want %v", got, want)
+  }
+
+  for _, order := range []struct {
+    id    string
+    order int
+  }{{"1", 2}, {"2", 1}} {
+    w := serve("POST /posts/{id}/order", setOrder, newJSONRequest(t, http.MethodPost, "/posts/"+order.id+"/order", map[string]int{"order": order.order}))
+    if w.Code != http.StatusOK {
+      t.Fatalf("post %s: got status %d, want %d: %s", order.id, w.Code, http.StatusOK, w.Body)
+    }
+  }
+  if got, want := listedIDs(t, ""), []PostID{"2", "1", "4", "3"}; !slices.Equal(got, want) {
+    t.Errorf("got %v, want the ordered posts first, then the newest", got)
+  }
+
+  // Order 0 puts the post back among the unordered ones.
+  serve("POST /posts/{id}/order", setOrder, newJSONRequest(t, http.MethodPost, "/posts/2/order", map[string]int{"order": 0}))
+  if got, want := listedIDs(t, ""), []PostID{"1", "4", "3", "2"}; !slices.Equal(got, want) {
+    t.Errorf("after clearing: got %v, want %v", got, want)
+  }
+
+  for _, body := range []any{map[string]int{"order": -1}, map[string]string{}} {
+    if w := serve("POST /posts/{id}/order", setOrder, newJSONRequest(t, http.MethodPost, "/posts/1/order", body)); w.Code != http.StatusBadRequest {
+      t.Errorf("%v: got status %d, want %d", body, w.Code, http.StatusBadRequest)
+    }
+  }
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "io"
+  "mime"
+  "net/http"
+  "reflect"
+  "strings"
+  "time"
+)
+
+/*
+  JSON PATCH
+
+  JSON Patch (RFC 6902) describes a change as a list of operations, e.g.
+
+  [{"op": "replace", "path": "/Title", "value": "A better title"}]
+
+  We support the "add", "replace" and "remove" operations on the top level fields of a post. The patch is applied to a generic JSON object first and the result is decoded back into a Post, so anything that doesn't fit the Post struct is rejected.
+*/
+type patchOperation struct {
+  Op    string          `json:"op"`
+  Path  string          `json:"path"`
+  Value json.RawMessage `json:"value"`
+}
+
+// requiredFields can't be removed by a patch, see Post.fieldErrors. readOnlyFields are managed by the service and can't be patched at all. CreatedAt can, but like in create it can't be in the future.
+var (
+  requiredFields = []string{"Title", "Content", "Author"}
+  readOnlyFields = []string{"ID", "UpdatedAt", "ViewCount", "LastViewed", "Revisions", "Deleted", "Shares", "Comments", "PinnedCommentID", "WordCount", "OriginalCreatedAt"}
+)
+
+// clearReadOnlyFields zeroes the readOnlyFields of a post sent by a client, create sets them itself. The field names are those of the Post struct too.
+func clearReadOnlyFields(post *Post) {
+  v := reflect.ValueOf(post).Elem()
+  for _, field := range readOnlyFields {
+    v.FieldByName(field).SetZero()
+  }
+}
+
+func patchPost(w http.ResponseWriter, r *http.Request) {
+  mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+  if mediaType != "application/json-patch+json" {
+    jsonError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json-patch+json")
+    return
+  }
+
+  id := PostID(r.PathValue("id"))
+
+  body, err := io.ReadAll(r.Body)
+  if err != nil {
+    jsonError(w, http.StatusBadRequest, "Error reading request body")
+    return
+  }
+  defer r.Body.Close()
+
+  var operations []patchOperation
+  if err := json.Unmarshal(body, &operations); err != nil {
+    jsonError(w, http.StatusBadRequest, "invalid JSON Patch document")
+    return
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+  if !checkUniqueID(w, posts, id) {
+    return
+  }
+
+  patched, status, err := applyPatch(posts[i], operations)
+  if err != nil {
+    jsonError(w, status, err.Error())
+    return
+  }
+  // The patched post is checked like a new one, see create: PROFANITY_MODE and MIN_CONTENT_LEN apply too.
+  if errs := patched.contentErrors(); len(errs) > 0 {
+    writeJSON(w, http.StatusUnprocessableEntity, newInvalidPost(errs))
+    return
+  }
+  if patched.Slug != "" && patched.Slug != posts[i].Slug && slugTaken(posts, patched.Slug, id) {
+    jsonError(w, http.StatusConflict, fmt.Sprintf("Slug %q is taken", patched.Slug))
+    return
+  }
+  if patched.CreatedAt != posts[i].CreatedAt {
+    if err := checkCreatedAt(patched.CreatedAt, time.Now()); err != nil {
+      jsonError(w, http.StatusUnprocessableEntity, err.Error())
+      return
+    }
+    patched.CreatedAt = storedTimestamp(patched.CreatedAt)
+  }
+  patched.CoAuthors = normalizeCoAuthors(patched.Author, patched.CoAuthors)
+  patched.recordRevision(posts[i])
+  patched.setUpdatedAt()
+  posts[i] = patched
+
+  if err := savePosts(r.Context(), posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, patched)
+}
+
+/*
+  applyPatch returns the patched copy of the post, which patchPost then validates. Since post is passed by value, the caller's post is left untouched when one of the operations fails.
+
+  The returned status tells the handler how to report the error: 400 for a malformed patch and 422 for a well formed patch that can't be applied to this post.
+*/
+func applyPatch(post Post, operations []patchOperation) (Post, int, error) {
+  data, err := json.Marshal(post)
+  if err != nil {
+    return post, http.StatusInternalServerError, err
+  }
+  var document map[string]json.RawMessage
+  if err := json.Unmarshal(data, &document); err != nil {
+    return post, http.StatusInternalServerError, err
+  }
+
+  for _, operation := range operations {
+    field, err := patchField(operation.Path)
+    if err != nil {
+      return post, http.StatusBadRequest, err
+    }
+    for _, readOnly := range readOnlyFields {
+      if field == readOnly {
+        return post, http.StatusUnprocessableEntity, fmt.Errorf("%s can't be modified", field)
+      }
+    }
+
+    switch operation.Op {
+    case "add":
+      if operation.Value == nil {
+        return post, http.StatusBadRequest, fmt.Errorf("%s operation on %s requires a value", operation.Op, operation.Path)
+      }
+      document[field] = operation.Value
+    case "replace":
+      if operation.Value == nil {
+        return post, http.StatusBadRequest, fmt.Errorf("%s operation on %s requires a value", operation.Op, operation.Path)
+      }
+      if _, ok := document[field]; !ok {
+        return post, http.StatusUnprocessableEntity, fmt.Errorf("path %s does not exist", operation.Path)
+      }
+      document[field] = operation.Value
+    case "remove":
+      if _, ok := document[field]; !ok {
+        return post, http.StatusUnprocessableEntity, fmt.Errorf("path %s does not exist", operation.Path)
+      }
+      for _, required := range requiredFields {
+        if field == required {
+          return post, http.StatusUnprocessableEntity, fmt.Errorf("%s is required and can't be removed", field)
+        }
+      }
+      delete(document, field)
+    default:
+      return post, http.StatusBadRequest, fmt.Errorf("unsupported operation %q", operation.Op)
+    }
+  }
+
+  data, err = json.Marshal(document)
+  if err != nil {
+    return post, http.StatusInternalServerError, err
+  }
+
+  // DisallowUnknownFields makes the decoder fail on fields the Post struct doesn't have, e.g. an "add" of "/Foo".
+  var patched Post
+  decoder := json.NewDecoder(bytes.NewReader(data))
+  decoder.DisallowUnknownFields()
+  if err := decoder.Decode(&patched); err != nil {
+    return post, http.StatusUnprocessableEntity, fmt.Errorf("patched post is invalid: %v", err)
+  }
+  // The overlay isn't part of the JSON, carry it over so savePosts can still take it off.
+  patched.overlay = post.overlay
+  return patched, http.StatusOK, nil
+}
+
+/*
+  patchField turns a JSON Pointer like "/Title" into the field name it points to. Pointers escape "~" as "~0" and "/" as "~1". Nested pointers (e.g. "/Title/0") are not supported since all the post fields are top level.
+*/
+func patchField(path string) (string, error) {
+  if !strings.HasPrefix(path, "/") || strings.Count(path, "/") != 1 {
+    return "", fmt.Errorf("unsupported path %q", path)
+  }
+  field := strings.NewReplacer("~1", "/", "~0", "~").Replace(path[1:])
+  return field, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+  "time"
+)
+
+// patchRequest returns a PATCH of post 1 with the given JSON Patch document.
+func patchRequest(document string) *http.Request {
+  r := httptest.NewRequest(http.MethodPatch, "/posts/1", strings.NewReader(document))
+  r.Header.Set("Content-Type", "application/json-patch+json")
+  return r
+}
+
+func TestPatchReplace(t *testing.T) {
+  setup(t, testPosts()...)
+
+  w := serve("PATCH /posts/{id}", patchPost, patchRequest(`[{"op": "replace", "path": "/Title", "value": "A better title"}]`))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if got := decode[Post](t, w).Title; got != "A better title" {
+    t.Errorf("response: got Title %q", got)
+  }
+  stored := storedPosts(t)[0]
+  if stored.Title != "A better title" || stored.Content != "The content of the first post." {
+    t.Errorf("stored: got Title %q and Content %q", stored.Title, stored.Content)
+  }
+}
+
+func TestPatchInvalidOperations(t *testing.T) {
+  future := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+  tests := []struct {
+    name     string
+    document string
+    status   int
+  }{
+    {"unsupported op", `[{"op": "move", "from": "/Title", "path": "/Content"}]`, http.StatusBadRequest},
+    {"not a patch", `{"op": "replace"}`, http.StatusBadRequest},
+    {"required field", `[{"op": "remove", "path": "/Title"}]`, http.StatusUnprocessableEntity},
+    {"unknown field", `[{"op": "add", "path": "/Foo", "value": 1}]`, http.StatusUnprocessableEntity},
+    {"view count", `[{"op": "replace", "path": "/ViewCount", "value": 1000}]`, http.StatusUnprocessableEntity},
+    {"last viewed", `[{"op": "replace", "path": "/LastViewed", "value": "2025-06-01T00:00:00Z"}]`, http.StatusUnprocessableEntity},
+    {"updated at", `[{"op": "add", "path": "/UpdatedAt", "value": "2025-06-01T00:00:00Z"}]`, http.StatusUnprocessableEntity},
+    {"future created at", `[{"op": "replace", "path": "/CreatedAt", "value": "` + future + `"}]`, http.StatusUnprocessableEntity},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t, testPosts()...)
+      w := serve("PATCH /posts/{id}", patchPost, patchRequest(test.document))
+      if w.Code != test.status {
+        t.Fatalf("got status %d, want %d: %s", w.Code, test.status, w.Body)
+      }
+      if got := storedPosts(t)[0]; got.Title != "First post" || got.ViewCount != 0 || got.CreatedAt != "2025-01-01T10:00:00Z" || got.UpdatedAt != "" {
+        t.Errorf("the post was modified: %+v", got)
+      }
+    })
+  }
+}
+
+func TestPatchCreatedAtIsStoredInUTC(t *testing.T) {
+  setup(t, testPosts()...)
+
+  w := serve("PATCH /posts/{id}", patchPost, patchRequest(`[{"op": "replace", "path": "/CreatedAt", "value": "2024-12-31T12:00:00+02:00"}]`))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if got := storedPosts(t)[0].CreatedAt; got != "2024-12-31T10:00:00Z" {
+    t.Errorf("got CreatedAt %q, want 2024-12-31T10:00:00Z", got)
+  }
+}
+
+func TestPatchIsCheckedLikeANewPost(t *testing.T) {
+  tests := []struct {
+    name     string
+    document string
+    field    string
+  }{
+    {"profanity in the Content", `[{"op": "replace", "path": "/Content", "value": "Well, d4rn."}]`, "Content"},
+    {"profanity in the Title", `[{"op": "replace", "path": "/Title", "value": "Darn it"}]`, "Title"},
+    {"too short", `[{"op": "replace", "path": "/Content", "value": "Short."}]`, "Content"},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t, testPosts()...)
+      config.ProfanityMode = profanityReject
+      config.ProfanityWords = []string{"darn"}
+      config.MinContentLength = 20
+
+      w := serve("PATCH /posts/{id}", patchPost, patchRequest(test.document))
+      if w.Code != http.StatusUnprocessableEntity {
+        t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+      }
+      if got := decode[invalidPost](t, w).Fields; got[test.field] == "" {
+        t.Errorf("got fields %v, want a %s error", got, test.field)
+      }
+      if got := storedPosts(t)[0]; got.Title != "First post" || got.Content != "The content of the first post." {
+        t.Errorf("the post was modified: %+v", got)
+      }
+    })
+  }
+}
+
+func TestPatchMasksProfanity(t *testing.T) {
+  setup(t, testPosts()...)
+  config.ProfanityMode = profanityMask
+  config.ProfanityWords = []string{"darn"}
+
+  w := serve("PATCH /posts/{id}", patchPost, patchRequest(`[{"op": "replace", "path": "/Content", "value": "Well, d4rn it."}]`))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if got := decode[Post](t, w).Content; got != "Well, **** it." {
+    t.Errorf("response: got Content %q", got)
+  }
+  if got := storedPosts(t)[0].Content; got != "Well, **** it." {
+    t.Errorf("stored: got Content %q", got)
+  }
+}
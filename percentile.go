@@ -0,0 +1,110 @@
+package main
+
+import (
+  "math"
+  "net/http"
+)
+
+/*
+  INCREMENT VIEW HANDLER
+
+  POST /posts/{id}/increment-view counts a view of the post, just like showing it does, and says how it ranks against the other posts for a "you're in the top 10%" kind of message:
+
+  {"id": "3", "views": 120, "percentile": 80, "top_percent": 40}
+
+  - percentile: the share of the posts with at most as many views, the post itself included.
+  - top_percent: the share of the posts with at least as many views, the post itself included.
+
+  Of 5 posts the most viewed is at the 100th percentile and in the top 20%. A post alone is at the 100th percentile and in the top 100%, it's both the most and the least viewed. The post is compared with the posts of the public lists, see visiblePosts.
+
+  Counting a view doesn't write to the file right away (see views.go), so this runs without postsMu like the read routes.
+*/
+func incrementView(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  post := &posts[i]
+  countView(post, r)
+  saveCountedViews(r)
+
+  others := otherListedPosts(posts, post.ID)
+  total, atMost, atLeast := len(others), 0, 0
+  for _, other := range others {
+    if other.ViewCount <= post.ViewCount {
+      atMost++
+    }
+    if other.ViewCount >= post.ViewCount {
+      atLeast++
+    }
+  }
+  // The post itself counts too.
+  total, atMost, atLeast = total+1, atMost+1, atLeast+1
+
+  writeJSON(w, http.StatusOK, map[string]any{
+    "id":          post.ID,
+    "views":       post.ViewCount,
+    "percentile":  percent(atMost, total),
+    "top_percent": percent(atLeast, total),
+  })
+}
+
+// percent returns part of total as a percentage rounded to one decimal.
+func percent(part, total int) float64 {
+  return math.Round(1000*float64(part)/float64(total)) / 10
+}
+
+// otherListedPosts returns the posts of the public lists, except the one with the given id. The post itself counts whether it's listed publicly or not.
+func otherListedPosts(posts []Post, id PostID) []Post {
+  others := []Post{}
+  for _, post := range visiblePosts(posts) {
+    if post.ID != id {
+      others = append(others, post)
+    }
+  }
+  return others
+}
+
+/*
+  VIEW RANK HANDLER
+
+  GET /posts/{id}/view-rank tells where the post stands when the posts are sorted by views, most viewed first, for a "ranked #3" badge:
+
+  {"id": "3", "views": 120, "rank": 3, "total": 12}
+
+  Posts with as many views share a rank and the next rank is skipped (competition ranking, "1224"): with views of 50, 30, 30 and 10 the ranks are 1, 2, 2 and 4. Like increment-view, the post is compared with the posts of the public lists.
+*/
+func viewRank(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  post := posts[i]
+  others := otherListedPosts(posts, post.ID)
+  rank := 1
+  for _, other := range others {
+    if other.ViewCount > post.ViewCount {
+      rank++
+    }
+  }
+
+  writeJSON(w, http.StatusOK, map[string]any{"id": post.ID, "views": post.ViewCount, "rank": rank, "total": len(others) + 1})
+}
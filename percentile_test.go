@@ -0,0 +1,76 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestIncrementViewPercentile(t *testing.T) {
+  posts := append(testPosts(),
+    Post{ID: "4", Title: "Fourth post", Content: "Fourth", Author: "Jane Doe", CreatedAt: "2025-01-04T10:00:00Z"},
+    Post{ID: "5", Title: "Hidden post", Content: "Hidden", Author: "Jane Doe", CreatedAt: "2025-01-05T10:00:00Z", ViewCount: 1000, Hidden: true},
+  )
+  posts[0].ViewCount = 9
+  posts[1].ViewCount = 20
+  posts[2].ViewCount = 10
+  posts[3].ViewCount = 5
+  tests := []struct {
+    id                     string
+    views                  float64
+    percentile, topPercent float64
+  }{
+    // 10 views after the increment, tied with post 3: 3 of 4 posts have at most as many, 3 at least as many.
+    {"1", 10, 75, 75},
+    {"2", 21, 100, 25},
+    {"4", 6, 25, 100},
+  }
+  for _, test := range tests {
+    setup(t, posts...)
+    w := serve("POST /posts/{id}/increment-view", incrementView, httptest.NewRequest(http.MethodPost, "/posts/"+test.id+"/increment-view", nil))
+    if w.Code != http.StatusOK {
+      t.Fatalf("post %s: got status %d, want %d", test.id, w.Code, http.StatusOK)
+    }
+    got := decode[map[string]any](t, w)
+    if got["views"] != test.views || got["percentile"] != test.percentile || got["top_percent"] != test.topPercent {
+      t.Errorf("post %s: got %v, want %v views, percentile %v and top %v%%", test.id, got, test.views, test.percentile, test.topPercent)
+    }
+  }
+
+  // A post alone is both the most and the least viewed.
+  setup(t, testPosts()[0])
+  w := serve("POST /posts/{id}/increment-view", incrementView, httptest.NewRequest(http.MethodPost, "/posts/1/increment-view", nil))
+  if got := decode[map[string]any](t, w); got["percentile"] != float64(100) || got["top_percent"] != float64(100) {
+    t.Errorf("single post: got %v, want the 100th percentile and the top 100%%", got)
+  }
+  if w := serve("POST /posts/{id}/increment-view", incrementView, httptest.NewRequest(http.MethodPost, "/posts/9/increment-view", nil)); w.Code != http.StatusNotFound {
+    t.Errorf("missing post: got status %d, want %d", w.Code, http.StatusNotFound)
+  }
+}
+
+func TestViewRankTies(t *testing.T) {
+  posts := append(testPosts(),
+    Post{ID: "4", Title: "Fourth post", Content: "Fourth", Author: "Jane Doe", CreatedAt: "2025-01-04T10:00:00Z", ViewCount: 10},
+    Post{ID: "5", Title: "Deleted post", Content: "Deleted", Author: "Jane Doe", CreatedAt: "2025-01-05T10:00:00Z", ViewCount: 99, Deleted: true},
+  )
+  posts[0].ViewCount = 50
+  posts[1].ViewCount = 30
+  posts[2].ViewCount = 30
+  setup(t, posts...)
+
+  // Competition ranking: 50, 30, 30 and 10 views rank 1, 2, 2 and 4.
+  for id, want := range map[string]float64{"1": 1, "2": 2, "3": 2, "4": 4} {
+    w := serve("GET /posts/{id}/view-rank", viewRank, httptest.NewRequest(http.MethodGet, "/posts/"+id+"/view-rank", nil))
+    if w.Code != http.StatusOK {
+      t.Fatalf("post %s: got status %d, want %d", id, w.Code, http.StatusOK)
+    }
+    got := decode[map[string]any](t, w)
+    if got["rank"] != want || got["total"] != float64(4) {
+      t.Errorf("post %s: got rank %v of %v, want %v of 4", id, got["rank"], got["total"], want)
+    }
+  }
+  // Looking at the rank isn't a view.
+  if got := storedPosts(t)[0].ViewCount; got != 50 {
+    t.Errorf("got %d views, want 50", got)
+  }
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+  "net/http"
+  "net/url"
+)
+
+/*
+  PERMALINK HANDLER
+
+  GET /posts/{id}/permalink returns the canonical address of the post, for copy-link buttons:
+
+  {"url": "http://localhost:3000/posts/my-first-post"}
+
+  It's PERMALINK_BASE followed by the slug of the post, see slug.go, or by its ID for the posts that have no slug. PERMALINK_BASE defaults to BASE_URL + "/posts", set it when the posts are shown by a frontend at other addresses, e.g. https://blog.example.com/p.
+*/
+func postPermalink(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, map[string]string{"url": permalink(posts[i])})
+}
+
+// permalink is the canonical address of the post, the one the feed, the sitemap and the Open Graph metadata link to as well.
+func permalink(post Post) string {
+  base := config.PermalinkBase
+  if base == "" {
+    base = config.BaseURL + "/posts"
+  }
+  name := post.Slug
+  if name == "" {
+    name = string(post.ID)
+  }
+  // Slugs can have non-ASCII letters, PathEscape percent-encodes them.
+  return base + "/" + url.PathEscape(name)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+  "encoding/xml"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+// postURL returns the permalink of post id.
+func postURL(t *testing.T, id string) string {
+  t.Helper()
+  w := serve("GET /posts/{id}/permalink", postPermalink, httptest.NewRequest(http.MethodGet, "/posts/"+id+"/permalink", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  return decode[map[string]string](t, w)["url"]
+}
+
+func TestPermalink(t *testing.T) {
+  posts := testPosts()
+  posts[0].Slug = "first-post"
+  posts[1].Slug = "été-à-paris"
+  tests := []struct {
+    name, base, id, want string
+  }{
+    {"slug", "", "1", "https://blog.example.com/posts/first-post"},
+    {"no slug", "", "3", "https://blog.example.com/posts/3"},
+    {"non-ASCII slug", "", "2", "https://blog.example.com/posts/%C3%A9t%C3%A9-%C3%A0-paris"},
+    {"PERMALINK_BASE", "https://example.com/p", "1", "https://example.com/p/first-post"},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t, posts...)
+      config.BaseURL = "https://blog.example.com"
+      config.PermalinkBase = test.base
+      if got := postURL(t, test.id); got != test.want {
+        t.Errorf("got %q, want %q", got, test.want)
+      }
+    })
+  }
+
+  setup(t, posts...)
+  if w := serve("GET /posts/{id}/permalink", postPermalink, httptest.NewRequest(http.MethodGet, "/posts/9/permalink", nil)); w.Code != http.StatusNotFound {
+    t.Errorf("unknown post: got status %d, want %d", w.Code, http.StatusNotFound)
+  }
+}
+
+func TestPermalinkIsTheOnlyURLOfAPost(t *testing.T) {
+  posts := testPosts()[:1]
+  posts[0].Slug = "first-post"
+  setup(t, posts...)
+  config.PermalinkBase = "https://example.com/p"
+  want := postURL(t, "1")
+
+  w := serve("GET /feed.xml", feed, httptest.NewRequest(http.MethodGet, "/feed.xml", nil))
+  var rss struct {
+    Items []rssItem `xml:"channel>item"`
+  }
+  if err := xml.Unmarshal(w.Body.Bytes(), &rss); err != nil {
+    t.Fatal(err)
+  }
+  if len(rss.Items) != 1 || rss.Items[0].Link != want || rss.Items[0].GUID != want {
+    t.Errorf("feed: got items %+v, want the link %q", rss.Items, want)
+  }
+
+  w = serve("GET /posts/{id}/og", postOpenGraph, httptest.NewRequest(http.MethodGet, "/posts/1/og", nil))
+  if got := decode[map[string]string](t, w)["og:url"]; got != want {
+    t.Errorf("og:url: got %q, want %q", got, want)
+  }
+
+  w = serve("GET /sitemap.xml", sitemap, httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+  var set struct {
+    URLs []sitemapURL `xml:"url"`
+  }
+  if err := xml.Unmarshal(w.Body.Bytes(), &set); err != nil {
+    t.Fatal(err)
+  }
+  if len(set.URLs) != 1 || set.URLs[0].Loc != want {
+    t.Errorf("sitemap: got %+v, want the loc %q", set.URLs, want)
+  }
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+  "fmt"
+  "net/http"
+  "strconv"
+  "strings"
+)
+
+/*
+  PRETTY PRINTING
+
+  JSON is compact for scripts and indented for people: a request that looks like it comes from a browser, sending "Accept: text/html" or a User-Agent containing "Mozilla", gets its JSON indented. ?pretty=true or ?pretty=false overrides the guess, for curl or for a browser extension that wants the compact form:
+
+  curl "http://localhost:3000/posts/1?pretty=true"
+
+  The choice is made once per request by prettyPrint and kept in the prettyWriter it passes down, writeJSON looks for it with prettyChoice. When the choice was guessed the same URL can answer differently depending on the Accept and User-Agent headers, writeJSON adds "Vary: Accept, User-Agent" to say so to the caches.
+*/
+type prettyWriter struct {
+  http.ResponseWriter
+  pretty bool
+  // guessed is false when ?pretty= made the choice.
+  guessed bool
+}
+
+func (p *prettyWriter) Unwrap() http.ResponseWriter {
+  return p.ResponseWriter
+}
+
+func prettyPrint(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    pretty, guessed := strings.Contains(r.Header.Get("Accept"), "text/html") || strings.Contains(r.Header.Get("User-Agent"), "Mozilla"), true
+    if value := r.URL.Query().Get("pretty"); value != "" {
+      parsed, err := strconv.ParseBool(value)
+      if err != nil {
+        jsonError(w, http.StatusBadRequest, fmt.Sprintf("pretty must be true or false, got %q", value))
+        return
+      }
+      pretty, guessed = parsed, false
+    }
+    next.ServeHTTP(&prettyWriter{ResponseWriter: w, pretty: pretty, guessed: guessed}, r)
+  })
+}
+
+// prettyChoice tells whether the JSON written to w should be indented and whether that was guessed from the request headers, going through the writers wrapping the prettyWriter.
+func prettyChoice(w http.ResponseWriter) (pretty, guessed bool) {
+  for {
+    if p, ok := w.(*prettyWriter); ok {
+      return p.pretty, p.guessed
+    }
+    wrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+    if !ok {
+      return false, false
+    }
+    w = wrapper.Unwrap()
+  }
+}
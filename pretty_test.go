@@ -0,0 +1,53 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func TestPrettyPrint(t *testing.T) {
+  const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+  tests := []struct {
+    name, query, accept, userAgent string
+    pretty                          bool
+    vary                            string
+  }{
+    {"browser", "", browserAccept, "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0", true, "Accept, User-Agent"},
+    {"script", "", "*/*", "curl/8.5.0", false, "Accept, User-Agent"},
+    // Either header is enough to look like a browser.
+    {"browser by its User-Agent", "", "application/json", "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0", true, "Accept, User-Agent"},
+    {"browser by its Accept", "", browserAccept, "", true, "Accept, User-Agent"},
+    {"script asking for pretty", "?pretty=true", "*/*", "curl/8.5.0", true, ""},
+    {"browser asking for compact", "?pretty=false", browserAccept, "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0", false, ""},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t, testPosts()...)
+      r := httptest.NewRequest(http.MethodGet, "/posts/1"+test.query, nil)
+      r.Header.Set("Accept", test.accept)
+      r.Header.Set("User-Agent", test.userAgent)
+      w := serve("GET /posts/{id}", prettyPrint(http.HandlerFunc(show)).ServeHTTP, r)
+      if w.Code != http.StatusOK {
+        t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+      }
+      // Compact JSON is a single line ending with a newline.
+      if got := strings.Count(w.Body.String(), "\n") > 1; got != test.pretty {
+        t.Errorf("got indented %t, want %t: %s", got, test.pretty, w.Body)
+      }
+      if got := w.Header().Get("Vary"); got != test.vary {
+        t.Errorf("got Vary %q, want %q", got, test.vary)
+      }
+      if got := decode[Post](t, w).Title; got != "First post" {
+        t.Errorf("got Title %q, want %q", got, "First post")
+      }
+    })
+  }
+
+  setup(t, testPosts()...)
+  w := serve("GET /posts/{id}", prettyPrint(http.HandlerFunc(show)).ServeHTTP, httptest.NewRequest(http.MethodGet, "/posts/1?pretty=maybe", nil))
+  if w.Code != http.StatusBadRequest {
+    t.Errorf("invalid pretty: got status %d, want %d", w.Code, http.StatusBadRequest)
+  }
+}
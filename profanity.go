@@ -0,0 +1,86 @@
+package main
+
+import (
+  "strings"
+  "unicode"
+)
+
+/*
+  PROFANITY FILTER
+
+  create, PATCH and the bulk update can check the Title and Content of posts against a list of words, PROFANITY_WORDS (a comma separated list as an environment variable). PROFANITY_MODE decides what happens with a post using them:
+
+  off     nothing, the default
+  reject  the post isn't saved, the answer is a 422 naming the fields
+  mask    the words are replaced with asterisks, "darn it" becomes "**** it"
+
+  Matching ignores case and undoes basic leetspeak, so "D4rn" is caught too. Only whole words match: a listed "ass" doesn't catch "class".
+*/
+const (
+  profanityOff    = "off"
+  profanityReject = "reject"
+  profanityMask   = "mask"
+)
+
+// leet maps the characters standing in for letters in leetspeak to the letters. "!" is left out, it ends too many sentences.
+var leet = map[rune]rune{'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's'}
+
+// unleet lowercases the word and replaces its leetspeak characters.
+func unleet(word string) string {
+  return strings.Map(func(r rune) rune {
+    if letter, ok := leet[r]; ok {
+      return letter
+    }
+    return unicode.ToLower(r)
+  }, word)
+}
+
+// isWordRune tells whether r can be part of a word, leetspeak included.
+func isWordRune(r rune) bool {
+  _, ok := leet[r]
+  return ok || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// profane tells whether the word is one of PROFANITY_WORDS.
+func profane(word string) bool {
+  word = unleet(word)
+  for _, listed := range config.ProfanityWords {
+    if word == unleet(listed) {
+      return true
+    }
+  }
+  return false
+}
+
+// hasProfanity tells whether text uses any of PROFANITY_WORDS.
+func hasProfanity(text string) bool {
+  for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !isWordRune(r) }) {
+    if profane(word) {
+      return true
+    }
+  }
+  return false
+}
+
+// maskProfanity replaces every rune of the words of PROFANITY_WORDS in text with an asterisk, leaving the rest untouched.
+func maskProfanity(text string) string {
+  var masked, word strings.Builder
+  flush := func() {
+    if profane(word.String()) {
+      masked.WriteString(strings.Repeat("*", len([]rune(word.String()))))
+    } else {
+      masked.WriteString(word.String())
+    }
+    word.Reset()
+  }
+  for _, r := range text {
+    if isWordRune(r) {
+      word.WriteRune(r)
+      continue
+    }
+    flush()
+    masked.WriteRune(r)
+  }
+  flush()
+  return masked.String()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+  "net/http"
+  "testing"
+)
+
+func TestProfanityReject(t *testing.T) {
+  setup(t)
+  config.ProfanityMode = profanityReject
+  config.ProfanityWords = []string{"darn", "heck"}
+
+  post := Post{Title: "What the H3CK", Content: "It's D4rn cold.", Author: "Jane Doe"}
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", post))
+  if w.Code != http.StatusUnprocessableEntity {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+  }
+  fields := decode[invalidPost](t, w).Fields
+  if fields["Title"] != "Title uses words that aren't allowed" || fields["Content"] != "Content uses words that aren't allowed" {
+    t.Errorf("got fields %v, want both the Title and the Content", fields)
+  }
+  if posts := storedPosts(t); len(posts) != 0 {
+    t.Errorf("got %d stored posts, want none", len(posts))
+  }
+
+  // Only whole words match.
+  post = Post{Title: "Checking darned things", Content: "Heckler's guide.", Author: "Jane Doe"}
+  if w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", post)); w.Code != http.StatusCreated {
+    t.Errorf("words containing listed ones: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+}
+
+func TestProfanityMask(t *testing.T) {
+  setup(t)
+  config.ProfanityMode = profanityMask
+  config.ProfanityWords = []string{"darn", "heck"}
+
+  post := Post{Title: "What the H3CK", Content: "It's D4rn cold, darn it.", Author: "Jane Doe"}
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", post))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  stored := storedPosts(t)[0]
+  if stored.Title != "What the ****" || stored.Content != "It's **** cold, **** it." {
+    t.Errorf("got Title %q and Content %q", stored.Title, stored.Content)
+  }
+}
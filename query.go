@@ -0,0 +1,40 @@
+package main
+
+import (
+  "net/http"
+  "slices"
+  "sort"
+  "strings"
+)
+
+/*
+  STRICT QUERY
+
+  A typo in a query parameter, like ?limt=10, is silently ignored and the client wonders why it gets every post. With STRICT_QUERY the read routes refuse the parameters they don't know with a 400 listing them:
+
+  {"error": "unknown query parameters: limt"}
+
+  Each read route declares the parameters it takes when it's registered, see handleRead. It's off by default so that existing clients sending extra parameters (cache busters, tracking tags...) keep working.
+*/
+func strictQuery(known []string, next http.HandlerFunc) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    if !config.StrictQuery {
+      next(w, r)
+      return
+    }
+    unknown := []string{}
+    for name := range r.URL.Query() {
+      // ?pretty is taken by every route, see pretty.go.
+      if !slices.Contains(known, name) && name != "pretty" {
+        unknown = append(unknown, name)
+      }
+    }
+    if len(unknown) > 0 {
+      // Maps have no order, sorting makes the message the same every time.
+      sort.Strings(unknown)
+      jsonError(w, http.StatusBadRequest, "unknown query parameters: "+strings.Join(unknown, ", "))
+      return
+    }
+    next(w, r)
+  }
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestStrictQuery(t *testing.T) {
+  setup(t, testPosts()...)
+  handler := strictQuery(listingParams, index)
+
+  // By default a typo is ignored.
+  if w := serve("/index", handler, httptest.NewRequest(http.MethodGet, "/index?limt=1", nil)); w.Code != http.StatusOK {
+    t.Fatalf("default: got status %d, want %d", w.Code, http.StatusOK)
+  }
+
+  config.StrictQuery = true
+  w := serve("/index", handler, httptest.NewRequest(http.MethodGet, "/index?limt=1&sort=title&zzz=1&aaa=2", nil))
+  if w.Code != http.StatusBadRequest {
+    t.Fatalf("strict: got status %d, want %d", w.Code, http.StatusBadRequest)
+  }
+  if got, want := decode[map[string]string](t, w)["error"], "unknown query parameters: aaa, limt, zzz"; got != want {
+    t.Errorf("got error %q, want %q", got, want)
+  }
+  if w := serve("/index", handler, httptest.NewRequest(http.MethodGet, "/index?limit=1&sort=title", nil)); w.Code != http.StatusOK {
+    t.Errorf("known parameters: got status %d, want %d", w.Code, http.StatusOK)
+  }
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+  "math"
+  "time"
+)
+
+/*
+  HYBRID RANKING
+
+  index?rank=hybrid blends how recent and how popular the posts are, for a feed that isn't only the newest posts nor always the same popular ones. Each post gets a score between 0 and 1:
+
+  score = w * recency + (1 - w) * popularity
+
+  - recency halves every week: 1 for a post created now, 0.5 a week ago, 0.25 two weeks ago... Posts without a valid CreatedAt get 0.
+  - popularity compares the views of the post with the most viewed of the listed posts, on a logarithmic scale so a handful of viral posts don't flatten everyone else: log(1+views) / log(1+maxViews).
+  - w is RANK_RECENCY_WEIGHT, 0.5 by default. 1 ranks by recency only and 0 by popularity only.
+*/
+const recencyHalfLife = 7 * 24 * time.Hour
+
+// hybridScores returns the score of each of the selected posts, in the same order.
+func hybridScores(posts []Post, selected []int, now time.Time) []float64 {
+  var maxViews int64
+  for _, i := range selected {
+    maxViews = max(maxViews, posts[i].ViewCount)
+  }
+
+  weight := config.RankRecencyWeight
+  scores := make([]float64, len(selected))
+  for n, i := range selected {
+    post := &posts[i]
+    recency := 0.0
+    if created, ok := parseTimestamp(post.CreatedAt); ok {
+      // Posts dated in the future count as brand new.
+      age := max(now.Sub(created), 0)
+      recency = math.Exp2(-float64(age) / float64(recencyHalfLife))
+    }
+    popularity := 0.0
+    if maxViews > 0 {
+      popularity = math.Log1p(float64(post.ViewCount)) / math.Log1p(float64(maxViews))
+    }
+    scores[n] = weight*recency + (1-weight)*popularity
+  }
+  return scores
+}
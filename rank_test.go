@@ -0,0 +1,40 @@
+package main
+
+import (
+  "net/http"
+  "slices"
+  "testing"
+  "time"
+)
+
+func TestHybridRank(t *testing.T) {
+  daysAgo := func(days int) string {
+    return time.Now().AddDate(0, 0, -days).UTC().Format(time.RFC3339)
+  }
+  posts := []Post{
+    {ID: "recent", Title: "Recent but unpopular", Content: "Recent", Author: "Jane Doe", CreatedAt: daysAgo(1), ViewCount: 10},
+    {ID: "old", Title: "Old but popular", Content: "Old", Author: "Jane Doe", CreatedAt: daysAgo(60), ViewCount: 10000},
+    {ID: "middle", Title: "Neither", Content: "Middle", Author: "Jane Doe", CreatedAt: daysAgo(30), ViewCount: 100},
+  }
+  tests := []struct {
+    weight float64
+    want   []PostID
+  }{
+    {0.5, []PostID{"recent", "old", "middle"}},
+    {1, []PostID{"recent", "middle", "old"}},
+    {0, []PostID{"old", "middle", "recent"}},
+  }
+  for _, test := range tests {
+    setup(t, posts...)
+    config.RankRecencyWeight = test.weight
+    if got := listedIDs(t, "?rank=hybrid"); !slices.Equal(got, test.want) {
+      t.Errorf("weight %v: got %v, want %v", test.weight, got, test.want)
+    }
+  }
+
+  for _, query := range []string{"?rank=hybrid&sort=views", "?rank=best"} {
+    if w := listPosts(query); w.Code != http.StatusBadRequest {
+      t.Errorf("%s: got status %d, want %d", query, w.Code, http.StatusBadRequest)
+    }
+  }
+}
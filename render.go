@@ -0,0 +1,115 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "net/http"
+  "strings"
+  "unicode/utf8"
+
+  "github.com/yuin/goldmark"
+  "github.com/yuin/goldmark/ast"
+  "github.com/yuin/goldmark/text"
+)
+
+/*
+  RENDERING
+
+  The Content of a post is written in Markdown. goldmark parses it into a tree of nodes (an AST), which we either render to HTML or walk to pull the plain text out of, for the excerpt.
+
+  Raw HTML in the Markdown is left out of the output by default, so a post can't inject scripts into the page.
+*/
+type renderedPost struct {
+  Title   string
+  Author  string
+  HTML    string
+  Excerpt string
+}
+
+// excerptLength is the maximum number of characters of an excerpt, not counting the ellipsis.
+const excerptLength = 200
+
+// render returns the Content of the post as HTML, along with its excerpt.
+func render(post Post) (renderedPost, error) {
+  source := []byte(post.Content)
+  doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+
+  var html bytes.Buffer
+  if err := goldmark.DefaultRenderer().Render(&html, source, doc); err != nil {
+    return renderedPost{}, err
+  }
+  return renderedPost{
+    Title:   post.Title,
+    Author:  post.Author,
+    HTML:    html.String(),
+    Excerpt: excerpt(plainText(doc, source), excerptLength),
+  }, nil
+}
+
+// plainText collects the text of the document, without any of the Markdown syntax.
+func plainText(doc ast.Node, source []byte) string {
+  var b strings.Builder
+  ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+    switch node := n.(type) {
+    case *ast.Text:
+      if entering {
+        b.Write(node.Segment.Value(source))
+        if node.SoftLineBreak() || node.HardLineBreak() {
+          b.WriteByte(' ')
+        }
+      }
+    case *ast.String:
+      if entering {
+        b.Write(node.Value)
+      }
+    case *ast.FencedCodeBlock, *ast.CodeBlock:
+      // Code doesn't make for a good excerpt.
+      return ast.WalkSkipChildren, nil
+    default:
+      // Blocks (paragraphs, headings...) are separated by a space.
+      if !entering && n.Type() == ast.TypeBlock {
+        b.WriteByte(' ')
+      }
+    }
+    return ast.WalkContinue, nil
+  })
+  return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// excerpt cuts s down to at most limit characters, at the end of a word, and adds an ellipsis when something was cut.
+func excerpt(s string, limit int) string {
+  if utf8.RuneCountInString(s) <= limit {
+    return s
+  }
+  // Slicing a string slices its bytes, converting to runes first keeps multi-byte characters whole.
+  cut := string([]rune(s)[:limit])
+  if i := strings.LastIndexByte(cut, ' '); i > 0 {
+    cut = cut[:i]
+  }
+  return strings.TrimRight(cut, " .,;:") + "…"
+}
+
+/*
+  PREVIEW HANDLER
+
+  Renders a post the way it would be shown, without saving it, so authors can check their Markdown before creating the post. Only the Content is required.
+*/
+func preview(w http.ResponseWriter, r *http.Request) {
+  var post Post
+  if err := json.NewDecoder(r.Body).Decode(&post); err != nil {
+    jsonError(w, http.StatusBadRequest, "invalid JSON body")
+    return
+  }
+  defer r.Body.Close()
+  if strings.TrimSpace(post.Content) == "" {
+    jsonError(w, http.StatusUnprocessableEntity, "Content is required")
+    return
+  }
+
+  rendered, err := render(post)
+  if err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error rendering post")
+    return
+  }
+  writeJSON(w, http.StatusOK, rendered)
+}
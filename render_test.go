@@ -0,0 +1,44 @@
+package main
+
+import (
+  "net/http"
+  "strings"
+  "testing"
+)
+
+func TestPreviewRendersWithoutSaving(t *testing.T) {
+  setup(t, testPosts()...)
+
+  post := Post{Title: "Draft", Author: "Jane Doe", Content: "# Hello\n\nSome **bold** text.\n\n<script>alert(1)</script>"}
+  w := serve("POST /posts/preview", preview, newJSONRequest(t, http.MethodPost, "/posts/preview", post))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  got := decode[renderedPost](t, w)
+  if !strings.Contains(got.HTML, "<h1>Hello</h1>") || !strings.Contains(got.HTML, "<strong>bold</strong>") {
+    t.Errorf("got HTML %q, want the rendered Markdown", got.HTML)
+  }
+  if strings.Contains(got.HTML, "<script>") {
+    t.Errorf("got HTML %q, want the raw HTML left out", got.HTML)
+  }
+  if got.Excerpt != "Hello Some bold text." || got.Title != "Draft" {
+    t.Errorf("got Title %q and Excerpt %q", got.Title, got.Excerpt)
+  }
+  if got := len(storedPosts(t)); got != 3 {
+    t.Errorf("got %d stored posts, want the 3 there were", got)
+  }
+
+  w = serve("POST /posts/preview", preview, newJSONRequest(t, http.MethodPost, "/posts/preview", Post{Title: "Draft"}))
+  if w.Code != http.StatusUnprocessableEntity {
+    t.Errorf("no Content: got status %d, want %d", w.Code, http.StatusUnprocessableEntity)
+  }
+}
+
+func TestExcerptCutsAtAWord(t *testing.T) {
+  if got := excerpt("The quick brown fox jumps", 12); got != "The quick…" {
+    t.Errorf("got %q, want %q", got, "The quick…")
+  }
+  if got := excerpt("Short", 12); got != "Short" {
+    t.Errorf("got %q, want the whole text", got)
+  }
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+  "bytes"
+  "encoding"
+  "encoding/json"
+  "fmt"
+  "net/http"
+  "reflect"
+  "sort"
+  "strings"
+  "unicode"
+)
+
+/*
+  JSON RESPONSES
+
+  Every JSON response goes through writeJSON so they all share the same headers and key casing.
+
+  The struct tags on Post use PascalCase keys and that's what we keep in posts.json. Clients can get camelCase or snake_case keys instead by setting JSON_CASE, in which case the value is encoded by encodeCased below rather than by encoding/json directly. Only the keys that come from struct fields are renamed, map keys are data and are left as they are.
+*/
+const (
+  pascalCase = "pascal"
+  camelCase  = "camel"
+  snakeCase  = "snake"
+)
+
+// writeJSON writes v as the JSON body of the response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+  var buf bytes.Buffer
+  if err := encodeJSON(&buf, v); err != nil {
+    http.Error(w, "Error encoding response", http.StatusInternalServerError)
+    return
+  }
+  // Browsers get indented JSON, see pretty.go.
+  pretty, guessed := prettyChoice(w)
+  if guessed {
+    w.Header().Add("Vary", "Accept, User-Agent")
+  }
+  if pretty {
+    var indented bytes.Buffer
+    json.Indent(&indented, buf.Bytes(), "", "  ")
+    buf = indented
+  }
+  w.Header().Set("Content-Type", "application/json")
+  w.WriteHeader(status)
+  w.Write(buf.Bytes())
+}
+
+// jsonError writes an error response with a {"error": message} JSON body.
+func jsonError(w http.ResponseWriter, status int, message string) {
+  writeJSON(w, status, map[string]string{"error": message})
+}
+
+// encodeJSON writes v followed by a newline, just like json.Encoder does.
+func encodeJSON(buf *bytes.Buffer, v any) error {
+  // Timestamps are converted to the configured time zone on the way out, see timezone.go.
+  v = forOutput(v)
+  if config.JSONCase == pascalCase {
+    return json.NewEncoder(buf).Encode(v)
+  }
+  if err := encodeCased(buf, reflect.ValueOf(v)); err != nil {
+    return err
+  }
+  buf.WriteByte('\n')
+  return nil
+}
+
+/*
+  REFLECTION
+
+  The reflect package lets a program inspect the type and value of a variable at run time. encoding/json relies on it to walk structs field by field, and so does encodeCased: it follows the same rules for struct tags ("-", omitempty and embedded structs) but renames every key on the way out.
+
+  Values that know how to marshal themselves (time.Time, json.RawMessage, ...) are handed to encoding/json untouched.
+*/
+var (
+  marshalerType     = reflect.TypeFor[json.Marshaler]()
+  textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+func encodeCased(buf *bytes.Buffer, v reflect.Value) error {
+  if !v.IsValid() {
+    buf.WriteString("null")
+    return nil
+  }
+  if v.Type().Implements(marshalerType) || v.Type().Implements(textMarshalerType) {
+    return encodePlain(buf, v)
+  }
+
+  switch v.Kind() {
+  case reflect.Pointer, reflect.Interface:
+    if v.IsNil() {
+      buf.WriteString("null")
+      return nil
+    }
+    return encodeCased(buf, v.Elem())
+  case reflect.Struct:
+    buf.WriteByte('{')
+    first := true
+    if err := encodeFields(buf, v, &first); err != nil {
+      return err
+    }
+    buf.WriteByte('}')
+  case reflect.Map:
+    if v.IsNil() {
+      buf.WriteString("null")
+      return nil
+    }
+    keys := v.MapKeys()
+    names := make([]string, len(keys))
+    for i, key := range keys {
+      names[i] = fmt.Sprint(key.Interface())
+    }
+    order := make([]int, len(keys))
+    for i := range order {
+      order[i] = i
+    }
+    sort.Slice(order, func(a, b int) bool { return names[order[a]] < names[order[b]] })
+
+    buf.WriteByte('{')
+    for n, i := range order {
+      if n > 0 {
+        buf.WriteByte(',')
+      }
+      name, _ := json.Marshal(names[i])
+      buf.Write(name)
+      buf.WriteByte(':')
+      if err := encodeCased(buf, v.MapIndex(keys[i])); err != nil {
+        return err
+      }
+    }
+    buf.WriteByte('}')
+  case reflect.Slice, reflect.Array:
+    // []byte is encoded as a base64 string by encoding/json.
+    if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+      return encodePlain(buf, v)
+    }
+    buf.WriteByte('[')
+    for i := 0; i < v.Len(); i++ {
+      if i > 0 {
+        buf.WriteByte(',')
+      }
+      if err := encodeCased(buf, v.Index(i)); err != nil {
+        return err
+      }
+    }
+    buf.WriteByte(']')
+  default:
+    return encodePlain(buf, v)
+  }
+  return nil
+}
+
+// encodeFields writes the fields of a struct, inlining the fields of embedded structs like encoding/json does.
+func encodeFields(buf *bytes.Buffer, v reflect.Value, first *bool) error {
+  for i := 0; i < v.NumField(); i++ {
+    field := v.Type().Field(i)
+    value := v.Field(i)
+
+    tag := field.Tag.Get("json")
+    if tag == "-" {
+      continue
+    }
+    name, options, _ := strings.Cut(tag, ",")
+
+    if field.Anonymous && name == "" {
+      for value.Kind() == reflect.Pointer {
+        if value.IsNil() {
+          break
+        }
+        value = value.Elem()
+      }
+      if value.Kind() == reflect.Struct {
+        if err := encodeFields(buf, value, first); err != nil {
+          return err
+        }
+        continue
+      }
+    }
+    if !field.IsExported() {
+      continue
+    }
+    if strings.Contains(options, "omitempty") && isEmptyValue(value) {
+      continue
+    }
+    if name == "" {
+      name = field.Name
+    }
+
+    if !*first {
+      buf.WriteByte(',')
+    }
+    *first = false
+    key, _ := json.Marshal(convertCase(name, config.JSONCase))
+    buf.Write(key)
+    buf.WriteByte(':')
+    if err := encodeCased(buf, value); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+func encodePlain(buf *bytes.Buffer, v reflect.Value) error {
+  data, err := json.Marshal(v.Interface())
+  if err != nil {
+    return err
+  }
+  buf.Write(data)
+  return nil
+}
+
+// isEmptyValue follows the omitempty rules of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+  switch v.Kind() {
+  case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+    return v.Len() == 0
+  case reflect.Struct:
+    return false
+  }
+  return v.IsZero()
+}
+
+/*
+  convertCase renames a PascalCase key ("LastViewed", "ID") to the requested casing: "lastViewed" / "id" for camel and "last_viewed" / "id" for snake.
+*/
+func convertCase(name string, keyCase string) string {
+  words := splitWords(name)
+  switch keyCase {
+  case camelCase:
+    for i, word := range words {
+      word = strings.ToLower(word)
+      if i > 0 {
+        word = strings.ToUpper(word[:1]) + word[1:]
+      }
+      words[i] = word
+    }
+    return strings.Join(words, "")
+  case snakeCase:
+    for i, word := range words {
+      words[i] = strings.ToLower(word)
+    }
+    return strings.Join(words, "_")
+  }
+  return name
+}
+
+/*
+  splitWords breaks a key into words at every lower-to-upper case change, keeping runs of capitals (acronyms like ID or URL) together: "PinnedCommentID" becomes ["Pinned", "Comment", "ID"] and "URLPath" becomes ["URL", "Path"].
+*/
+func splitWords(name string) []string {
+  var words []string
+  runes := []rune(name)
+  start := 0
+  for i := 1; i < len(runes); i++ {
+    previous, current := runes[i-1], runes[i]
+    next := rune(0)
+    if i+1 < len(runes) {
+      next = runes[i+1]
+    }
+    lowerToUpper := !unicode.IsUpper(previous) && unicode.IsUpper(current)
+    acronymEnd := unicode.IsUpper(previous) && unicode.IsUpper(current) && unicode.IsLower(next)
+    if current == '_' || lowerToUpper || acronymEnd {
+      if word := strings.Trim(string(runes[start:i]), "_"); word != "" {
+        words = append(words, word)
+      }
+      start = i
+    }
+  }
+  if word := strings.Trim(string(runes[start:]), "_"); word != "" {
+    words = append(words, word)
+  }
+  return words
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestJSONCase(t *testing.T) {
+  tests := []struct {
+    jsonCase string
+    keys     []string
+  }{
+    {pascalCase, []string{"ID", "Title", "ViewCount", "LastViewed", "CreatedAt"}},
+    {camelCase, []string{"id", "title", "viewCount", "lastViewed", "createdAt"}},
+    {snakeCase, []string{"id", "title", "view_count", "last_viewed", "created_at"}},
+  }
+  for _, test := range tests {
+    t.Run(test.jsonCase, func(t *testing.T) {
+      setup(t, testPosts()...)
+      config.JSONCase = test.jsonCase
+
+      w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+      if w.Code != http.StatusOK {
+        t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+      }
+      got := decode[map[string]any](t, w)
+      for _, key := range test.keys {
+        if _, ok := got[key]; !ok {
+          t.Errorf("key %q is missing from %v", key, got)
+        }
+      }
+      if got[test.keys[1]] != "First post" {
+        t.Errorf("got %s %v, want %q", test.keys[1], got[test.keys[1]], "First post")
+      }
+    })
+  }
+}
+
+func TestJSONCaseLeavesTheFileAlone(t *testing.T) {
+  setup(t)
+  config.JSONCase = snakeCase
+
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"}))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  if _, ok := decode[map[string]any](t, w)["created_at"]; !ok {
+    t.Errorf("the response has no created_at: %s", w.Body)
+  }
+  // The stored posts keep their PascalCase keys, a snake_case file would load as empty posts.
+  if got := storedPosts(t)[0]; got.Title != "New" || got.CreatedAt == "" {
+    t.Errorf("got stored post %+v", got)
+  }
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+  "net/http"
+  "reflect"
+  "slices"
+  "strings"
+)
+
+/*
+  SCHEMA HANDLER
+
+  GET /schema/post describes a Post as a JSON Schema (https://json-schema.org), which form builders can turn into a form. The properties are read from the Post struct with reflection, so a new field shows up without touching this file, and the constraints come from the same places as the validation:
+
+  - required: requiredFields, see patch.go, except for the Author when there's a DEFAULT_AUTHOR
+  - readOnly: readOnlyFields, the fields managed by the service
+  - Content: MIN_CONTENT_LEN characters at least
+  - Slug: lowercase words separated by hyphens, see slug.go
+  - Lang: one of the configured Locales, see lang.go
+  - FeatureImage: a URL, see image.go
+  - Tags: MAX_TAGS and MAX_TAG_LENGTH, see tags.go
+*/
+func postSchema(w http.ResponseWriter, r *http.Request) {
+  schema := jsonSchema(reflect.TypeOf(Post{}))
+  schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+  schema["title"] = "Post"
+  properties := schema["properties"].(map[string]any)
+  required := requiredFields
+  // create fills in the Author when DEFAULT_AUTHOR is set, it can be left out then.
+  if config.DefaultAuthor != "" {
+    required = slices.DeleteFunc(slices.Clone(required), func(field string) bool { return field == "Author" })
+    properties["Author"].(map[string]any)["default"] = config.DefaultAuthor
+  }
+  schema["required"] = required
+  for _, field := range readOnlyFields {
+    properties[field].(map[string]any)["readOnly"] = true
+  }
+  for _, field := range required {
+    properties[field].(map[string]any)["minLength"] = 1
+  }
+  if config.MinContentLength > 1 {
+    properties["Content"].(map[string]any)["minLength"] = config.MinContentLength
+  }
+  properties["Slug"].(map[string]any)["pattern"] = `^[\p{Ll}\p{Lo}\p{N}]+(-[\p{Ll}\p{Lo}\p{N}]+)*$`
+  properties["Lang"].(map[string]any)["enum"] = config.Locales
+  properties["FeatureImage"].(map[string]any)["format"] = "uri"
+  tags := properties["Tags"].(map[string]any)
+  if config.MaxTags > 0 {
+    tags["maxItems"] = config.MaxTags
+  }
+  tagItems := tags["items"].(map[string]any)
+  tagItems["minLength"] = 1
+  if config.MaxTagLength > 0 {
+    tagItems["maxLength"] = config.MaxTagLength
+  }
+
+  writeJSON(w, http.StatusOK, schema)
+}
+
+// jsonSchema describes values of type t, following the json struct tags like encoding/json does.
+func jsonSchema(t reflect.Type) map[string]any {
+  switch t.Kind() {
+  case reflect.Pointer:
+    return jsonSchema(t.Elem())
+  case reflect.String:
+    return map[string]any{"type": "string"}
+  case reflect.Bool:
+    return map[string]any{"type": "boolean"}
+  case reflect.Int, reflect.Int64:
+    return map[string]any{"type": "integer"}
+  case reflect.Float64:
+    return map[string]any{"type": "number"}
+  case reflect.Slice:
+    return map[string]any{"type": "array", "items": jsonSchema(t.Elem())}
+  case reflect.Struct:
+    properties := map[string]any{}
+    for i := 0; i < t.NumField(); i++ {
+      field := t.Field(i)
+      name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+      if !field.IsExported() || name == "-" {
+        continue
+      }
+      if name == "" {
+        name = field.Name
+      }
+      properties[name] = jsonSchema(field.Type)
+    }
+    return map[string]any{"type": "object", "properties": properties}
+  }
+  // An empty schema accepts anything.
+  return map[string]any{}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "testing"
+)
+
+// schema is the part of the Post schema the tests look at.
+type schema struct {
+  Required   []string
+  Properties map[string]struct {
+    Type     string
+    ReadOnly bool
+    Enum     []string
+    MaxItems int
+  }
+}
+
+func getSchema(t *testing.T) schema {
+  t.Helper()
+  w := serve("GET /schema/post", postSchema, httptest.NewRequest(http.MethodGet, "/schema/post", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  return decode[schema](t, w)
+}
+
+func TestPostSchema(t *testing.T) {
+  setup(t)
+  config.Locales = []string{"en", "fr"}
+
+  got := getSchema(t)
+  if !slices.Contains(got.Required, "Title") || !slices.Contains(got.Required, "Author") {
+    t.Errorf("got required %v, want Title and Author", got.Required)
+  }
+  if got := got.Properties["ViewCount"]; got.Type != "integer" || !got.ReadOnly {
+    t.Errorf("got ViewCount %+v, want a read-only integer", got)
+  }
+  if got := got.Properties["Title"].Type; got != "string" {
+    t.Errorf("got Title type %q, want string", got)
+  }
+  if got := got.Properties["Lang"].Enum; !slices.Equal(got, []string{"en", "fr"}) {
+    t.Errorf("got Lang enum %v, want the Locales", got)
+  }
+  if got := got.Properties["Tags"]; got.Type != "array" || got.MaxItems != 10 {
+    t.Errorf("got Tags %+v, want an array of at most 10", got)
+  }
+  if _, ok := got.Properties["overlay"]; ok {
+    t.Error("got the unexported overlay field")
+  }
+
+  // With a DEFAULT_AUTHOR the Author can be left out.
+  config.DefaultAuthor = "Jane Doe"
+  if got := getSchema(t).Required; slices.Contains(got, "Author") {
+    t.Errorf("with a default author: got required %v", got)
+  }
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "net/http"
+  "os"
+  "strings"
+  "sync"
+)
+
+/*
+  SITE SETTINGS
+
+  Settings are the part of the configuration that can change while the service runs. They're kept in their own JSON file, SETTINGS_FILE (settings.json by default), or only in memory with MEMORY_ONLY:
+
+  {"title": "My blog", "tagline": "Notes about Go", "posts_per_page": 20}
+
+  posts_per_page is how many posts index lists when the query has no ?limit, 0 lists them all. The title starts out as the FEED_TITLE.
+
+  GET /settings returns them, and an admin changes them with PUT /settings. The keys left out of the body keep their value and unknown keys are refused, catching typos:
+
+  curl -X PUT -H "Authorization: Bearer $ADMIN_TOKEN" -d '{"posts_per_page": 10}' http://localhost:3000/settings
+*/
+type Settings struct {
+  Title        string `json:"title"`
+  Tagline      string `json:"tagline"`
+  PostsPerPage int    `json:"posts_per_page"`
+}
+
+var (
+  settingsMu   sync.RWMutex
+  siteSettings Settings
+)
+
+func (s Settings) validate() error {
+  var problems []error
+  if strings.TrimSpace(s.Title) == "" {
+    problems = append(problems, errors.New("title can't be empty"))
+  }
+  if s.PostsPerPage < 0 {
+    problems = append(problems, fmt.Errorf("posts_per_page can't be negative, got %d", s.PostsPerPage))
+  }
+  return errors.Join(problems...)
+}
+
+// currentSettings returns a copy of the settings, safe to use while they're being changed.
+func currentSettings() Settings {
+  settingsMu.RLock()
+  defer settingsMu.RUnlock()
+  return siteSettings
+}
+
+// loadSettings reads SETTINGS_FILE at startup. A missing file means the defaults are used.
+func loadSettings() error {
+  loaded := Settings{Title: config.FeedTitle}
+  if !config.MemoryOnly {
+    data, err := os.ReadFile(config.SettingsFile)
+    if err != nil && !errors.Is(err, os.ErrNotExist) {
+      return err
+    }
+    if err == nil {
+      if err := decodeSettings(data, &loaded); err != nil {
+        return fmt.Errorf("%s: %w", config.SettingsFile, err)
+      }
+      if err := loaded.validate(); err != nil {
+        return fmt.Errorf("%s: %w", config.SettingsFile, err)
+      }
+    }
+  }
+  settingsMu.Lock()
+  siteSettings = loaded
+  settingsMu.Unlock()
+  return nil
+}
+
+// decodeSettings decodes data over settings, refusing unknown keys.
+func decodeSettings(data []byte, settings *Settings) error {
+  decoder := json.NewDecoder(bytes.NewReader(data))
+  decoder.DisallowUnknownFields()
+  if err := decoder.Decode(settings); err != nil {
+    return fmt.Errorf("invalid settings: %w", err)
+  }
+  return nil
+}
+
+func getSettings(w http.ResponseWriter, r *http.Request) {
+  writeJSON(w, http.StatusOK, currentSettings())
+}
+
+func putSettings(w http.ResponseWriter, r *http.Request) {
+  body, status, err := readBody(r)
+  if err != nil {
+    jsonError(w, status, err.Error())
+    return
+  }
+
+  settingsMu.Lock()
+  defer settingsMu.Unlock()
+  updated := siteSettings
+  if err := decodeSettings(body, &updated); err != nil {
+    jsonError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+  if err := updated.validate(); err != nil {
+    jsonError(w, http.StatusUnprocessableEntity, err.Error())
+    return
+  }
+  if !config.MemoryOnly {
+    data, err := json.MarshalIndent(updated, "", "  ")
+    if err != nil {
+      jsonError(w, http.StatusInternalServerError, err.Error())
+      return
+    }
+    if err := os.WriteFile(config.SettingsFile, append(data, '\n'), config.FileMode.FileMode); err != nil {
+      jsonError(w, http.StatusInternalServerError, "Error saving settings")
+      return
+    }
+  }
+  siteSettings = updated
+
+  writeJSON(w, http.StatusOK, updated)
+}
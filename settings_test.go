@@ -0,0 +1,74 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+func TestSettings(t *testing.T) {
+  setup(t, testPosts()...)
+  config.AdminToken = testAdminToken
+  put := requireAdmin(putSettings)
+
+  w := serve("PUT /settings", put, adminRequest(http.MethodPut, "/settings", strings.NewReader(`{"tagline": "Notes about Go", "posts_per_page": 2}`)))
+  if w.Code != http.StatusOK {
+    t.Fatalf("put: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  w = serve("GET /settings", getSettings, httptest.NewRequest(http.MethodGet, "/settings", nil))
+  want := Settings{Title: config.FeedTitle, Tagline: "Notes about Go", PostsPerPage: 2}
+  if got := decode[Settings](t, w); got != want {
+    t.Errorf("get: got %+v, want %+v", got, want)
+  }
+  // index lists posts_per_page posts unless the query says otherwise.
+  if got := listedIDs(t, ""); len(got) != 2 {
+    t.Errorf("index: got %d posts, want 2", len(got))
+  }
+  if got := listedIDs(t, "?limit=3"); len(got) != 3 {
+    t.Errorf("index?limit=3: got %d posts, want 3", len(got))
+  }
+
+  tests := []struct {
+    name   string
+    r      *http.Request
+    status int
+  }{
+    {"no token", httptest.NewRequest(http.MethodPut, "/settings", strings.NewReader(`{"tagline": "Hacked"}`)), http.StatusUnauthorized},
+    {"unknown key", adminRequest(http.MethodPut, "/settings", strings.NewReader(`{"posts_per_pgae": 5}`)), http.StatusBadRequest},
+    {"invalid value", adminRequest(http.MethodPut, "/settings", strings.NewReader(`{"posts_per_page": -1}`)), http.StatusUnprocessableEntity},
+  }
+  for _, test := range tests {
+    if w := serve("PUT /settings", put, test.r); w.Code != test.status {
+      t.Errorf("%s: got status %d, want %d", test.name, w.Code, test.status)
+    }
+  }
+  if got := currentSettings(); got != want {
+    t.Errorf("the refused changes were applied: got %+v", got)
+  }
+}
+
+func TestSettingsFile(t *testing.T) {
+  setup(t)
+  config.MemoryOnly = false
+  config.SettingsFile = filepath.Join(t.TempDir(), "settings.json")
+  config.AdminToken = testAdminToken
+
+  w := serve("PUT /settings", requireAdmin(putSettings), adminRequest(http.MethodPut, "/settings", strings.NewReader(`{"title": "My blog"}`)))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  if _, err := os.Stat(config.SettingsFile); err != nil {
+    t.Fatal(err)
+  }
+  // The settings are still there after a restart.
+  siteSettings = Settings{}
+  if err := loadSettings(); err != nil {
+    t.Fatal(err)
+  }
+  if got := currentSettings().Title; got != "My blog" {
+    t.Errorf("got title %q, want %q", got, "My blog")
+  }
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+  "net/http"
+)
+
+/*
+  SHARE HANDLER
+
+  POST /posts/{id}/share counts a share of the post on social media, separately from its views, and returns the new total. Like every write it runs while holding postsMu, so shares counted at the same time all make it to the file.
+
+  index?sort=shares lists the most shared posts first.
+*/
+func share(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  post := &posts[i]
+  post.Shares = saturatingAdd(post.Shares, 1)
+  // Like creates, shares are logged before they're saved, see wal.go.
+  viewBuffer.logWrite(viewLogEntry{Op: walShare, ID: post.ID, Shares: post.Shares})
+  if err := savePosts(r.Context(), posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, map[string]any{"id": post.ID, "shares": post.Shares})
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "sync"
+  "testing"
+)
+
+func TestConcurrentShares(t *testing.T) {
+  setup(t, testPosts()...)
+  const shares = 50
+
+  var wg sync.WaitGroup
+  for range shares {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      w := serve("POST /posts/{id}/share", lockPosts(share), httptest.NewRequest(http.MethodPost, "/posts/2/share", nil))
+      if w.Code != http.StatusOK {
+        t.Errorf("got status %d, want %d", w.Code, http.StatusOK)
+      }
+    }()
+  }
+  wg.Wait()
+
+  if got := storedPosts(t)[1].Shares; got != shares {
+    t.Errorf("got %d shares, want %d", got, shares)
+  }
+  w := serve("POST /posts/{id}/share", lockPosts(share), httptest.NewRequest(http.MethodPost, "/posts/2/share", nil))
+  if got := decode[map[string]any](t, w)["shares"]; got != float64(shares+1) {
+    t.Errorf("got total %v, want %d", got, shares+1)
+  }
+  if w := serve("POST /posts/{id}/share", lockPosts(share), httptest.NewRequest(http.MethodPost, "/posts/99/share", nil)); w.Code != http.StatusNotFound {
+    t.Errorf("missing post: got status %d, want %d", w.Code, http.StatusNotFound)
+  }
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "log"
+  "net/http"
+  "sync/atomic"
+  "time"
+)
+
+/*
+  GRACEFUL SHUTDOWN
+
+  When the service is asked to stop, server.Shutdown stops accepting connections and waits for the requests in flight to finish, for at most SHUTDOWN_TIMEOUT (10s by default). Requests still running after that have their connections closed.
+
+  Either way the views buffered since the last flush are written before the process exits, the handlers that were cut off may still be finishing a write so flushViews waits for postsMu like any other write. It waits for SHUTDOWN_TIMEOUT at most too, the views it couldn't write are counted in the logs.
+*/
+func shutdown(server *http.Server, timeout time.Duration) {
+  fmt.Println("Shutting down")
+  ctx, cancel := context.WithTimeout(context.Background(), timeout)
+  defer cancel()
+
+  err := server.Shutdown(ctx)
+  if errors.Is(err, context.DeadlineExceeded) {
+    log.Printf("Shutdown timed out after %s with %d requests in flight, closing their connections", timeout, inFlight.Load())
+    server.Close()
+  } else if err != nil {
+    log.Printf("Error shutting down: %v", err)
+  }
+
+  flushCtx, cancelFlush := context.WithTimeout(context.Background(), timeout)
+  defer cancelFlush()
+  if err := flushViews(flushCtx); err != nil {
+    views, posts := viewBuffer.unsaved()
+    log.Printf("Error saving views, %d views of %d posts are lost: %v", views, posts, err)
+  }
+}
+
+// inFlight counts the requests being served. atomic.Int64 can be updated from many goroutines at once without a mutex.
+var inFlight atomic.Int64
+
+func trackInFlight(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    inFlight.Add(1)
+    defer inFlight.Add(-1)
+    next.ServeHTTP(w, r)
+  })
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+  "bytes"
+  "log"
+  "net"
+  "net/http"
+  "os"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestShutdownTimesOutAndFlushesViews(t *testing.T) {
+  setup(t, testPosts()...)
+  viewBuffer.add("1", "2025-01-05T10:00:00Z")
+
+  var logs bytes.Buffer
+  log.SetOutput(&logs)
+  defer log.SetOutput(os.Stderr)
+
+  started, release := make(chan struct{}), make(chan struct{})
+  defer close(release)
+  slow := func(w http.ResponseWriter, r *http.Request) {
+    close(started)
+    <-release
+  }
+  listener, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil {
+    t.Fatal(err)
+  }
+  server := &http.Server{Handler: trackInFlight(http.HandlerFunc(slow))}
+  go server.Serve(listener)
+
+  requestErr := make(chan error, 1)
+  go func() {
+    response, err := http.Get("http://" + listener.Addr().String())
+    if err == nil {
+      response.Body.Close()
+    }
+    requestErr <- err
+  }()
+  <-started
+
+  start := time.Now()
+  shutdown(server, 50*time.Millisecond)
+  if elapsed := time.Since(start); elapsed > time.Second {
+    t.Errorf("shutdown took %s, want about the 50ms timeout", elapsed)
+  }
+  if !strings.Contains(logs.String(), "timed out after 50ms with 1 requests in flight") {
+    t.Errorf("got logs %q, want the requests in flight", logs.String())
+  }
+  if err := <-requestErr; err == nil {
+    t.Error("the slow request succeeded, want its connection closed")
+  }
+  if got := storedPosts(t)[0].ViewCount; got != 1 {
+    t.Errorf("got %d stored views, want the buffered view flushed", got)
+  }
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+  "encoding/xml"
+  "net/http"
+  "time"
+)
+
+/*
+  SITEMAP HANDLER
+
+  GET /sitemap.xml lists the published posts for search engines, following https://www.sitemaps.org/protocol.html. Like the feed it's built with encoding/xml, each post gets a <url> with its address and when it was last modified:
+
+  <url><loc>http://localhost:3000/posts/my-first-post</loc><lastmod>2025-06-04T10:00:00Z</lastmod></url>
+
+  The address is the permalink of the post, made from its slug or, for the posts that have none, its ID. See permalink.go.
+
+  Drafts, hidden and deleted posts are left out, see visiblePosts.
+*/
+type sitemapURLSet struct {
+  XMLName xml.Name     `xml:"urlset"`
+  XMLNS   string       `xml:"xmlns,attr"`
+  URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+  Loc     string `xml:"loc"`
+  LastMod string `xml:"lastmod,omitempty"`
+}
+
+func sitemap(w http.ResponseWriter, r *http.Request) {
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+
+  set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9", URLs: []sitemapURL{}}
+  for _, post := range visiblePosts(posts) {
+    url := sitemapURL{Loc: permalink(post)}
+    if modified, ok := post.lastModified(); ok {
+      url.LastMod = modified.Format(time.RFC3339)
+    }
+    set.URLs = append(set.URLs, url)
+  }
+
+  data, err := xml.MarshalIndent(set, "", "  ")
+  if err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error encoding sitemap")
+    return
+  }
+  w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+  w.Write([]byte(xml.Header))
+  w.Write(data)
+}
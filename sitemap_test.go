@@ -0,0 +1,43 @@
+package main
+
+import (
+  "encoding/xml"
+  "net/http"
+  "net/http/httptest"
+  "reflect"
+  "testing"
+)
+
+func TestSitemap(t *testing.T) {
+  posts := testPosts()
+  posts[0].Slug = "first-post"
+  posts[0].UpdatedAt = "2025-02-01T10:00:00Z"
+  posts[1].Hidden = true
+  extra := Post{ID: "4", Title: "Draft", Content: "Draft", Author: "Jane Doe", CreatedAt: "2025-01-04T10:00:00Z", Draft: true}
+  deleted := Post{ID: "5", Title: "Deleted", Content: "Deleted", Author: "Jane Doe", CreatedAt: "2025-01-05T10:00:00Z", Deleted: true}
+  setup(t, append(posts, extra, deleted)...)
+  config.BaseURL = "https://blog.example.com"
+
+  w := serve("GET /sitemap.xml", sitemap, httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  if got := w.Header().Get("Content-Type"); got != "application/xml; charset=utf-8" {
+    t.Errorf("got Content-Type %q", got)
+  }
+
+  var got struct {
+    XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+    URLs    []sitemapURL `xml:"url"`
+  }
+  if err := xml.Unmarshal(w.Body.Bytes(), &got); err != nil {
+    t.Fatalf("invalid sitemap: %v\n%s", err, w.Body)
+  }
+  want := []sitemapURL{
+    {Loc: "https://blog.example.com/posts/first-post", LastMod: "2025-02-01T10:00:00Z"},
+    {Loc: "https://blog.example.com/posts/3", LastMod: "2025-01-03T10:00:00Z"},
+  }
+  if !reflect.DeepEqual(got.URLs, want) {
+    t.Errorf("got %+v, want %+v", got.URLs, want)
+  }
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+  "fmt"
+  "net/http"
+  "strconv"
+  "strings"
+  "unicode"
+)
+
+/*
+  SLUGS
+
+  A slug names a post in URLs, e.g. "my-first-post": lowercase letters and digits in words separated by hyphens. New posts can be given one, otherwise it's made from the Title. Slugs are unique regardless of case, a taken slug is refused with a 409, except for the ones made from the Title which get a number instead, e.g. "my-first-post-2".
+
+  Posts created before slugs existed have none, they're only known by their ID.
+*/
+func slugify(title string) string {
+  var slug strings.Builder
+  hyphen := false
+  for _, r := range strings.ToLower(title) {
+    if unicode.IsLetter(r) || unicode.IsDigit(r) {
+      if hyphen && slug.Len() > 0 {
+        slug.WriteByte('-')
+      }
+      slug.WriteRune(r)
+      hyphen = false
+    } else {
+      hyphen = true
+    }
+  }
+  if slug.Len() == 0 {
+    return "post"
+  }
+  return slug.String()
+}
+
+// validateSlug accepts the slugs slugify could have made.
+func validateSlug(slug string) error {
+  if slugify(slug) != slug {
+    return fmt.Errorf("Slug %q must be lowercase words separated by hyphens, e.g. %q", slug, slugify(slug))
+  }
+  return nil
+}
+
+// slugTaken tells whether another post than the one with the given ID has the slug. Deleted posts keep theirs, they can still be restored.
+func slugTaken(posts []Post, slug string, id PostID) bool {
+  for _, post := range posts {
+    if post.ID != id && strings.EqualFold(post.Slug, slug) {
+      return true
+    }
+  }
+  return false
+}
+
+// findPostBySlug is findPost for slugs, which are unique regardless of case.
+func findPostBySlug(posts []Post, slug string) int {
+  for i, post := range posts {
+    if post.Slug != "" && strings.EqualFold(post.Slug, slug) && !post.Deleted {
+      return i
+    }
+  }
+  return -1
+}
+
+// uniqueSlug numbers the slug until no other post has it.
+func uniqueSlug(posts []Post, slug string, id PostID) string {
+  unique := slug
+  for n := 2; slugTaken(posts, unique, id); n++ {
+    unique = slug + "-" + strconv.Itoa(n)
+  }
+  return unique
+}
+
+/*
+  SLUG AVAILABLE HANDLER
+
+  GET /posts/slug-available?slug=my-first-post tells a post editor whether create would accept the slug, before the post is submitted:
+
+  {"available": false}
+
+  Slugs are compared regardless of case, like create does, and deleted posts keep theirs. When editing a post, ?id= leaves that post out, its own slug is available to it. A missing or malformed slug is a 400, see validateSlug.
+*/
+func slugAvailable(w http.ResponseWriter, r *http.Request) {
+  slug := r.URL.Query().Get("slug")
+  if slug == "" {
+    jsonError(w, http.StatusBadRequest, "slug is required")
+    return
+  }
+  if err := validateSlug(slug); err != nil {
+    jsonError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  id := PostID(r.URL.Query().Get("id"))
+  writeJSON(w, http.StatusOK, map[string]bool{"available": !slugTaken(posts, slug, id)})
+}
+
+/*
+  POST IDS HANDLER
+
+  GET /posts/ids lists the published posts in a few bytes each, for sitemaps and the like, without their Content:
+
+  [{"id": "1", "slug": "my-first-post", "updatedAt": "2025-06-04T10:00:00Z"}]
+
+  updatedAt is when the post was last modified, see lastModified. The slug is left out for posts that have none.
+*/
+type postRef struct {
+  ID        PostID `json:"id"`
+  Slug      string `json:"slug,omitempty"`
+  UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+func postIDs(w http.ResponseWriter, r *http.Request) {
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+
+  refs := []postRef{}
+  for _, post := range visiblePosts(posts) {
+    ref := postRef{ID: post.ID, Slug: post.Slug}
+    // postRef isn't a Post, writeJSON won't convert the timestamp to the display time zone itself. Going from the stored value leaves plain dates as they are.
+    if _, ok := post.lastModified(); ok {
+      ref.UpdatedAt = displayTimestamp(post.modifiedAt())
+    }
+    refs = append(refs, ref)
+  }
+  writeJSON(w, http.StatusOK, refs)
+}
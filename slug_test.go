@@ -0,0 +1,109 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "reflect"
+  "testing"
+  "time"
+)
+
+func TestPostIDs(t *testing.T) {
+  posts := testPosts()
+  posts[0].Slug = "first-post"
+  posts[0].UpdatedAt = "2025-02-01T10:00:00Z"
+  posts[1].Draft = true
+  posts[2].CreatedAt = "2025-01-03"
+  setup(t, posts...)
+  tokyo, err := time.LoadLocation("Asia/Tokyo")
+  if err != nil {
+    t.Skip(err)
+  }
+  config.Timezone = Location{tokyo}
+
+  w := serve("GET /posts/ids", postIDs, httptest.NewRequest(http.MethodGet, "/posts/ids", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  want := []map[string]any{
+    {"id": "1", "slug": "first-post", "updatedAt": "2025-02-01T19:00:00+09:00"},
+    // A plain date has no time to convert.
+    {"id": "3", "updatedAt": "2025-01-03"},
+  }
+  if got := decode[[]map[string]any](t, w); !reflect.DeepEqual(got, want) {
+    t.Errorf("got %v, want %v", got, want)
+  }
+}
+
+func TestShowBySlugOrID(t *testing.T) {
+  posts := testPosts()
+  posts[0].Slug = "first-post"
+  // A slug that is another post's ID.
+  posts[1].Slug = "3"
+  setup(t, posts...)
+
+  tests := []struct {
+    segment string
+    want    PostID
+  }{
+    {"1", "1"},
+    {"first-post", "1"},
+    {"First-Post", "1"},
+    {"3", "3"},
+    {"2", "2"},
+  }
+  for _, test := range tests {
+    w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/"+test.segment, nil))
+    if w.Code != http.StatusOK {
+      t.Errorf("/posts/%s: got status %d, want %d", test.segment, w.Code, http.StatusOK)
+      continue
+    }
+    if got := decode[Post](t, w).ID; got != test.want {
+      t.Errorf("/posts/%s: got post %s, want %s", test.segment, got, test.want)
+    }
+  }
+  if w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/second-post", nil)); w.Code != http.StatusNotFound {
+    t.Errorf("neither an ID nor a slug: got status %d, want %d", w.Code, http.StatusNotFound)
+  }
+}
+
+func TestSlugAvailable(t *testing.T) {
+  posts := testPosts()
+  posts[0].Slug = "first-post"
+  posts[1].Slug = "deleted-post"
+  posts[1].Deleted = true
+  // Edited by hand, create wouldn't take an uppercase slug.
+  posts[2].Slug = "Third-Post"
+  setup(t, posts...)
+
+  tests := []struct {
+    query     string
+    available bool
+  }{
+    {"?slug=new-post", true},
+    {"?slug=first-post", false},
+    // Slugs are compared regardless of case, and deleted posts keep theirs.
+    {"?slug=third-post", false},
+    {"?slug=deleted-post", false},
+    // A post's own slug is available to it.
+    {"?slug=first-post&id=1", true},
+    {"?slug=first-post&id=3", false},
+    {"?slug=third-post&id=3", true},
+  }
+  for _, test := range tests {
+    w := serve("GET /posts/slug-available", slugAvailable, httptest.NewRequest(http.MethodGet, "/posts/slug-available"+test.query, nil))
+    if w.Code != http.StatusOK {
+      t.Errorf("%s: got status %d, want %d", test.query, w.Code, http.StatusOK)
+      continue
+    }
+    if got := decode[map[string]bool](t, w)["available"]; got != test.available {
+      t.Errorf("%s: got available %t, want %t", test.query, got, test.available)
+    }
+  }
+
+  for _, query := range []string{"", "?slug=", "?slug=Not%20a%20slug", "?slug=FIRST-POST", "?slug=trailing-"} {
+    if w := serve("GET /posts/slug-available", slugAvailable, httptest.NewRequest(http.MethodGet, "/posts/slug-available"+query, nil)); w.Code != http.StatusBadRequest {
+      t.Errorf("%q: got status %d, want %d", query, w.Code, http.StatusBadRequest)
+    }
+  }
+}
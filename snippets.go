@@ -0,0 +1,29 @@
+package main
+
+import (
+  "regexp"
+  "strings"
+)
+
+/*
+  SNIPPETS
+
+  Boilerplate shared by many posts, like a signature, can be written once in the snippets of the config file, e.g. {"snippets": {"signature": "Thanks for reading! -- John"}}, and used in the Content of any post as {{signature}}. show replaces the variables with their snippet, the stored Content keeps the variables so editing a snippet updates every post using it. Variables without a snippet are left as they are.
+*/
+var (
+  snippetName     = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+  snippetVariable = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_-]+)\s*\}\}`)
+)
+
+func expandSnippets(content string) string {
+  if len(config.Snippets) == 0 || !strings.Contains(content, "{{") {
+    return content
+  }
+  return snippetVariable.ReplaceAllStringFunc(content, func(variable string) string {
+    name := snippetVariable.FindStringSubmatch(variable)[1]
+    if snippet, ok := config.Snippets[name]; ok {
+      return snippet
+    }
+    return variable
+  })
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestShowExpandsSnippets(t *testing.T) {
+  posts := testPosts()
+  posts[0].Content = "The content.\n\n{{ signature }} {{unknown}}"
+  setup(t, posts...)
+  config.Snippets = map[string]string{"signature": "Thanks for reading! -- Jane"}
+
+  w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  if got, want := decode[Post](t, w).Content, "The content.\n\nThanks for reading! -- Jane {{unknown}}"; got != want {
+    t.Errorf("got Content %q, want %q", got, want)
+  }
+  if got := storedPosts(t)[0].Content; got != posts[0].Content {
+    t.Errorf("got stored Content %q, want the template", got)
+  }
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "log"
+  "os"
+  "sync"
+)
+
+/*
+  INTERFACES
+
+  An interface lists the methods a type must have, without saying anything about how they work. Any type with those methods satisfies the interface automatically, there's no "implements" keyword in Go.
+
+  Handlers only ever talk to a Store through loadPost and savePosts, so where the posts actually live can change without touching them. FileStore keeps them in a JSON file and MemoryStore keeps them in memory, which is handy for tests and ephemeral environments. CachedStore keeps a FileStore's posts in memory too, see cache.go, and DirStore keeps every post in its own file, see dirstore.go.
+*/
+type Store interface {
+  Load() ([]Post, error)
+  Save(posts []Post) error
+}
+
+// newStore returns the store selected by the config.
+func newStore(config Config) Store {
+  if config.MemoryOnly {
+    return &MemoryStore{}
+  }
+  if config.PostsDir != "" {
+    return &DirStore{Dir: config.PostsDir, Mode: config.FileMode.FileMode, TrailingNewline: config.TrailingNewline}
+  }
+  file := &FileStore{Path: config.FilePath, Recover: config.RecoverMode, Mode: config.FileMode.FileMode, TrailingNewline: config.TrailingNewline}
+  if config.CachePosts {
+    return &CachedStore{File: file}
+  }
+  return file
+}
+
+/*
+  FileStore keeps the posts in a JSON file.
+
+  With Recover set, a file that can't be parsed (e.g. cut short by an interrupted write) is read up to the last complete post instead of being rejected. The next save rewrites the file with the recovered posts only, so this is meant as a last resort. A file holding a single post object rather than a list, as hand-written files sometimes do, is read as a list of that one post.
+*/
+type FileStore struct {
+  Path    string
+  Recover bool
+  // Mode is the permissions of the file and TrailingNewline ends it with a newline, see FILE_MODE and TRAILING_NEWLINE.
+  Mode            os.FileMode
+  TrailingNewline bool
+}
+
+/*
+  Load returns an error when the file can't be read or doesn't contain valid JSON. A missing file is fine though, it simply means there are no posts yet.
+*/
+func (store *FileStore) Load() ([]Post, error) {
+  data, err := os.ReadFile(store.Path)
+  if errors.Is(err, os.ErrNotExist) {
+    return []Post{}, nil
+  }
+  if err != nil {
+    return nil, fmt.Errorf("Error reading %s: %w", store.Path, err)
+  }
+  // Files edited on Windows may start with a BOM or be UTF-16, see encoding.go.
+  if data, err = decodeText(data); err != nil {
+    return nil, fmt.Errorf("Error reading %s: %w", store.Path, err)
+  }
+
+  // This is how we 'transform' the unstructured json into a list of posts structs. The process is commonly referred as unmarshalling or deserialization.
+  var posts []Post
+  if err := json.Unmarshal(data, &posts); err != nil {
+    if store.Recover {
+      if post, ok := singlePost(data); ok {
+        log.Printf("%s holds a single post rather than a list, loaded it as a list of one", store.Path)
+        return []Post{post}, nil
+      }
+      if recovered, ok := recoverPosts(data); ok {
+        log.Printf("%s is corrupt (%v), recovered %d posts", store.Path, err, len(recovered))
+        return recovered, nil
+      }
+    }
+    return nil, fmt.Errorf("%s is corrupt and won't be overwritten, fix or restore it: %w", store.Path, err)
+  }
+  return posts, nil
+}
+
+// singlePost decodes data as one post, it reports false when data isn't a JSON object.
+func singlePost(data []byte) (Post, bool) {
+  var post Post
+  if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) || json.Unmarshal(data, &post) != nil {
+    return Post{}, false
+  }
+  return post, true
+}
+
+/*
+  recoverPosts decodes a JSON array one element at a time, stopping at the first one that fails to decode. It reports false when the data doesn't even start like an array of posts.
+*/
+func recoverPosts(data []byte) ([]Post, bool) {
+  decoder := json.NewDecoder(bytes.NewReader(data))
+  if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+    return nil, false
+  }
+
+  posts := []Post{}
+  for decoder.More() {
+    var post Post
+    if err := decoder.Decode(&post); err != nil {
+      break
+    }
+    posts = append(posts, post)
+  }
+  return posts, true
+}
+
+func (store *FileStore) Save(posts []Post) error {
+  /*
+    Serializes the posts back to a json object
+    prefix: "" means that no prefix should be added at the beginning of the line
+    indent: "  " means that each level should have a 2 spaces indentation
+  */
+  data, err := json.MarshalIndent(posts, "", "  ")
+  if err != nil {
+    return err
+  }
+
+  if store.TrailingNewline {
+    data = append(data, '\n')
+  }
+
+  // Writes the post back into the local file. os.WriteFile only applies the mode when it creates the file, Chmod also fixes the mode of an existing one.
+  if err := os.WriteFile(store.Path, data, store.Mode); err != nil {
+    return err
+  }
+  return os.Chmod(store.Path, store.Mode)
+}
+
+/*
+  MemoryStore keeps the posts in memory, serialized just like they would be in the file. Storing the JSON rather than the slice means that every Load hands out a fresh copy, so a handler modifying its posts can't change the stored ones until it calls Save.
+*/
+type MemoryStore struct {
+  mu   sync.Mutex
+  data []byte
+}
+
+func (store *MemoryStore) Load() ([]Post, error) {
+  store.mu.Lock()
+  defer store.mu.Unlock()
+
+  posts := []Post{}
+  if store.data == nil {
+    return posts, nil
+  }
+  if err := json.Unmarshal(store.data, &posts); err != nil {
+    return nil, err
+  }
+  return posts, nil
+}
+
+func (store *MemoryStore) Save(posts []Post) error {
+  data, err := json.Marshal(posts)
+  if err != nil {
+    return err
+  }
+
+  store.mu.Lock()
+  defer store.mu.Unlock()
+  store.data = data
+  return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+// useFileStore stores the posts in a file of a temporary directory holding data, and returns its path.
+func useFileStore(t *testing.T, data string) string {
+  t.Helper()
+  path := filepath.Join(t.TempDir(), "posts.json")
+  if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+    t.Fatal(err)
+  }
+  config.MemoryOnly = false
+  config.FilePath = path
+  storage = &FileStore{Path: path, Mode: 0644}
+  return path
+}
+
+func TestCorruptFileIsNotOverwritten(t *testing.T) {
+  setup(t)
+  const corrupt = `[{"ID": "1", "Title": "First post", "Content": "The content`
+  path := useFileStore(t, corrupt)
+
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"}))
+  if w.Code != http.StatusInternalServerError {
+    t.Fatalf("create: got status %d, want %d", w.Code, http.StatusInternalServerError)
+  }
+  if got := decode[map[string]string](t, w)["error"]; got == "" {
+    t.Error("create: got no error message")
+  }
+  if w := serve("/index", index, httptest.NewRequest(http.MethodGet, "/index", nil)); w.Code != http.StatusInternalServerError {
+    t.Errorf("index: got status %d, want %d", w.Code, http.StatusInternalServerError)
+  }
+
+  data, err := os.ReadFile(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if string(data) != corrupt {
+    t.Errorf("the file was overwritten with %q", data)
+  }
+}
+
+func TestMemoryOnly(t *testing.T) {
+  setup(t)
+  dir := t.TempDir()
+  config.FilePath = filepath.Join(dir, "posts.json")
+  storage = newStore(config)
+  ids = newIDGenerator(config)
+  if _, ok := storage.(*MemoryStore); !ok {
+    t.Fatalf("got a %T, want a MemoryStore", storage)
+  }
+
+  for _, title := range []string{"First", "Second"} {
+    w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: title, Content: "Content of " + title, Author: "Jane Doe"}))
+    if w.Code != http.StatusCreated {
+      t.Fatalf("create: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+    }
+  }
+  w := serve("/index", index, httptest.NewRequest(http.MethodGet, "/index", nil))
+  if got := decode[[]Post](t, w); len(got) != 2 || got[0].Title != "First" || got[1].Title != "Second" {
+    t.Errorf("index: got %+v, want both posts", got)
+  }
+
+  entries, err := os.ReadDir(dir)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(entries) != 0 {
+    t.Errorf("got %d files, want none", len(entries))
+  }
+}
+
+func TestRecoverModeLoadsCompletePosts(t *testing.T) {
+  const truncated = `[
+  {"ID": "1", "Title": "First post", "Content": "First", "Author": "Jane Doe"},
+  {"ID": "2", "Title": "Second post", "Content": "Second", "Author": "Jane Doe"},
+  {"ID": "3", "Title": "Third post", "Cont`
+  path := filepath.Join(t.TempDir(), "posts.json")
+  if err := os.WriteFile(path, []byte(truncated), 0644); err != nil {
+    t.Fatal(err)
+  }
+
+  if _, err := (&FileStore{Path: path}).Load(); err == nil {
+    t.Error("without RECOVER_MODE: got no error")
+  }
+  posts, err := (&FileStore{Path: path, Recover: true}).Load()
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(posts) != 2 || posts[0].ID != "1" || posts[1].ID != "2" {
+    t.Errorf("got %+v, want posts 1 and 2", posts)
+  }
+
+  // Data that isn't an array of posts can't be recovered.
+  if err := os.WriteFile(path, []byte(`not json`), 0644); err != nil {
+    t.Fatal(err)
+  }
+  if _, err := (&FileStore{Path: path, Recover: true}).Load(); err == nil {
+    t.Error("not JSON: got no error")
+  }
+}
+
+func TestFileModeAndTrailingNewline(t *testing.T) {
+  for _, trailingNewline := range []bool{true, false} {
+    setup(t)
+    path := useFileStore(t, `[]`)
+    config.FileMode = FileMode{0600}
+    config.TrailingNewline = trailingNewline
+    storage = newStore(config)
+
+    w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"}))
+    if w.Code != http.StatusCreated {
+      t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+    }
+    info, err := os.Stat(path)
+    if err != nil {
+      t.Fatal(err)
+    }
+    if got := info.Mode().Perm(); got != 0600 {
+      t.Errorf("got mode %#o, want 0600", got)
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+      t.Fatal(err)
+    }
+    if got := strings.HasSuffix(string(data), "]\n"); got != trailingNewline {
+      t.Errorf("TRAILING_NEWLINE=%t: the file ends with %q", trailingNewline, data[len(data)-2:])
+    }
+  }
+}
+
+func TestRecoverModeLoadsASinglePost(t *testing.T) {
+  setup(t)
+  useFileStore(t, `{"ID": "1", "Title": "First post", "Content": "First", "Author": "Jane Doe", "CreatedAt": "2025-01-01T10:00:00Z"}`)
+
+  if w := serve("/index", index, httptest.NewRequest(http.MethodGet, "/index", nil)); w.Code != http.StatusInternalServerError {
+    t.Errorf("without RECOVER_MODE: got status %d, want %d", w.Code, http.StatusInternalServerError)
+  }
+
+  storage.(*FileStore).Recover = true
+  if got := listedIDs(t, ""); len(got) != 1 || got[0] != "1" {
+    t.Fatalf("got posts %v, want post 1", got)
+  }
+  // The next save writes a list.
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"}))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("create: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  storage.(*FileStore).Recover = false
+  if got := storedPosts(t); len(got) != 2 || got[0].Title != "First post" || got[1].Title != "New" {
+    t.Errorf("got stored posts %+v, want the single post and the new one", got)
+  }
+}
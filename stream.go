@@ -0,0 +1,133 @@
+package main
+
+import (
+  "bufio"
+  "bytes"
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "log"
+  "os"
+  "sort"
+)
+
+/*
+  STREAMING
+
+  loadPost reads the whole file and decodes every post before index filters them, so the memory used grows with the file. With STREAM_POSTS set, index reads the posts one at a time instead and only keeps the ones it's going to list: with ?limit=20&offset=40 that's never more than 60 posts, however big the file is.
+
+  Only stores that implement PostStreamer can be streamed (FileStore does), and ?rank=hybrid needs the views of every post to score them, so those lists are loaded the usual way.
+*/
+type PostStreamer interface {
+  // Each calls fn with every post in order, stopping at the first error.
+  Each(fn func(Post) error) error
+}
+
+/*
+  Each decodes the JSON array one element at a time with json.Decoder, which reads from the file as it goes rather than all of it upfront.
+*/
+func (store *FileStore) Each(fn func(Post) error) error {
+  file, err := os.Open(store.Path)
+  if errors.Is(err, os.ErrNotExist) {
+    return nil
+  }
+  if err != nil {
+    return fmt.Errorf("Error reading %s: %w", store.Path, err)
+  }
+  defer file.Close()
+
+  var reader io.Reader = bufio.NewReader(file)
+  if bom, _ := reader.(*bufio.Reader).Peek(len(utf8BOM)); bytes.HasPrefix(bom, utf8BOM) {
+    reader.(*bufio.Reader).Discard(len(utf8BOM))
+  } else if bytes.HasPrefix(bom, utf16LEBOM) || bytes.HasPrefix(bom, utf16BEBOM) {
+    // UTF-16 files have to be converted first, see encoding.go. They're rare enough to be read whole.
+    data, err := io.ReadAll(reader)
+    if err != nil {
+      return fmt.Errorf("Error reading %s: %w", store.Path, err)
+    }
+    if data, err = decodeText(data); err != nil {
+      return fmt.Errorf("Error reading %s: %w", store.Path, err)
+    }
+    reader = bytes.NewReader(data)
+  }
+
+  decoder := json.NewDecoder(reader)
+  token, err := decoder.Token()
+  if err == nil && token == json.Delim('{') && store.Recover {
+    // A single post, Load knows how to read it.
+    posts, err := store.Load()
+    if err != nil {
+      return err
+    }
+    for _, post := range posts {
+      if err := fn(post); err != nil {
+        return err
+      }
+    }
+    return nil
+  }
+  if err != nil || token != json.Delim('[') {
+    return fmt.Errorf("%s is corrupt: not a list of posts", store.Path)
+  }
+  for decoder.More() {
+    var post Post
+    if err := decoder.Decode(&post); err != nil {
+      // Like Load, a recovering store keeps the posts read so far, see recoverPosts.
+      if store.Recover {
+        log.Printf("%s is corrupt (%v), stopping at the last complete post", store.Path, err)
+        return nil
+      }
+      return fmt.Errorf("%s is corrupt: %w", store.Path, err)
+    }
+    if err := fn(post); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+/*
+  streamPosts returns the posts of the listing, going through the posts one at a time. It reports false when the posts can't be streamed, the caller then loads them with loadPost.
+
+  The posts kept so far are sorted, each new post is inserted at its place and the ones past offset+limit dropped. Inserting after the posts comparing equal keeps them in file order, like sort.SliceStable does.
+*/
+func streamPosts(ctx context.Context, l listing) (posts []Post, ok bool, err error) {
+  streamer, ok := storage.(PostStreamer)
+  if !ok || l.hybrid {
+    return nil, false, nil
+  }
+  _, span := tracer.Start(ctx, "streamPosts")
+  defer func() { endSpan(span, err) }()
+
+  kept := []Post{}
+  err = streamer.Each(func(post Post) error {
+    // decryptPosts and apply work on slices, a slice of one post is no different.
+    one := []Post{post}
+    if err := decryptPosts(one); err != nil {
+      return err
+    }
+    viewBuffer.apply(one)
+    post = one[0]
+    if !l.keep(&post) {
+      return nil
+    }
+
+    at := sort.Search(len(kept), func(i int) bool { return l.less(&post, &kept[i]) })
+    if l.limit > 0 && at >= l.offset+l.limit {
+      return nil
+    }
+    kept = append(kept, Post{})
+    copy(kept[at+1:], kept[at:])
+    kept[at] = post
+    if l.limit > 0 && len(kept) > l.offset+l.limit {
+      kept = kept[:l.offset+l.limit]
+    }
+    return nil
+  })
+  if err != nil {
+    return nil, true, err
+  }
+  return page(l, kept), true, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+  "encoding/json"
+  "fmt"
+  "os"
+  "path/filepath"
+  "reflect"
+  "runtime"
+  "strings"
+  "testing"
+)
+
+// samplingStore records the most memory in use, above base, while the posts are streamed.
+type samplingStore struct {
+  *FileStore
+  base, peak uint64
+}
+
+func (s *samplingStore) Each(fn func(Post) error) error {
+  n := 0
+  return s.FileStore.Each(func(post Post) error {
+    if n++; n%500 == 0 {
+      var stats runtime.MemStats
+      runtime.ReadMemStats(&stats)
+      s.peak = max(s.peak, stats.HeapAlloc-min(stats.HeapAlloc, s.base))
+    }
+    return fn(post)
+  })
+}
+
+func TestStreamingALargeFile(t *testing.T) {
+  const count = 20000
+  posts := make([]Post, count)
+  for i := range posts {
+    posts[i] = Post{
+      ID: PostID(fmt.Sprint(i + 1)), Title: fmt.Sprintf("Post %d", i+1), Author: "Jane Doe",
+      Content: strings.Repeat("lorem ipsum ", 80), CreatedAt: "2025-01-01T10:00:00Z", ViewCount: int64(i % 1000),
+    }
+  }
+  data, err := json.Marshal(posts)
+  if err != nil {
+    t.Fatal(err)
+  }
+  setup(t)
+  path := filepath.Join(t.TempDir(), "posts.json")
+  if err := os.WriteFile(path, data, 0644); err != nil {
+    t.Fatal(err)
+  }
+  // The test keeps as little as possible in memory, or the garbage collector lets the garbage grow with it.
+  size := uint64(len(data))
+  posts, data = nil, nil
+  config.MemoryOnly = false
+  config.FilePath = path
+  config.ViewFlushInterval.Duration = 0
+  config.ReadOnly = true // Nothing to write, only the listing is measured.
+
+  const query = "?sort=views&limit=5&offset=10"
+  storage = &FileStore{Path: path}
+  want := decode[[]Post](t, listPosts(query))
+
+  config.StreamPosts = true
+  store := &samplingStore{FileStore: &FileStore{Path: path}}
+  storage = store
+  // Pools like the one encoding/json keeps its buffers in are only emptied by the second collection.
+  for range 2 {
+    runtime.GC()
+  }
+  var stats runtime.MemStats
+  runtime.ReadMemStats(&stats)
+  store.base = stats.HeapAlloc
+
+  got := decode[[]Post](t, listPosts(query))
+  if !reflect.DeepEqual(got, want) {
+    t.Errorf("streamed: got %d posts, want the same page as loading the file", len(got))
+  }
+  if len(got) != 5 || got[0].ViewCount != 999 {
+    t.Errorf("got %d posts starting with %d views, want 5 starting with 999", len(got), got[0].ViewCount)
+  }
+  // Loading every post takes more memory than the file itself, streaming only keeps a page of them.
+  if store.peak == 0 || store.peak > size/2 {
+    t.Errorf("streaming used up to %d bytes for a %d bytes file", store.peak, size)
+  }
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+  "net/http"
+  "sort"
+  "time"
+)
+
+/*
+  SYNC HANDLER
+
+  GET /posts?modified_since=2025-06-04T10:00:00Z returns the posts created or updated after that time, so a client keeping a copy of the posts only fetches what changed since its last sync. The posts come least recently modified first, the client can take the last one's UpdatedAt as the starting point of its next sync. Without modified_since every post is returned.
+
+  A post is modified when its UpdatedAt is, or its CreatedAt for posts that have no UpdatedAt, see lastModified. Only the posts of the public lists are returned, and fetching them isn't counted as a view.
+*/
+func modifiedPosts(w http.ResponseWriter, r *http.Request) {
+  var since time.Time
+  if value := r.URL.Query().Get("modified_since"); value != "" {
+    var err error
+    if since, err = time.Parse(time.RFC3339, value); err != nil {
+      jsonError(w, http.StatusBadRequest, "modified_since must be an RFC 3339 timestamp, e.g. 2025-06-04T10:00:00Z")
+      return
+    }
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+
+  type modifiedPost struct {
+    post     Post
+    modified time.Time
+  }
+  modified := []modifiedPost{}
+  for _, post := range visiblePosts(posts) {
+    // Posts without a valid date can't be placed in time, they're only part of a full sync.
+    at, ok := post.lastModified()
+    if (ok && at.After(since)) || (!ok && since.IsZero()) {
+      modified = append(modified, modifiedPost{post, at})
+    }
+  }
+  sort.SliceStable(modified, func(a, b int) bool { return modified[a].modified.Before(modified[b].modified) })
+
+  listed := make([]Post, 0, len(modified))
+  for _, m := range modified {
+    listed = append(listed, m.post)
+  }
+  writeJSON(w, http.StatusOK, listed)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "testing"
+)
+
+// syncedIDs returns the IDs of the posts returned by GET /posts for the query.
+func syncedIDs(t *testing.T, query string) []PostID {
+  t.Helper()
+  w := serve("GET /posts", modifiedPosts, httptest.NewRequest(http.MethodGet, "/posts"+query, nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("%s: got status %d, want %d: %s", query, w.Code, http.StatusOK, w.Body)
+  }
+  ids := []PostID{}
+  for _, post := range decode[[]Post](t, w) {
+    ids = append(ids, post.ID)
+  }
+  return ids
+}
+
+func TestModifiedSince(t *testing.T) {
+  posts := testPosts()
+  // Post 1 was created first but edited last.
+  posts[0].UpdatedAt = "2025-02-01T10:00:00Z"
+  posts[1].UpdatedAt = "2025-01-20T10:00:00Z"
+  setup(t, posts...)
+
+  if got, want := syncedIDs(t, "?modified_since=2025-01-10T00:00:00Z"), []PostID{"2", "1"}; !slices.Equal(got, want) {
+    t.Errorf("got %v, want %v", got, want)
+  }
+  if got, want := syncedIDs(t, "?modified_since=2025-01-02T12:00:00%2B02:00"), []PostID{"3", "2", "1"}; !slices.Equal(got, want) {
+    t.Errorf("with an offset: got %v, want %v", got, want)
+  }
+  if got := syncedIDs(t, "?modified_since=2025-02-01T10:00:00Z"); len(got) != 0 {
+    t.Errorf("up to date: got %v, want none", got)
+  }
+  if got, want := syncedIDs(t, ""), []PostID{"3", "2", "1"}; !slices.Equal(got, want) {
+    t.Errorf("full sync: got %v, want %v", got, want)
+  }
+
+  // Syncing isn't reading, no view is counted.
+  if got := storedPosts(t)[0].ViewCount; got != 0 {
+    t.Errorf("got %d views, want none", got)
+  }
+  if views, _ := viewBuffer.unsaved(); views != 0 {
+    t.Errorf("got %d buffered views, want none", views)
+  }
+
+  for _, value := range []string{"yesterday", "2025-01-10"} {
+    w := serve("GET /posts", modifiedPosts, httptest.NewRequest(http.MethodGet, "/posts?modified_since="+value, nil))
+    if w.Code != http.StatusBadRequest {
+      t.Errorf("%s: got status %d, want %d", value, w.Code, http.StatusBadRequest)
+    }
+  }
+}
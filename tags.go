@@ -0,0 +1,59 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+  "slices"
+  "strings"
+  "unicode/utf8"
+)
+
+/*
+  TAGS
+
+  Tags label posts, e.g. ["go", "tutorial"]. To keep the tag cloud readable a post can have at most MAX_TAGS tags (10 by default) of at most MAX_TAG_LENGTH characters each (30 by default), and tags can't be blank.
+*/
+func validateTags(tags []string) error {
+  if config.MaxTags > 0 && len(tags) > config.MaxTags {
+    return fmt.Errorf("a post can have at most %d tags, got %d", config.MaxTags, len(tags))
+  }
+  for _, tag := range tags {
+    if strings.TrimSpace(tag) == "" {
+      return errors.New("tags can't be empty")
+    }
+    // len counts bytes, RuneCountInString counts characters, which is what people expect for non-ASCII tags.
+    if config.MaxTagLength > 0 && utf8.RuneCountInString(tag) > config.MaxTagLength {
+      return fmt.Errorf("tag %q is longer than %d characters", tag, config.MaxTagLength)
+    }
+  }
+  return nil
+}
+
+// normalizeTags trims the tags and drops the empty ones and the duplicates, keeping the first occurrence.
+func normalizeTags(tags []string) []string {
+  if tags == nil {
+    return nil
+  }
+  normalized := []string{}
+  seen := map[string]bool{}
+  for _, tag := range tags {
+    tag = strings.TrimSpace(tag)
+    if tag == "" || seen[tag] {
+      continue
+    }
+    seen[tag] = true
+    normalized = append(normalized, tag)
+  }
+  return normalized
+}
+
+// hasTags tells whether the post has any of the tags, or all of them, case aside.
+func (post *Post) hasTags(tags []string, all bool) bool {
+  for _, tag := range tags {
+    has := slices.ContainsFunc(post.Tags, func(own string) bool { return strings.EqualFold(own, tag) })
+    if has != all {
+      return has
+    }
+  }
+  return all
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+  "net/http"
+  "slices"
+  "strings"
+  "testing"
+)
+
+func TestCreateTagLimits(t *testing.T) {
+  tooMany := make([]string, 11)
+  for i := range tooMany {
+    tooMany[i] = "tag" + string(rune('a'+i))
+  }
+  tests := []struct {
+    name string
+    tags []string
+    want string
+  }{
+    {"too many tags", tooMany, "a post can have at most 10 tags, got 11"},
+    {"over-long tag", []string{"go", strings.Repeat("é", 31)}, "is longer than 30 characters"},
+    {"empty tag", []string{"go", " "}, "tags can't be empty"},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      setup(t)
+      w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe", Tags: test.tags}))
+      if w.Code != http.StatusUnprocessableEntity {
+        t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+      }
+      if got := decode[invalidPost](t, w).Fields["Tags"]; !strings.Contains(got, test.want) {
+        t.Errorf("got Tags error %q, want %q", got, test.want)
+      }
+      if got := len(storedPosts(t)); got != 0 {
+        t.Errorf("got %d stored posts, want none", got)
+      }
+    })
+  }
+
+  setup(t)
+  w := serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe", Tags: tooMany[:10]}))
+  if w.Code != http.StatusCreated {
+    t.Errorf("10 tags: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+}
+
+func TestTagModes(t *testing.T) {
+  posts := testPosts()
+  posts[0].Tags = []string{"go", "web"}
+  posts[1].Tags = []string{"Go"}
+  posts[2].Tags = []string{"web", "css"}
+  setup(t, posts...)
+
+  tests := []struct {
+    query string
+    want  []PostID
+  }{
+    {"?tags=go,web", []PostID{"3", "2", "1"}},
+    {"?tags=go,web&tag_mode=any", []PostID{"3", "2", "1"}},
+    {"?tags=go,web&tag_mode=all", []PostID{"1"}},
+    {"?tags=GO&tag_mode=all", []PostID{"2", "1"}},
+    {"?tags=web,css&tag_mode=all", []PostID{"3"}},
+    {"?tags=css,rust&tag_mode=any", []PostID{"3"}},
+    {"?tags=css,rust&tag_mode=all", []PostID{}},
+  }
+  for _, test := range tests {
+    if got := listedIDs(t, test.query); !slices.Equal(got, test.want) {
+      t.Errorf("%s: got %v, want %v", test.query, got, test.want)
+    }
+  }
+
+  if w := listPosts("?tags=go&tag_mode=some"); w.Code != http.StatusBadRequest {
+    t.Errorf("invalid tag_mode: got status %d, want %d", w.Code, http.StatusBadRequest)
+  }
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+  "fmt"
+  "reflect"
+  "time"
+  // Importing time/tzdata embeds the time zone database in the binary, so TIMEZONE works on machines that don't have one installed.
+  _ "time/tzdata"
+)
+
+/*
+  TIME ZONES
+
+  Timestamps are stored in UTC, so posts created on servers in different time zones compare as they should. They're shown in the zone set with TIMEZONE (an IANA name like "Europe/Paris", the server's local zone by default): forOutput converts them on their way into a response.
+
+  Plain dates like "2023-05-19", from older posts, have no time of day to convert and are shown as they are.
+*/
+type Location struct {
+  *time.Location
+}
+
+func (l *Location) UnmarshalText(text []byte) error {
+  loc, err := time.LoadLocation(string(text))
+  if err != nil {
+    return fmt.Errorf("unknown time zone %q, use a name like UTC or Europe/Paris", text)
+  }
+  l.Location = loc
+  return nil
+}
+
+func (l Location) MarshalText() ([]byte, error) {
+  return []byte(l.String()), nil
+}
+
+// storedTimestamp converts a timestamp sent by a client to UTC, plain dates are kept as they are.
+func storedTimestamp(value string) string {
+  t, ok := parseTimestamp(value)
+  if !ok || len(value) <= len("2006-01-02") {
+    return value
+  }
+  return t.UTC().Format(time.RFC3339)
+}
+
+// displayTime parses a stored timestamp and returns it in the configured time zone.
+func displayTime(value string) (time.Time, bool) {
+  t, ok := parseTimestamp(value)
+  if !ok || len(value) <= len("2006-01-02") {
+    return t, ok
+  }
+  return t.In(config.Timezone.Location), true
+}
+
+// displayTimestamp formats a stored timestamp in the configured time zone, values that aren't timestamps are returned as they are.
+func displayTimestamp(value string) string {
+  if len(value) <= len("2006-01-02") {
+    return value
+  }
+  t, ok := displayTime(value)
+  if !ok {
+    return value
+  }
+  return t.Format(time.RFC3339)
+}
+
+// inDisplayZone returns a copy of the post with its timestamps in the configured time zone.
+func (post Post) inDisplayZone() Post {
+  post.CreatedAt = displayTimestamp(post.CreatedAt)
+  post.OriginalCreatedAt = displayTimestamp(post.OriginalCreatedAt)
+  post.LastViewed = displayTimestamp(post.LastViewed)
+  post.UpdatedAt = displayTimestamp(post.UpdatedAt)
+  if post.Revisions != nil {
+    revisions := make([]Revision, len(post.Revisions))
+    for i, revision := range post.Revisions {
+      revision.UpdatedAt = displayTimestamp(revision.UpdatedAt)
+      revisions[i] = revision
+    }
+    post.Revisions = revisions
+  }
+  if post.Comments != nil {
+    comments := make([]Comment, len(post.Comments))
+    for i, comment := range post.Comments {
+      comment.CreatedAt = displayTimestamp(comment.CreatedAt)
+      comments[i] = comment
+    }
+    post.Comments = comments
+  }
+  return post
+}
+
+/*
+  forOutput returns a copy of v, a response about to be encoded, where every Post has its timestamps in the configured time zone. Posts show up in responses in all sorts of shapes (a post, a slice of them, a struct embedding one...), so it walks v with reflection and copies whatever contains a Post on the way.
+*/
+var postType = reflect.TypeOf(Post{})
+
+func forOutput(v any) any {
+  if v == nil {
+    return nil
+  }
+  return localize(reflect.ValueOf(v)).Interface()
+}
+
+func localize(v reflect.Value) reflect.Value {
+  if !containsPost(v.Type()) {
+    return v
+  }
+  switch v.Kind() {
+  case reflect.Pointer:
+    if v.IsNil() {
+      return v
+    }
+    copied := reflect.New(v.Type().Elem())
+    copied.Elem().Set(localize(v.Elem()))
+    return copied
+  case reflect.Slice:
+    if v.IsNil() {
+      return v
+    }
+    copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+    for i := 0; i < v.Len(); i++ {
+      copied.Index(i).Set(localize(v.Index(i)))
+    }
+    return copied
+  case reflect.Map:
+    if v.IsNil() {
+      return v
+    }
+    copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+    iter := v.MapRange()
+    for iter.Next() {
+      copied.SetMapIndex(iter.Key(), localize(iter.Value()))
+    }
+    return copied
+  case reflect.Interface:
+    if v.IsNil() {
+      return v
+    }
+    copied := reflect.New(v.Type()).Elem()
+    copied.Set(localize(v.Elem()))
+    return copied
+  case reflect.Struct:
+    if v.Type() == postType {
+      // Responses also get the word count of the posts, see wordcount.go.
+      return reflect.ValueOf(v.Interface().(Post).inDisplayZone().withWordCount())
+    }
+    // Copying the whole struct first takes care of the unexported fields, which reflect can't set one by one.
+    copied := reflect.New(v.Type()).Elem()
+    copied.Set(v)
+    for i := 0; i < v.NumField(); i++ {
+      if v.Type().Field(i).IsExported() {
+        copied.Field(i).Set(localize(v.Field(i)))
+      }
+    }
+    return copied
+  }
+  return v
+}
+
+// containsPost reports whether values of type t can hold a Post. Interfaces can hold anything, so they might.
+func containsPost(t reflect.Type) bool {
+  return typeContains(t, map[reflect.Type]bool{})
+}
+
+func typeContains(t reflect.Type, visited map[reflect.Type]bool) bool {
+  if t == postType || t.Kind() == reflect.Interface {
+    return true
+  }
+  // visited stops the recursion on types that refer to themselves.
+  if visited[t] {
+    return false
+  }
+  visited[t] = true
+  switch t.Kind() {
+  case reflect.Pointer, reflect.Slice, reflect.Map:
+    return typeContains(t.Elem(), visited)
+  case reflect.Struct:
+    for i := 0; i < t.NumField(); i++ {
+      if t.Field(i).IsExported() && typeContains(t.Field(i).Type, visited) {
+        return true
+      }
+    }
+  }
+  return false
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+func TestTimezone(t *testing.T) {
+  posts := testPosts()
+  posts[1].CreatedAt = "2023-05-19"
+  setup(t, posts...)
+  tokyo, err := time.LoadLocation("Asia/Tokyo")
+  if err != nil {
+    t.Fatal(err)
+  }
+  config.Timezone = Location{tokyo}
+
+  w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+  if got := decode[Post](t, w).CreatedAt; got != "2025-01-01T19:00:00+09:00" {
+    t.Errorf("got CreatedAt %q, want it in Tokyo time", got)
+  }
+  w = serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/2", nil))
+  if got := decode[Post](t, w).CreatedAt; got != "2023-05-19" {
+    t.Errorf("plain date: got CreatedAt %q, want it as it is", got)
+  }
+
+  w = serve("/create", create, newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"}))
+  if w.Code != http.StatusCreated {
+    t.Fatalf("create: got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+  if got := decode[Post](t, w).CreatedAt; len(got) < 6 || got[len(got)-6:] != "+09:00" {
+    t.Errorf("create: got CreatedAt %q, want it in Tokyo time", got)
+  }
+  stored := storedPosts(t)
+  if got := stored[len(stored)-1].CreatedAt; got[len(got)-1] != 'Z' {
+    t.Errorf("got stored CreatedAt %q, want it in UTC", got)
+  }
+  if got := stored[0].CreatedAt; got != "2025-01-01T10:00:00Z" {
+    t.Errorf("got stored CreatedAt %q, want it unchanged", got)
+  }
+}
+
+func TestInvalidTimezone(t *testing.T) {
+  var l Location
+  if err := l.UnmarshalText([]byte("Mars/Olympus_Mons")); err == nil {
+    t.Error("got no error")
+  }
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+  "crypto/ecdsa"
+  "crypto/elliptic"
+  "crypto/rand"
+  "crypto/tls"
+  "crypto/x509"
+  "encoding/pem"
+  "errors"
+  "math/big"
+  "net"
+  "net/http"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+  "time"
+)
+
+// writeSelfSignedCert writes a certificate for 127.0.0.1 and its key in a temporary directory, and returns their paths and the certificate.
+func writeSelfSignedCert(t *testing.T) (certPath, keyPath string, cert *x509.Certificate) {
+  t.Helper()
+  key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+  if err != nil {
+    t.Fatal(err)
+  }
+  template := &x509.Certificate{
+    SerialNumber: big.NewInt(1),
+    NotBefore:    time.Now().Add(-time.Hour),
+    NotAfter:     time.Now().Add(time.Hour),
+    IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+    KeyUsage:     x509.KeyUsageDigitalSignature,
+    ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+  }
+  der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if cert, err = x509.ParseCertificate(der); err != nil {
+    t.Fatal(err)
+  }
+  keyDER, err := x509.MarshalECPrivateKey(key)
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  dir := t.TempDir()
+  certPath, keyPath = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+  if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+    t.Fatal(err)
+  }
+  if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+    t.Fatal(err)
+  }
+  return certPath, keyPath, cert
+}
+
+func TestServeOverTLS(t *testing.T) {
+  setup(t, testPosts()...)
+  certPath, keyPath, cert := writeSelfSignedCert(t)
+  config.TLSCert, config.TLSKey = certPath, keyPath
+  if err := config.validate(); err != nil {
+    t.Fatalf("validating the cert and key: %v", err)
+  }
+
+  listener, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil {
+    t.Fatal(err)
+  }
+  mux := http.NewServeMux()
+  mux.HandleFunc("/index", index)
+  server := &http.Server{Handler: mux}
+  done := make(chan error, 1)
+  go func() { done <- server.ServeTLS(listener, config.TLSCert, config.TLSKey) }()
+  defer func() {
+    server.Close()
+    if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+      t.Errorf("serving: %v", err)
+    }
+  }()
+
+  roots := x509.NewCertPool()
+  roots.AddCert(cert)
+  client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}, ForceAttemptHTTP2: true}}
+  response, err := client.Get("https://" + listener.Addr().String() + "/index")
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer response.Body.Close()
+  if response.StatusCode != http.StatusOK {
+    t.Errorf("got status %d, want %d", response.StatusCode, http.StatusOK)
+  }
+  if response.ProtoMajor != 2 {
+    t.Errorf("got %s, want HTTP/2", response.Proto)
+  }
+}
+
+func TestInvalidTLSConfig(t *testing.T) {
+  certPath, _, _ := writeSelfSignedCert(t)
+  _, otherKey, _ := writeSelfSignedCert(t)
+  tests := []struct {
+    name, cert, key, want string
+  }{
+    {"cert only", certPath, "", "tls_cert and tls_key must be set together"},
+    {"key of another cert", certPath, otherKey, "loading tls_cert and tls_key"},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      c := defaultConfig()
+      c.TLSCert, c.TLSKey = test.cert, test.key
+      if err := c.validate(); err == nil || !strings.Contains(err.Error(), test.want) {
+        t.Errorf("got error %v, want %q", err, test.want)
+      }
+    })
+  }
+}
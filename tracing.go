@@ -0,0 +1,95 @@
+package main
+
+import (
+  "context"
+  "net/http"
+  "strings"
+
+  "go.opentelemetry.io/otel"
+  "go.opentelemetry.io/otel/attribute"
+  "go.opentelemetry.io/otel/codes"
+  "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+  "go.opentelemetry.io/otel/propagation"
+  "go.opentelemetry.io/otel/sdk/resource"
+  sdktrace "go.opentelemetry.io/otel/sdk/trace"
+  "go.opentelemetry.io/otel/trace"
+)
+
+/*
+  TRACING
+
+  We use OpenTelemetry to record a span for every request, with child spans for loadPost and savePosts, so we can see where the time goes. The trace context travels in a context.Context: the one attached to the request (r.Context()) is passed down to every function that records a span.
+
+  Spans are only exported when an OTLP endpoint is configured (OTEL_EXPORTER_OTLP_ENDPOINT), otherwise the global tracer provider is a no-op and tracing costs next to nothing.
+*/
+var tracer = otel.Tracer("go/tutorial")
+
+// setupTracing installs the OTLP exporter and returns a function that flushes the remaining spans on shutdown.
+func setupTracing(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+  // Read the trace context (W3C traceparent header and baggage) from incoming requests, see traced.
+  otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+  if endpoint == "" {
+    return func(context.Context) error { return nil }, nil
+  }
+
+  // Like the OpenTelemetry SDKs do for OTEL_EXPORTER_OTLP_ENDPOINT, the endpoint is the base URL of the collector and the traces go to its /v1/traces path.
+  exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(strings.TrimRight(endpoint, "/")+"/v1/traces"))
+  if err != nil {
+    return nil, err
+  }
+  provider := sdktrace.NewTracerProvider(
+    sdktrace.WithBatcher(exporter),
+    sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "go-tutorial"))),
+  )
+  otel.SetTracerProvider(provider)
+  return provider.Shutdown, nil
+}
+
+// traced starts a server span for every request, continuing the trace of the caller when the request carries one.
+func traced(pattern string, next http.HandlerFunc) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+    ctx, span := tracer.Start(ctx, pattern, trace.WithSpanKind(trace.SpanKindServer))
+    defer span.End()
+
+    recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+    next(recorder, r.WithContext(ctx))
+
+    span.SetAttributes(
+      attribute.String("http.request.method", r.Method),
+      attribute.String("url.path", r.URL.Path),
+      attribute.Int("http.response.status_code", recorder.status),
+    )
+    if recorder.status >= http.StatusInternalServerError {
+      span.SetStatus(codes.Error, http.StatusText(recorder.status))
+    }
+  }
+}
+
+// endSpan records err on the span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+  if err != nil {
+    span.RecordError(err)
+    span.SetStatus(codes.Error, err.Error())
+  }
+  span.End()
+}
+
+/*
+  statusRecorder remembers the status code written by the handler. Embedding http.ResponseWriter gives it every method of the wrapped writer, we only override WriteHeader.
+*/
+type statusRecorder struct {
+  http.ResponseWriter
+  status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+  recorder.status = status
+  recorder.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap gives http.ResponseController access to the original writer.
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+  return recorder.ResponseWriter
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+  "net/http"
+  "testing"
+
+  "go.opentelemetry.io/otel"
+  "go.opentelemetry.io/otel/propagation"
+  sdktrace "go.opentelemetry.io/otel/sdk/trace"
+  "go.opentelemetry.io/otel/sdk/trace/tracetest"
+  "go.opentelemetry.io/otel/trace"
+)
+
+func TestTracedRecordsSpans(t *testing.T) {
+  setup(t, testPosts()...)
+  // tracer was created before any provider was set, its spans go to the first provider set.
+  exporter := tracetest.NewInMemoryExporter()
+  otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+  otel.SetTextMapPropagator(propagation.TraceContext{})
+
+  r := newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"})
+  r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+  w := serve("/create", traced("/create", create), r)
+  if w.Code != http.StatusCreated {
+    t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+  }
+
+  spans := map[string]tracetest.SpanStub{}
+  for _, span := range exporter.GetSpans() {
+    spans[span.Name] = span
+  }
+  request, ok := spans["/create"]
+  if !ok {
+    t.Fatalf("got spans %v, want one for the request", exporter.GetSpans())
+  }
+  if request.SpanKind != trace.SpanKindServer {
+    t.Errorf("got a %s span, want a server span", request.SpanKind)
+  }
+  if got := request.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+    t.Errorf("got trace ID %s, want the one of the traceparent header", got)
+  }
+  for _, name := range []string{"loadPost", "savePosts"} {
+    span, ok := spans[name]
+    if !ok {
+      t.Errorf("got no %s span", name)
+      continue
+    }
+    if span.Parent.SpanID() != request.SpanContext.SpanID() {
+      t.Errorf("%s isn't a child of the request span", name)
+    }
+  }
+}
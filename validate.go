@@ -0,0 +1,92 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "net/http"
+  "time"
+)
+
+/*
+  VALIDATE HANDLER
+
+  POST /validate checks a candidate posts file sent as the body, e.g. in a deployment pipeline before promoting it, without touching the live posts:
+
+  curl --fail --data-binary @posts.json http://localhost:3000/validate
+
+  On top of the self-check (see check.go) it reports fields the Post struct doesn't know, values of the wrong type, CreatedAt, UpdatedAt and LastViewed dates that can't be parsed or lie in the future, and invalid Lang, FeatureImage and Tags. The answer is a 200 when the file is valid and a 422 otherwise, so curl --fail stops the pipeline:
+
+  {"valid": false, "posts": 2, "problems": [{"id": "1", "field": "CreatedAt", "message": "\"Fri 19th, 2023\" is not a valid date"}]}
+*/
+type validationReport struct {
+  Valid    bool      `json:"valid"`
+  Posts    int       `json:"posts"`
+  Problems []problem `json:"problems"`
+}
+
+func validatePosts(w http.ResponseWriter, r *http.Request) {
+  body, status, err := readBody(r)
+  if err != nil {
+    jsonError(w, status, err.Error())
+    return
+  }
+
+  var posts []Post
+  decoder := json.NewDecoder(bytes.NewReader(body))
+  decoder.DisallowUnknownFields()
+  if err := decoder.Decode(&posts); err != nil {
+    // A file that doesn't decode can't be checked any further.
+    report := validationReport{Problems: []problem{{Message: fmt.Sprintf("not a valid posts file: %v", err)}}}
+    writeJSON(w, http.StatusUnprocessableEntity, report)
+    return
+  }
+
+  report := validationReport{Posts: len(posts), Problems: checkPosts(posts)}
+  now := time.Now()
+  for _, post := range posts {
+    report.Problems = append(report.Problems, checkPostFields(post, now)...)
+  }
+  report.Valid = len(report.Problems) == 0
+  status = http.StatusOK
+  if !report.Valid {
+    status = http.StatusUnprocessableEntity
+  }
+  writeJSON(w, status, report)
+}
+
+// checkPostFields returns the problems with the dates, the Lang and the Tags of the post.
+func checkPostFields(post Post, now time.Time) []problem {
+  problems := []problem{}
+  dates := []struct{ field, value string }{
+    {"CreatedAt", post.CreatedAt},
+    {"UpdatedAt", post.UpdatedAt},
+    {"LastViewed", post.LastViewed},
+  }
+  for _, date := range dates {
+    // Only CreatedAt is required, the others are empty until the post is updated or viewed.
+    if date.value == "" && date.field != "CreatedAt" {
+      continue
+    }
+    t, ok := parseTimestamp(date.value)
+    switch {
+    case !ok:
+      problems = append(problems, problem{ID: post.ID, Field: date.field, Message: fmt.Sprintf("%q is not a valid date", date.value)})
+    case t.After(now.Add(config.FutureTolerance.Duration)):
+      problems = append(problems, problem{ID: post.ID, Field: date.field, Message: fmt.Sprintf("%q is in the future", date.value)})
+    }
+  }
+  if err := validateCoAuthors(post.CoAuthors); err != nil {
+    problems = append(problems, problem{ID: post.ID, Field: "CoAuthors", Message: err.Error()})
+  }
+  if err := validateLang(post.Lang); err != nil {
+    problems = append(problems, problem{ID: post.ID, Field: "Lang", Message: err.Error()})
+  }
+  if err := validateFeatureImage(post.FeatureImage); err != nil {
+    problems = append(problems, problem{ID: post.ID, Field: "FeatureImage", Message: err.Error()})
+  }
+  if err := validateTags(post.Tags); err != nil {
+    problems = append(problems, problem{ID: post.ID, Field: "Tags", Message: err.Error()})
+  }
+  return problems
+}
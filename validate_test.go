@@ -0,0 +1,56 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "reflect"
+  "strings"
+  "testing"
+)
+
+// validateRequest sends the candidate posts file to /validate and returns the report.
+func validateRequest(t *testing.T, file string) (int, validationReport) {
+  t.Helper()
+  w := serve("POST /validate", validatePosts, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(file)))
+  return w.Code, decode[validationReport](t, w)
+}
+
+func TestValidateReportsProblems(t *testing.T) {
+  setup(t, testPosts()...)
+  const bad = `[
+  {"ID": "1", "Title": "First post", "Content": "First", "Author": "Jane Doe", "CreatedAt": "Fri 19th, 2023"},
+  {"ID": "1", "Title": "", "Content": "Second", "Author": "Jane Doe", "CreatedAt": "2999-01-01T00:00:00Z", "Lang": "xx"},
+  {"ID": "3", "Title": "Third post", "Content": "Third", "Author": "Jane Doe", "CreatedAt": "2025-01-03", "Tags": ["go", ""]}
+]`
+
+  status, report := validateRequest(t, bad)
+  if status != http.StatusUnprocessableEntity || report.Valid || report.Posts != 3 {
+    t.Errorf("got status %d, valid %v and %d posts, want 422, false and 3", status, report.Valid, report.Posts)
+  }
+  want := []problem{
+    {ID: "1", Field: "ID", Message: "is used by more than one post"},
+    {ID: "1", Field: "Title", Message: "is required"},
+    {ID: "1", Field: "CreatedAt", Message: `"Fri 19th, 2023" is not a valid date`},
+    {ID: "1", Field: "CreatedAt", Message: `"2999-01-01T00:00:00Z" is in the future`},
+    {ID: "1", Field: "Lang", Message: `Lang must be one of en, got "xx"`},
+    {ID: "3", Field: "Tags", Message: "tags can't be empty"},
+  }
+  if !reflect.DeepEqual(report.Problems, want) {
+    t.Errorf("got problems %+v, want %+v", report.Problems, want)
+  }
+
+  status, report = validateRequest(t, `[{"ID": "1", "Title": "A", "Content": "B", "Author": "C", "CreatedAt": "2025-01-01", "Colour": "red"}]`)
+  if status != http.StatusUnprocessableEntity || len(report.Problems) != 1 || !strings.Contains(report.Problems[0].Message, `unknown field "Colour"`) {
+    t.Errorf("unknown field: got status %d and problems %+v", status, report.Problems)
+  }
+
+  status, report = validateRequest(t, `[{"ID": "1", "Title": "A", "Content": "B", "Author": "C", "CreatedAt": "2025-01-01"}]`)
+  if status != http.StatusOK || !report.Valid || len(report.Problems) != 0 {
+    t.Errorf("valid file: got status %d and report %+v", status, report)
+  }
+
+  // The live posts are left alone.
+  if got := storedPosts(t); !reflect.DeepEqual(got, testPosts()) {
+    t.Errorf("got stored posts %+v, want them untouched", got)
+  }
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+  "log"
+  "sync"
+  "time"
+)
+
+/*
+  VIEW CAP
+
+  The debouncer (see views.go) ignores a client viewing the same post over and over, but a script can view it from many addresses. MAX_VIEWS_PER_MINUTE caps how many views a single post gets per minute, whoever they come from. Views past the cap are served as usual, they just aren't counted. 0, the default, means no cap.
+
+  Minutes are counted from the first view of the post, so the cap applies to fixed windows of a minute rather than to any sixty seconds.
+*/
+type viewCapLimiter struct {
+  mu      sync.Mutex
+  windows map[PostID]viewWindow
+}
+
+type viewWindow struct {
+  start time.Time
+  count int
+}
+
+var viewCap = &viewCapLimiter{windows: map[PostID]viewWindow{}}
+
+// allow counts a view of the post at now and tells whether it's within the cap. The first view past the cap in a window is logged.
+func (c *viewCapLimiter) allow(id PostID, now time.Time) bool {
+  if config.MaxViewsPerMinute <= 0 {
+    return true
+  }
+  c.mu.Lock()
+  defer c.mu.Unlock()
+  window := c.windows[id]
+  if now.Sub(window.start) >= time.Minute {
+    window = viewWindow{start: now}
+  }
+  window.count++
+  c.windows[id] = window
+  if window.count == config.MaxViewsPerMinute+1 {
+    log.Printf("Post %s reached MAX_VIEWS_PER_MINUTE (%d), its views aren't counted until %s", id, config.MaxViewsPerMinute, window.start.Add(time.Minute).Format(time.TimeOnly))
+  }
+  return window.count <= config.MaxViewsPerMinute
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+  "fmt"
+  "slices"
+  "testing"
+  "time"
+)
+
+func TestViewCap(t *testing.T) {
+  setup(t, testPosts()...)
+  config.MaxViewsPerMinute = 3
+
+  var got []int64
+  for i := 1; i <= 5; i++ {
+    // Every view comes from another client, the debouncer lets them all through.
+    got = append(got, viewPost(t, "1", fmt.Sprintf("192.0.2.%d:1234", i)))
+  }
+  if want := []int64{1, 2, 3, 3, 3}; !slices.Equal(got, want) {
+    t.Errorf("got view counts %v, want %v", got, want)
+  }
+  // The cap is per post.
+  if got := viewPost(t, "2", "192.0.2.1:1234"); got != 1 {
+    t.Errorf("other post: got %d views, want 1", got)
+  }
+}
+
+func TestViewCapWindow(t *testing.T) {
+  setup(t)
+  config.MaxViewsPerMinute = 2
+  start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+  for i, want := range []bool{true, true, false} {
+    if got := viewCap.allow("1", start.Add(time.Duration(i)*time.Second)); got != want {
+      t.Errorf("view %d: got %t, want %t", i+1, got, want)
+    }
+  }
+  if !viewCap.allow("1", start.Add(time.Minute)) {
+    t.Error("a view in the next minute wasn't allowed")
+  }
+
+  config.MaxViewsPerMinute = 0
+  for i := 0; i < 10; i++ {
+    if !viewCap.allow("2", start) {
+      t.Fatal("without a cap: a view wasn't allowed")
+    }
+  }
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+  "context"
+  "log"
+  "net"
+  "net/http"
+  "sync"
+  "time"
+)
+
+/*
+  VIEWS
+
+  Both index and show count views. To keep a reader that keeps refreshing the page from inflating the numbers, repeated views of the same post by the same client within the debounce window (VIEW_DEBOUNCE, 30 minutes by default) are ignored.
+
+  Counting views means writing to the file, so in read-only mode we serve the posts as they are. Posts where views mean nothing, like legal pages, can opt out with "TrackViews": false.
+*/
+var viewDebouncer = &debouncer{window: 30 * time.Minute, seen: map[string]time.Time{}}
+
+// tracksViews reports whether views of the post are counted, which they are unless TrackViews is false.
+func (post *Post) tracksViews() bool {
+  return post.TrackViews == nil || *post.TrackViews
+}
+
+// countView records a view of the post by the client making the request, in the post and in the view buffer. The handler then calls saveCountedViews.
+func countView(post *Post, r *http.Request) {
+  if config.ReadOnly || !post.tracksViews() {
+    return
+  }
+  if !viewDebouncer.allow(clientIP(r)+"|"+string(post.ID), time.Now()) {
+    return
+  }
+  // Popular posts can only grow so fast, see viewcap.go.
+  if !viewCap.allow(post.ID, time.Now()) {
+    return
+  }
+  post.increaseViewCount()
+  post.setLastViewed()
+  viewBuffer.add(post.ID, post.LastViewed)
+}
+
+// saveCountedViews writes the views counted by the request right away when there's no flush interval. Handlers call it once they're done counting, so a list counting many posts saves them all at once.
+func saveCountedViews(r *http.Request) {
+  if config.ViewFlushInterval.Duration > 0 {
+    return
+  }
+  if err := flushViews(r.Context()); err != nil {
+    log.Printf("Error saving views: %v", err)
+  }
+}
+
+// clientIP returns the address the request came from, without the port.
+func clientIP(r *http.Request) string {
+  host, _, err := net.SplitHostPort(r.RemoteAddr)
+  if err != nil {
+    return r.RemoteAddr
+  }
+  return host
+}
+
+/*
+  DEBOUNCER
+
+  Handlers run concurrently, each request in its own goroutine, so the map of recent views is guarded by a mutex. Only one goroutine at a time can hold the lock, the others wait in Lock() until it's released.
+*/
+type debouncer struct {
+  mu        sync.Mutex
+  window    time.Duration
+  seen      map[string]time.Time
+  lastSweep time.Time
+}
+
+// allow reports whether key wasn't seen within the window, and remembers it as seen at now.
+func (d *debouncer) allow(key string, now time.Time) bool {
+  if d.window <= 0 {
+    return true
+  }
+
+  d.mu.Lock()
+  // defer makes sure we release the lock whichever return statement we exit through.
+  defer d.mu.Unlock()
+
+  if d.wait(key, now) > 0 {
+    return false
+  }
+  d.remember(key, now)
+  return true
+}
+
+// retryIn returns how long until key is allowed again, 0 when it is already. Unlike allow it doesn't remember key.
+func (d *debouncer) retryIn(key string, now time.Time) time.Duration {
+  if d.window <= 0 {
+    return 0
+  }
+  d.mu.Lock()
+  defer d.mu.Unlock()
+  return d.wait(key, now)
+}
+
+// seenAt remembers key as seen at now.
+func (d *debouncer) seenAt(key string, now time.Time) {
+  if d.window <= 0 {
+    return
+  }
+  d.mu.Lock()
+  defer d.mu.Unlock()
+  d.remember(key, now)
+}
+
+// wait and remember expect d.mu to be held.
+func (d *debouncer) wait(key string, now time.Time) time.Duration {
+  if at, ok := d.seen[key]; ok && now.Sub(at) < d.window {
+    return d.window - now.Sub(at)
+  }
+  return 0
+}
+
+func (d *debouncer) remember(key string, now time.Time) {
+  // Forget about expired entries every now and then so the map doesn't grow forever.
+  if now.Sub(d.lastSweep) > d.window {
+    for k, at := range d.seen {
+      if now.Sub(at) >= d.window {
+        delete(d.seen, k)
+      }
+    }
+    d.lastSweep = now
+  }
+  d.seen[key] = now
+}
+
+/*
+  VIEW BUFFER
+
+  Writing the whole file for every view is a lot of disk churn for a popular post. Views are added up in memory instead, and written in one go every VIEW_FLUSH_INTERVAL (10s by default) and when the service stops.
+
+  loadPost adds the buffered views on top of the stored ones, so every route sees the current numbers, and remembers what it added in post.overlay. savePosts takes the overlay off again before writing: only flushViews writes the buffered views, otherwise they would be counted twice.
+*/
+type bufferedViews struct {
+  count      int64
+  lastViewed string
+}
+
+// viewOverlay is what the view buffer added to a loaded post, and the LastViewed it replaced.
+type viewOverlay struct {
+  count            int64
+  storedLastViewed string
+}
+
+type viewBufferMap struct {
+  mu      sync.Mutex
+  pending map[PostID]bufferedViews
+  // log is the VIEW_LOG, nil without one, see wal.go.
+  log *viewLog
+}
+
+var viewBuffer = &viewBufferMap{pending: map[PostID]bufferedViews{}}
+
+func (b *viewBufferMap) add(id PostID, lastViewed string) {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  views := b.pending[id]
+  views.count++
+  views.lastViewed = lastViewed
+  b.pending[id] = views
+  if b.log != nil {
+    if err := b.log.append(viewLogEntry{ID: id, Count: 1, LastViewed: lastViewed}); err != nil {
+      log.Printf("Error logging a view to %s: %v", b.log.path, err)
+    }
+  }
+}
+
+// apply adds the buffered views to the posts.
+func (b *viewBufferMap) apply(posts []Post) {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  for i := range posts {
+    post := &posts[i]
+    views, ok := b.pending[post.ID]
+    if !ok {
+      continue
+    }
+    // The overlay records what was actually added, which is less than the buffered views when the count saturates.
+    stored := post.ViewCount
+    post.ViewCount = saturatingAdd(post.ViewCount, views.count)
+    post.overlay = &viewOverlay{count: post.ViewCount - stored, storedLastViewed: post.LastViewed}
+    post.LastViewed = views.lastViewed
+  }
+}
+
+// snapshot returns a copy of the buffered views, to be written by flushViews.
+func (b *viewBufferMap) snapshot() map[PostID]bufferedViews {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  snapshot := make(map[PostID]bufferedViews, len(b.pending))
+  for id, views := range b.pending {
+    snapshot[id] = views
+  }
+  return snapshot
+}
+
+// flushed takes the views of a snapshot out of the buffer once they're written. Views counted in the meantime stay.
+func (b *viewBufferMap) flushed(snapshot map[PostID]bufferedViews) {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  for id, views := range snapshot {
+    remaining := b.pending[id]
+    remaining.count -= views.count
+    if remaining.count <= 0 {
+      delete(b.pending, id)
+    } else {
+      b.pending[id] = remaining
+    }
+  }
+  b.logged()
+}
+
+// rename moves the buffered views of posts that got a new ID, see compact.
+func (b *viewBufferMap) rename(renamed map[PostID]PostID) {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  moved := map[PostID]bufferedViews{}
+  for from, to := range renamed {
+    if views, ok := b.pending[from]; ok {
+      moved[to] = views
+      delete(b.pending, from)
+    }
+  }
+  for id, views := range moved {
+    b.pending[id] = views
+  }
+  b.logged()
+}
+
+// viewedNow sets the LastViewed of a post loaded by loadPost to now. The buffer's LastViewed of the post, and the stored one savePosts puts back, are older: they're both updated so the new one is what gets written.
+func (b *viewBufferMap) viewedNow(post *Post) {
+  post.setLastViewed()
+  if post.overlay != nil {
+    post.overlay.storedLastViewed = post.LastViewed
+  }
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  if views, ok := b.pending[post.ID]; ok {
+    views.lastViewed = post.LastViewed
+    b.pending[post.ID] = views
+  }
+}
+
+// removeOverlay puts back the stored views of a post loaded by loadPost.
+func (post *Post) removeOverlay() {
+  if post.overlay == nil {
+    return
+  }
+  post.ViewCount -= post.overlay.count
+  post.LastViewed = post.overlay.storedLastViewed
+  post.overlay = nil
+}
+
+/*
+  flushViews adds the buffered views to the posts file.
+
+  It gives up when ctx is done before it could write them, e.g. at shutdown past its deadline, rather than waiting on a write that's stuck holding postsMu. The views then stay in the buffer: the next flush writes them, if there's one.
+*/
+func flushViews(ctx context.Context) (err error) {
+  _, span := tracer.Start(ctx, "flushViews")
+  defer func() { endSpan(span, err) }()
+
+  pending := viewBuffer.snapshot()
+  if len(pending) == 0 {
+    return nil
+  }
+
+  if err := lockWithContext(ctx, &postsMu); err != nil {
+    return err
+  }
+  defer postsMu.Unlock()
+  // The views don't need the Content, so there's no need to decrypt the posts.
+  posts, err := storage.Load()
+  if err != nil {
+    return err
+  }
+  // Loading a large file takes a while, the deadline may have passed in the meantime.
+  if err := ctx.Err(); err != nil {
+    return err
+  }
+  for i := range posts {
+    if views, ok := pending[posts[i].ID]; ok {
+      posts[i].ViewCount = saturatingAdd(posts[i].ViewCount, views.count)
+      posts[i].LastViewed = views.lastViewed
+    }
+  }
+  if err := storage.Save(posts); err != nil {
+    return err
+  }
+  // Views of posts that are gone are dropped too.
+  viewBuffer.flushed(pending)
+  return nil
+}
+
+/*
+  lockWithContext locks mu unless ctx is done first. sync.Mutex can't give up waiting, so a goroutine waits instead and, when nobody wants the lock anymore, releases it as soon as it gets it.
+*/
+func lockWithContext(ctx context.Context, mu *sync.Mutex) error {
+  locked := make(chan struct{})
+  go func() {
+    mu.Lock()
+    close(locked)
+  }()
+  select {
+  case <-locked:
+    return nil
+  case <-ctx.Done():
+    go func() {
+      <-locked
+      mu.Unlock()
+    }()
+    return ctx.Err()
+  }
+}
+
+// unsaved returns the number of views in the buffer and of posts they belong to.
+func (b *viewBufferMap) unsaved() (views int64, posts int) {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  for _, pending := range b.pending {
+    views = saturatingAdd(views, pending.count)
+  }
+  return views, len(b.pending)
+}
+
+// flushViewsEvery flushes the views every interval until ctx is cancelled. A time.Ticker sends the time on its channel C once per interval.
+func flushViewsEvery(ctx context.Context, interval time.Duration) {
+  ticker := time.NewTicker(interval)
+  defer ticker.Stop()
+  for {
+    select {
+    case <-ctx.Done():
+      return
+    case <-ticker.C:
+      if err := flushViews(ctx); err != nil {
+        log.Printf("Error saving views: %v", err)
+      }
+    }
+  }
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+)
+
+// viewPost shows post id to the client at addr and returns the view count in the response.
+func viewPost(t *testing.T, id, addr string) int64 {
+  t.Helper()
+  r := httptest.NewRequest(http.MethodGet, "/posts/"+id, nil)
+  r.RemoteAddr = addr
+  w := serve("GET /posts/{id}", show, r)
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  return decode[Post](t, w).ViewCount
+}
+
+func TestDebouncerIgnoresRepeatedViews(t *testing.T) {
+  setup(t, testPosts()...)
+
+  if got := viewPost(t, "1", "192.0.2.1:1234"); got != 1 {
+    t.Fatalf("first view: got %d views, want 1", got)
+  }
+  // Another port is the same client.
+  if got := viewPost(t, "1", "192.0.2.1:5678"); got != 1 {
+    t.Errorf("refresh: got %d views, want 1", got)
+  }
+  if got := viewPost(t, "1", "192.0.2.2:1234"); got != 2 {
+    t.Errorf("other client: got %d views, want 2", got)
+  }
+  if got := viewPost(t, "2", "192.0.2.1:1234"); got != 1 {
+    t.Errorf("other post: got %d views, want 1", got)
+  }
+}
+
+func TestDebouncerWindow(t *testing.T) {
+  d := &debouncer{window: 30 * time.Minute, seen: map[string]time.Time{}}
+  start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+  if !d.allow("192.0.2.1|1", start) {
+    t.Fatal("the first view wasn't allowed")
+  }
+  if d.allow("192.0.2.1|1", start.Add(time.Second)) {
+    t.Error("a view a second later was allowed")
+  }
+  if d.allow("192.0.2.1|1", start.Add(29*time.Minute)) {
+    t.Error("a view within the window was allowed")
+  }
+  if !d.allow("192.0.2.1|1", start.Add(31*time.Minute)) {
+    t.Error("a view after the window wasn't allowed")
+  }
+}
+
+// countingStore counts the saves of the store it wraps.
+type countingStore struct {
+  Store
+  saves int
+}
+
+func (s *countingStore) Save(posts []Post) error {
+  s.saves++
+  return s.Store.Save(posts)
+}
+
+func TestViewsAreBufferedUntilFlushed(t *testing.T) {
+  setup(t, testPosts()...)
+  store := &countingStore{Store: storage}
+  storage = store
+
+  for _, addr := range []string{"192.0.2.1:1234", "192.0.2.2:1234", "192.0.2.3:1234"} {
+    viewPost(t, "1", addr)
+  }
+  if got := viewPost(t, "1", "192.0.2.4:1234"); got != 4 {
+    t.Errorf("reads: got %d views, want the 4 buffered ones", got)
+  }
+  if store.saves != 0 || storedPosts(t)[0].ViewCount != 0 {
+    t.Fatalf("got %d saves and %d stored views before the flush, want none", store.saves, storedPosts(t)[0].ViewCount)
+  }
+
+  if err := flushViews(context.Background()); err != nil {
+    t.Fatal(err)
+  }
+  stored := storedPosts(t)[0]
+  if store.saves != 1 || stored.ViewCount != 4 || stored.LastViewed == "" {
+    t.Errorf("got %d saves and stored post %+v, want one save of the 4 views", store.saves, stored)
+  }
+  if views, posts := viewBuffer.unsaved(); views != 0 || posts != 0 {
+    t.Errorf("got %d views of %d posts left in the buffer, want none", views, posts)
+  }
+  // The views aren't counted twice.
+  if got := viewPost(t, "1", "192.0.2.5:1234"); got != 5 {
+    t.Errorf("after the flush: got %d views, want 5", got)
+  }
+}
+
+func TestListingSavesItsViewsOnce(t *testing.T) {
+  setup(t, testPosts()...)
+  config.ViewFlushInterval.Duration = 0
+  store := &countingStore{Store: storage}
+  storage = store
+
+  if w := serve("/index", index, httptest.NewRequest(http.MethodGet, "/index", nil)); w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  if store.saves != 1 {
+    t.Errorf("got %d saves for a list of 3 posts, want 1", store.saves)
+  }
+  for _, post := range storedPosts(t) {
+    if post.ViewCount != 1 {
+      t.Errorf("post %s: got %d stored views, want 1", post.ID, post.ViewCount)
+    }
+  }
+}
+
+func TestUntrackedPostsAreNeverCounted(t *testing.T) {
+  posts := testPosts()
+  untracked := false
+  posts[1].TrackViews = &untracked
+  posts[1].ViewCount = 7
+  setup(t, posts...)
+  config.ViewFlushInterval.Duration = 0
+  store := &countingStore{Store: storage}
+  storage = store
+
+  if got := viewPost(t, "2", "192.0.2.1:1234"); got != 7 {
+    t.Errorf("show: got %d views, want 7", got)
+  }
+  w := serve("POST /posts/{id}/increment-view", incrementView, httptest.NewRequest(http.MethodPost, "/posts/2/increment-view", nil))
+  if got := decode[map[string]any](t, w)["views"]; got != float64(7) {
+    t.Errorf("increment-view: got %v views, want 7", got)
+  }
+  if store.saves != 0 {
+    t.Errorf("got %d saves, want none", store.saves)
+  }
+
+  // Listing counts the other posts only.
+  serve("/index", index, httptest.NewRequest(http.MethodGet, "/index", nil))
+  stored := storedPosts(t)
+  if stored[1].ViewCount != 7 || stored[1].LastViewed != "" {
+    t.Errorf("got %d views last viewed %q, want 7 and never", stored[1].ViewCount, stored[1].LastViewed)
+  }
+  if stored[0].ViewCount != 1 || stored[2].ViewCount != 1 {
+    t.Errorf("got %d and %d views of the tracked posts, want 1 each", stored[0].ViewCount, stored[2].ViewCount)
+  }
+}
+
+func TestFlushGivesUpWhenItsContextIsDone(t *testing.T) {
+  setup(t, testPosts()...)
+  viewPost(t, "1", "192.0.2.1:1234")
+  viewPost(t, "2", "192.0.2.1:1234")
+
+  // A write stuck holding the lock.
+  postsMu.Lock()
+  ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+  defer cancel()
+  start := time.Now()
+  err := flushViews(ctx)
+  if !errors.Is(err, context.DeadlineExceeded) {
+    t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+  }
+  if elapsed := time.Since(start); elapsed > time.Second {
+    t.Errorf("the flush took %v", elapsed)
+  }
+  postsMu.Unlock()
+
+  // The views stay in the buffer for the next flush.
+  if views, posts := viewBuffer.unsaved(); views != 2 || posts != 2 {
+    t.Fatalf("got %d views of %d posts left in the buffer, want 2 of 2", views, posts)
+  }
+  if err := flushViews(context.Background()); err != nil {
+    t.Fatal(err)
+  }
+  if stored := storedPosts(t); stored[0].ViewCount != 1 || stored[1].ViewCount != 1 {
+    t.Errorf("got %d and %d stored views, want 1 each", stored[0].ViewCount, stored[1].ViewCount)
+  }
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+  "net/http"
+)
+
+/*
+  VISIBILITY
+
+  Hidden posts are left out of the public lists (index, the archive, the feed, ...) but can still be reached through their own URL. toggleVisibility flips a post between hidden and visible and returns its new state.
+*/
+func toggleVisibility(w http.ResponseWriter, r *http.Request) {
+  id := PostID(r.PathValue("id"))
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  i := findPost(posts, id)
+  if i == -1 {
+    jsonError(w, http.StatusNotFound, "post not found")
+    return
+  }
+
+  post := &posts[i]
+  post.Hidden = !post.Hidden
+  post.setUpdatedAt()
+  if err := savePosts(r.Context(), posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, "Error saving posts")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, map[string]any{"id": post.ID, "hidden": post.Hidden})
+}
+
+// visiblePosts returns the posts that should show up in public lists, leaving out hidden, draft and deleted posts.
+func visiblePosts(posts []Post) []Post {
+  visible := []Post{}
+  for _, post := range posts {
+    if !post.Hidden && !post.Draft && !post.Deleted {
+      visible = append(visible, post)
+    }
+  }
+  return visible
+}
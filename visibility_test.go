@@ -0,0 +1,53 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "slices"
+  "testing"
+)
+
+// listedIDs returns the IDs of the posts index lists.
+func listedIDs(t *testing.T, query string) []PostID {
+  t.Helper()
+  w := serve("/index", index, httptest.NewRequest(http.MethodGet, "/index"+query, nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("index: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+  }
+  listed := []PostID{}
+  for _, post := range decode[[]Post](t, w) {
+    listed = append(listed, post.ID)
+  }
+  return listed
+}
+
+func TestToggleVisibility(t *testing.T) {
+  setup(t, testPosts()...)
+
+  for _, want := range []bool{true, false} {
+    w := serve("POST /posts/{id}/toggle-visibility", toggleVisibility, httptest.NewRequest(http.MethodPost, "/posts/2/toggle-visibility", nil))
+    if w.Code != http.StatusOK {
+      t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+    }
+    if got := decode[map[string]any](t, w)["hidden"]; got != want {
+      t.Errorf("got hidden %v, want %v", got, want)
+    }
+    // Newest first, see DEFAULT_SORT.
+    wantListed := []PostID{"3", "2", "1"}
+    if want {
+      wantListed = []PostID{"3", "1"}
+    }
+    if got := listedIDs(t, ""); !slices.Equal(got, wantListed) {
+      t.Errorf("hidden %v: index listed %v, want %v", want, got, wantListed)
+    }
+  }
+
+  // A hidden post can still be reached by its URL.
+  serve("POST /posts/{id}/toggle-visibility", toggleVisibility, httptest.NewRequest(http.MethodPost, "/posts/2/toggle-visibility", nil))
+  if w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/2", nil)); w.Code != http.StatusOK {
+    t.Errorf("show: got status %d, want %d", w.Code, http.StatusOK)
+  }
+  if w := serve("POST /posts/{id}/toggle-visibility", toggleVisibility, httptest.NewRequest(http.MethodPost, "/posts/9/toggle-visibility", nil)); w.Code != http.StatusNotFound {
+    t.Errorf("unknown post: got status %d, want %d", w.Code, http.StatusNotFound)
+  }
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+  "bufio"
+  "bytes"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "log"
+  "os"
+  "slices"
+)
+
+/*
+  WRITE-AHEAD LOG
+
+  Buffered views (see views.go) only reach the posts file at the next flush, so a crash loses the views counted since the last one. With VIEW_LOG set every buffered view is also appended to that file, a write-ahead log, one JSON line per view:
+
+  {"id":"3","count":1,"last_viewed":"2025-06-04T10:00:00Z"}
+
+  Creates and shares are written to the posts file before they're answered, but a crash in the middle of that save could still lose them. They're logged too, right before the save, with an op saying what they are:
+
+  {"op":"create","id":"4","post":{"ID":"4","Title":"My First Post",...}}
+  {"op":"share","id":"3","shares":12}
+
+  A share logs the new total rather than one more share, so replaying it twice doesn't count it twice, and a create is only replayed when the posts have no post with its ID yet.
+
+  At startup the lines left in it are replayed: creates and shares into the posts file right away, views into the buffer, which the next flush writes. Once a save succeeds the log is truncated down to the buffered views, and after a flush it's rewritten with the views still in the buffer, the ones counted during the flush, one line per post. Lines aren't synced to disk one by one, which would make every view as slow as a save: the log survives the process crashing, not the machine losing power.
+*/
+const (
+  walView   = ""
+  walCreate = "create"
+  walShare  = "share"
+)
+
+type viewLogEntry struct {
+  // Op is one of walCreate or walShare, views leave it out.
+  Op         string `json:"op,omitempty"`
+  ID         PostID `json:"id"`
+  Count      int64  `json:"count,omitempty"`
+  LastViewed string `json:"last_viewed,omitempty"`
+  Shares     int64  `json:"shares,omitempty"`
+  Post       *Post  `json:"post,omitempty"`
+}
+
+type viewLog struct {
+  path string
+  file *os.File
+  // unsaved is set when a create or a share was logged, the next save truncates the log.
+  unsaved bool
+}
+
+// openViewLog opens the log at path for appending, creating it if needed, and returns the views and the writes it holds. A line cut short by a crash ends the replay.
+func openViewLog(path string) (*viewLog, map[PostID]bufferedViews, []viewLogEntry, error) {
+  pending := map[PostID]bufferedViews{}
+  writes := []viewLogEntry{}
+  data, err := os.ReadFile(path)
+  if err != nil && !errors.Is(err, os.ErrNotExist) {
+    return nil, nil, nil, err
+  }
+  scanner := bufio.NewScanner(bytes.NewReader(data))
+  // A line holding a whole post can be longer than the 64KB a Scanner accepts by default.
+  scanner.Buffer(nil, 1<<30)
+  for scanner.Scan() {
+    var entry viewLogEntry
+    if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+      log.Printf("%s ends with an incomplete line, replaying the entries before it", path)
+      break
+    }
+    if entry.Op != walView {
+      writes = append(writes, entry)
+      continue
+    }
+    views := pending[entry.ID]
+    views.count = saturatingAdd(views.count, entry.Count)
+    views.lastViewed = entry.LastViewed
+    pending[entry.ID] = views
+  }
+  return &viewLog{path: path}, pending, writes, nil
+}
+
+func (l *viewLog) append(entry viewLogEntry) error {
+  line, err := json.Marshal(entry)
+  if err != nil {
+    return err
+  }
+  _, err = l.file.Write(append(line, '\n'))
+  return err
+}
+
+// rewrite replaces the log with the given views and writes. It writes a temporary file and renames it over the log, a crash in the middle leaves the old log in place.
+func (l *viewLog) rewrite(pending map[PostID]bufferedViews, writes []viewLogEntry) error {
+  var buf bytes.Buffer
+  entries := writes
+  for id, views := range pending {
+    entries = append(entries, viewLogEntry{ID: id, Count: views.count, LastViewed: views.lastViewed})
+  }
+  for _, entry := range entries {
+    line, err := json.Marshal(entry)
+    if err != nil {
+      return err
+    }
+    buf.Write(append(line, '\n'))
+  }
+  tmp := l.path + ".tmp"
+  if err := os.WriteFile(tmp, buf.Bytes(), config.FileMode.FileMode); err != nil {
+    return err
+  }
+  if err := os.Rename(tmp, l.path); err != nil {
+    return err
+  }
+  // The old file is gone, appending goes on in the new one.
+  file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0)
+  if err != nil {
+    return err
+  }
+  if l.file != nil {
+    l.file.Close()
+  }
+  l.file = file
+  l.unsaved = len(writes) > 0
+  return nil
+}
+
+/*
+  replayWrites applies the creates and shares of the log to the posts file. Like flushViews it works on the stored posts, the logged posts have their Content encrypted already.
+*/
+func replayWrites(writes []viewLogEntry) error {
+  posts, err := storage.Load()
+  if err != nil {
+    return err
+  }
+  for _, entry := range writes {
+    switch entry.Op {
+    case walCreate:
+      if entry.Post != nil && !slices.ContainsFunc(posts, func(post Post) bool { return post.ID == entry.Post.ID }) {
+        posts = append(posts, *entry.Post)
+      }
+    case walShare:
+      if i := findPost(posts, entry.ID); i != -1 {
+        posts[i].Shares = entry.Shares
+      }
+    }
+  }
+  return storage.Save(posts)
+}
+
+// useLog replays the log at path, see replayWrites, and logs the views counted from now on. It returns the number of views and of writes replayed. Writes that can't be replayed, e.g. because the posts file is corrupt, are kept for the next start.
+func (b *viewBufferMap) useLog(path string) (int64, int, error) {
+  l, pending, writes, err := openViewLog(path)
+  if err != nil {
+    return 0, 0, fmt.Errorf("Error opening %s: %w", path, err)
+  }
+  replayedWrites := 0
+  if len(writes) > 0 {
+    if err := replayWrites(writes); err != nil {
+      log.Printf("Error replaying the writes of %s, keeping them for the next start: %v", path, err)
+    } else {
+      replayedWrites, writes = len(writes), nil
+    }
+  }
+
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  var replayed int64
+  for id, views := range pending {
+    buffered := b.pending[id]
+    buffered.count = saturatingAdd(buffered.count, views.count)
+    buffered.lastViewed = views.lastViewed
+    b.pending[id] = buffered
+    replayed = saturatingAdd(replayed, views.count)
+  }
+  // The log is rewritten right away, dropping the replayed writes and the incomplete line if there's one.
+  if err := l.rewrite(b.pending, writes); err != nil {
+    return 0, 0, fmt.Errorf("Error rewriting %s: %w", path, err)
+  }
+  b.log = l
+  return replayed, replayedWrites, nil
+}
+
+// logWrite appends a create or a share to the log, if there's one, before it's saved.
+func (b *viewBufferMap) logWrite(entry viewLogEntry) {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  if b.log == nil {
+    return
+  }
+  if err := b.log.append(entry); err != nil {
+    log.Printf("Error logging a %s to %s: %v", entry.Op, b.log.path, err)
+    return
+  }
+  b.log.unsaved = true
+}
+
+// saved truncates the log down to the buffered views once the writes logged before a save are in the posts file.
+func (b *viewBufferMap) saved() {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  if b.log != nil && b.log.unsaved {
+    b.logged()
+  }
+}
+
+// logged writes the buffer to the log after a flush, a rename or a save, expecting b.mu to be held.
+func (b *viewBufferMap) logged() {
+  if b.log == nil {
+    return
+  }
+  if err := b.log.rewrite(b.pending, nil); err != nil {
+    log.Printf("Error rewriting %s: %v", b.log.path, err)
+  }
+}
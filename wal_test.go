@@ -0,0 +1,118 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+// crashingStore dies on every save, before the posts file is written. A save that fails with an error isn't a crash: the client is told and the logged write is dropped.
+type crashingStore struct {
+  Store
+}
+
+var errCrash = errors.New("crashed")
+
+func (s crashingStore) Save(posts []Post) error {
+  panic(errCrash)
+}
+
+// crashWhile sends the request to handler until it crashes, see crashingStore.
+func crashWhile(t *testing.T, pattern string, handler http.HandlerFunc, r *http.Request) {
+  t.Helper()
+  defer func() {
+    if recover() != errCrash {
+      t.Errorf("%s didn't crash", pattern)
+    }
+  }()
+  serve(pattern, handler, r)
+}
+
+// useViewLog logs the buffered views to a file of a temporary directory and returns its path.
+func useViewLog(t *testing.T) string {
+  t.Helper()
+  path := filepath.Join(t.TempDir(), "views.log")
+  if _, _, err := viewBuffer.useLog(path); err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() { viewBuffer.log.file.Close() })
+  return path
+}
+
+func TestViewLogReplaysAfterACrash(t *testing.T) {
+  setup(t, testPosts()...)
+  path := useViewLog(t)
+  for _, addr := range []string{"192.0.2.1:1234", "192.0.2.2:1234", "192.0.2.3:1234"} {
+    viewPost(t, "1", addr)
+  }
+  stored := storage
+  storage = crashingStore{stored}
+  crashWhile(t, "POST /posts/{id}/share", lockPosts(share), httptest.NewRequest(http.MethodPost, "/posts/2/share", nil))
+  crashWhile(t, "/create", lockPosts(create), newJSONRequest(t, http.MethodPost, "/create", Post{Title: "New", Content: "New content", Author: "Jane Doe"}))
+
+  // The process restarts with an empty buffer and the posts file as it was before the crash.
+  viewBuffer.log.file.Close()
+  viewBuffer = &viewBufferMap{pending: map[PostID]bufferedViews{}}
+  storage = stored
+  views, writes, err := viewBuffer.useLog(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if views != 3 || writes != 2 {
+    t.Errorf("got %d views and %d writes replayed, want 3 and 2", views, writes)
+  }
+  if err := flushViews(context.Background()); err != nil {
+    t.Fatal(err)
+  }
+  posts := storedPosts(t)
+  if len(posts) != 4 || posts[3].Title != "New" {
+    t.Fatalf("got stored posts %+v, want the created post back", posts)
+  }
+  if posts[0].ViewCount != 3 || posts[0].LastViewed == "" || posts[1].Shares != 1 {
+    t.Errorf("got %d views of post 1 and %d shares of post 2, want 3 and 1", posts[0].ViewCount, posts[1].Shares)
+  }
+
+  // Everything is saved, the log is empty and a second restart replays nothing.
+  data, err := os.ReadFile(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(data) != 0 {
+    t.Errorf("got log %q after the flush, want it empty", data)
+  }
+}
+
+func TestViewLogStopsAtAnIncompleteLine(t *testing.T) {
+  setup(t, testPosts()...)
+  path := filepath.Join(t.TempDir(), "views.log")
+  lines := []string{
+    `{"id":"1","count":2,"last_viewed":"2025-06-04T10:00:00Z"}`,
+    `{"op":"share","id":"2","shares":12}`,
+    // Replaying a share sets the total, it isn't counted twice.
+    `{"op":"share","id":"2","shares":12}`,
+    `{"id":"3","count":5,"last_vie`,
+  }
+  if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+    t.Fatal(err)
+  }
+  views, writes, err := viewBuffer.useLog(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() { viewBuffer.log.file.Close() })
+  if views != 2 || writes != 2 {
+    t.Errorf("got %d views and %d writes replayed, want 2 and 2", views, writes)
+  }
+  if err := flushViews(context.Background()); err != nil {
+    t.Fatal(err)
+  }
+  posts := storedPosts(t)
+  if posts[0].ViewCount != 2 || posts[0].LastViewed != "2025-06-04T10:00:00Z" || posts[1].Shares != 12 || posts[2].ViewCount != 0 {
+    t.Errorf("got %d, %d and %d views and %d shares, want 2, 0 and 0 views and 12 shares", posts[0].ViewCount, posts[1].ViewCount, posts[2].ViewCount, posts[1].Shares)
+  }
+}
@@ -0,0 +1,22 @@
+package main
+
+import "strings"
+
+/*
+  WORD COUNT
+
+  Responses tell how long each post is in WordCount, the number of words of its Content, e.g. "WordCount": 250 for a short article. Words are whatever lies between white space, in any script, so "l'été arrive" counts 2 words.
+
+  WordCount is computed on the way out, like the time zone conversion (see timezone.go), and never stored: it's a pointer so that it's left out of the posts file while being nil, yet a post without words still shows 0.
+*/
+func countWords(content string) int {
+  // strings.Fields splits on unicode.IsSpace, which knows about non-breaking and ideographic spaces too.
+  return len(strings.Fields(content))
+}
+
+// withWordCount returns a copy of the post with its WordCount set.
+func (post Post) withWordCount() Post {
+  count := countWords(post.Content)
+  post.WordCount = &count
+  return post
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestWordCount(t *testing.T) {
+  posts := testPosts()
+  // Non-breaking and ideographic spaces separate words too.
+  posts[0].Content = "l'été\u00a0arrive bientôt\u3000ici"
+  posts[1].Content = ""
+  setup(t, posts...)
+
+  w := serve("GET /posts/{id}", show, httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+  if got := decode[map[string]any](t, w)["WordCount"]; got != float64(4) {
+    t.Errorf("show: got WordCount %v, want 4", got)
+  }
+
+  w = serve("/index", index, httptest.NewRequest(http.MethodGet, "/index?sort=title", nil))
+  want := map[string]float64{"1": 4, "2": 0, "3": 6}
+  for _, post := range decode[[]map[string]any](t, w) {
+    // An empty post still shows 0 rather than leaving WordCount out.
+    if got, ok := post["WordCount"]; !ok || got != want[post["ID"].(string)] {
+      t.Errorf("index: post %v: got WordCount %v, want %v", post["ID"], got, want[post["ID"].(string)])
+    }
+  }
+
+  // The count is never stored.
+  for _, post := range storedPosts(t) {
+    if post.WordCount != nil {
+      t.Errorf("post %s: got stored WordCount %d, want none", post.ID, *post.WordCount)
+    }
+  }
+}
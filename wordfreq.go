@@ -0,0 +1,89 @@
+package main
+
+import (
+  "net/http"
+  "sort"
+  "strconv"
+  "strings"
+  "unicode"
+)
+
+/*
+  WORD FREQUENCY HANDLER
+
+  Counts the words used across the content of all posts and returns the most common ones, e.g. for a word cloud:
+
+  GET /posts/wordfreq?top=20
+
+  Words are lowercased and stripped of punctuation, and the stopwords from the config ("the", "and", ...) are left out.
+*/
+type wordCount struct {
+  Word  string `json:"Word"`
+  Count int    `json:"Count"`
+}
+
+func wordFrequency(w http.ResponseWriter, r *http.Request) {
+  top := 20
+  if value := r.URL.Query().Get("top"); value != "" {
+    n, err := strconv.Atoi(value)
+    if err != nil || n < 1 {
+      jsonError(w, http.StatusBadRequest, "top must be a positive number")
+      return
+    }
+    top = n
+  }
+
+  var posts []Post
+  if err := loadPost(r.Context(), &posts); err != nil {
+    jsonError(w, http.StatusInternalServerError, err.Error())
+    return
+  }
+  posts = visiblePosts(posts)
+
+  // A map with empty struct values is the idiomatic way to build a set in Go, struct{}{} takes no memory.
+  stopwords := map[string]struct{}{}
+  for _, word := range config.Stopwords {
+    stopwords[strings.ToLower(word)] = struct{}{}
+  }
+
+  counts := map[string]int{}
+  for _, post := range posts {
+    for _, word := range tokenize(post.Content) {
+      if _, skip := stopwords[word]; !skip {
+        counts[word]++
+      }
+    }
+  }
+
+  words := make([]wordCount, 0, len(counts))
+  for word, count := range counts {
+    words = append(words, wordCount{Word: word, Count: count})
+  }
+  // Maps have no order, so ties are sorted alphabetically to keep the response stable.
+  sort.Slice(words, func(i, j int) bool {
+    if words[i].Count != words[j].Count {
+      return words[i].Count > words[j].Count
+    }
+    return words[i].Word < words[j].Word
+  })
+  if len(words) > top {
+    words = words[:top]
+  }
+
+  writeJSON(w, http.StatusOK, words)
+}
+
+// tokenize splits text into lowercase words, keeping apostrophes inside words like "don't".
+func tokenize(text string) []string {
+  fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+    return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+  })
+
+  words := fields[:0]
+  for _, field := range fields {
+    if word := strings.Trim(field, "'"); word != "" {
+      words = append(words, word)
+    }
+  }
+  return words
+}
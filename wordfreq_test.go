@@ -0,0 +1,40 @@
+package main
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "reflect"
+  "testing"
+)
+
+func TestWordFrequency(t *testing.T) {
+  setup(t,
+    Post{ID: "1", Title: "Go", Content: "Go is fun. Go, go, GO!", Author: "a"},
+    Post{ID: "2", Title: "Rust", Content: "Rust is fun; the borrow checker isn't.", Author: "a"},
+    Post{ID: "3", Title: "Hidden", Content: "secret secret secret secret secret", Author: "a", Hidden: true},
+  )
+
+  w := serve("GET /posts/wordfreq", wordFrequency, httptest.NewRequest(http.MethodGet, "/posts/wordfreq?top=3", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  want := []wordCount{{"go", 4}, {"fun", 2}, {"borrow", 1}}
+  if got := decode[[]wordCount](t, w); !reflect.DeepEqual(got, want) {
+    t.Errorf("got %v, want %v", got, want)
+  }
+}
+
+func TestWordFrequencyEmpty(t *testing.T) {
+  setup(t)
+
+  w := serve("GET /posts/wordfreq", wordFrequency, httptest.NewRequest(http.MethodGet, "/posts/wordfreq", nil))
+  if w.Code != http.StatusOK {
+    t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+  }
+  if got := decode[[]wordCount](t, w); len(got) != 0 {
+    t.Errorf("got %v, want no words", got)
+  }
+  if w := serve("GET /posts/wordfreq", wordFrequency, httptest.NewRequest(http.MethodGet, "/posts/wordfreq?top=0", nil)); w.Code != http.StatusBadRequest {
+    t.Errorf("top=0: got status %d, want %d", w.Code, http.StatusBadRequest)
+  }
+}